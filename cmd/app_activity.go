@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var appActivityCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "activity",
+		Short: "List the audit log events of an app",
+	},
+
+	Options:      func() interface{} { return &qbcli.AppActivityOptions{} },
+	Args:         []string{qbclient.OptionAppID},
+	DefaultAppID: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.AppActivity(qb, opts.(*qbcli.AppActivityOptions))
+	},
+}
+
+func init() {
+	appActivityCmd.Add(appCmd, &globalCfg)
+}
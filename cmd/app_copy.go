@@ -13,6 +13,13 @@ var appCopyCfg *viper.Viper
 var appCopyCmd = &cobra.Command{
 	Use:   "copy",
 	Short: "Copy an app",
+	Long: `Copy an app
+
+Copies the app passed through --app-id or the first argument to a new app
+named by --name, e.g., to create test and production environments from a
+template app. Only the schema is copied unless --keep-data and
+--keep-users-roles are passed. The new app is written in the output format,
+and its ID is the id property.`,
 
 	Args: func(cmd *cobra.Command, args []string) error {
 		err := globalCfg.Validate()
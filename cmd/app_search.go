@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var appSearchCfg *viper.Viper
+
+var appSearchCmd = &cobra.Command{
+	Use:   "search",
+	Short: "Search apps by name, optionally across the realms of all profiles",
+
+	Args: func(cmd *cobra.Command, args []string) error {
+		return globalCfg.Validate()
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
+
+		opts := &qbcli.AppSearchOptions{}
+		qbcli.GetOptions(ctx, logger, opts, appSearchCfg)
+
+		output, err := qbcli.AppSearch(ctx, logger, qb, globalCfg, opts)
+		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	},
+}
+
+func init() {
+	var flags *cliutil.Flagger
+	appSearchCfg, flags = cliutil.AddCommand(appCmd, appSearchCmd, qbclient.EnvPrefix)
+	flags.SetOptions(&qbcli.AppSearchOptions{})
+}
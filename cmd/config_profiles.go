@@ -20,7 +20,7 @@ var configProfilesCfg = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, logger, _ := qbcli.NewLogger(cmd, globalCfg)
 
-		cfg, err := qbclient.ReadConfigFile(globalCfg.ConfigDir())
+		cfg, err := qbclient.ReadConfigFileAt(globalCfg.ConfigFile())
 		qbcli.HandleError(ctx, logger, "error reading config file", err)
 
 		i := 0
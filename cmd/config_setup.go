@@ -20,7 +20,7 @@ var configSetupCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, logger, _ := qbcli.NewLogger(cmd, globalCfg)
 
-		filepath := qbclient.Filepath(globalCfg.ConfigDir(), qbclient.ConfigFilename)
+		filepath := globalCfg.ConfigFile()
 		ctx = cliutil.ContextWithLogTag(ctx, "file", filepath)
 
 		if qbclient.FileExists(filepath) {
@@ -44,7 +44,7 @@ var configSetupCmd = &cobra.Command{
 			AppID:         appID,
 		}
 
-		err = qbclient.WriteConfigFile(globalCfg.ConfigDir(), cf)
+		err = qbclient.WriteConfigFileAt(filepath, cf)
 		qbcli.HandleError(ctx, logger, "error writing config file", err)
 		logger.Notice(ctx, "setup complete")
 	},
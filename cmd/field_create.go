@@ -25,7 +25,8 @@ var fieldCreateCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
 
-		input := &qbclient.CreateFieldInput{Properties: &qbclient.CreateFieldInputProperties{}}
+		// Create is set so the label and type are validated with the options.
+		input := &qbclient.CreateFieldInput{Field: qbclient.Field{Create: true}, Properties: &qbclient.CreateFieldInputProperties{}}
 		qbcli.GetOptions(ctx, logger, input, fieldCreateCfg)
 
 		// Set the formula from the contents for a file.
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var fieldEnsureCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "ensure",
+		Short: "Create a field in a table unless a field with its label exists",
+	},
+
+	Options: func() interface{} {
+		return &qbcli.FieldEnsureOptions{
+			CreateFieldInput: qbclient.CreateFieldInput{Properties: &qbclient.CreateFieldInputProperties{}},
+		}
+	},
+	Args:           []string{qbclient.OptionTableID},
+	DefaultTableID: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.FieldEnsure(qb, opts.(*qbcli.FieldEnsureOptions))
+	},
+}
+
+func init() {
+	fieldEnsureCmd.Add(fieldCmd, &globalCfg)
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var fieldExportCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "export",
+		Short: "Export the schema of the fields in a table to YAML",
+	},
+
+	Options:        func() interface{} { return &qbcli.FieldsExportOptions{} },
+	Args:           []string{qbclient.OptionTableID},
+	DefaultTableID: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.FieldsExport(qb, opts.(*qbcli.FieldsExportOptions))
+	},
+
+	Output: qbcli.RenderFieldsFile,
+}
+
+func init() {
+	fieldExportCmd.Add(fieldCmd, &globalCfg)
+}
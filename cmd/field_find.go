@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var fieldFindCfg *viper.Viper
+
+var fieldFindCmd = &cobra.Command{
+	Use:   "find",
+	Short: "Find fields in an app by label",
+
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		if err = globalCfg.Validate(); err == nil {
+			globalCfg.SetDefaultAppID(fieldFindCfg)
+			qbcli.SetOptionFromArg(fieldFindCfg, args, 0, "label-regex")
+		}
+		return
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
+
+		opts := &qbcli.FieldFindOptions{}
+		qbcli.GetOptions(ctx, logger, opts, fieldFindCfg)
+
+		output, err := qbcli.FieldFind(qb, opts)
+		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	},
+}
+
+func init() {
+	var flags *cliutil.Flagger
+	fieldFindCfg, flags = cliutil.AddCommand(fieldCmd, fieldFindCmd, qbclient.EnvPrefix)
+	flags.SetOptions(&qbcli.FieldFindOptions{})
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var fieldImportCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "import",
+		Short: "Create and update the fields in a table to match a file written by field export",
+	},
+
+	Options:        func() interface{} { return &qbcli.FieldsImportOptions{} },
+	Args:           []string{qbclient.OptionTableID},
+	DefaultTableID: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.FieldsImport(ctx, logger, qb, globalCfg, opts.(*qbcli.FieldsImportOptions))
+	},
+}
+
+func init() {
+	fieldImportCmd.Add(fieldCmd, &globalCfg)
+}
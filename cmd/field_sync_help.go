@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var fieldSyncHelpCfg *viper.Viper
+
+var fieldSyncHelpCmd = &cobra.Command{
+	Use:   "sync-help",
+	Short: "Update the help text of fields in a table from a file keyed by label",
+
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		if err = globalCfg.Validate(); err == nil {
+			globalCfg.SetDefaultTableID(fieldSyncHelpCfg)
+			qbcli.SetOptionFromArg(fieldSyncHelpCfg, args, 0, qbclient.OptionTableID)
+		}
+		return
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
+
+		opts := &qbcli.FieldSyncHelpOptions{}
+		qbcli.GetOptions(ctx, logger, opts, fieldSyncHelpCfg)
+
+		output, err := qbcli.FieldSyncHelp(qb, globalCfg, opts)
+		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	},
+}
+
+func init() {
+	var flags *cliutil.Flagger
+	fieldSyncHelpCfg, flags = cliutil.AddCommand(fieldCmd, fieldSyncHelpCmd, qbclient.EnvPrefix)
+	flags.SetOptions(&qbcli.FieldSyncHelpOptions{})
+}
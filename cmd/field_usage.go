@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var fieldUsageCfg *viper.Viper
+
+var fieldUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "List formulas, reports, and relationships that reference a field",
+
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		if err = globalCfg.Validate(); err == nil {
+			globalCfg.SetDefaultAppID(fieldUsageCfg)
+			globalCfg.SetDefaultTableID(fieldUsageCfg)
+			qbcli.SetOptionFromArg(fieldUsageCfg, args, 0, qbclient.OptionTableID)
+			qbcli.SetOptionFromArg(fieldUsageCfg, args, 1, qbclient.OptionFieldID)
+		}
+		return
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
+
+		opts := &qbcli.FieldUsageOptions{}
+		qbcli.GetOptions(ctx, logger, opts, fieldUsageCfg)
+
+		output, err := qbcli.FieldUsage(qb, opts)
+		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	},
+}
+
+func init() {
+	var flags *cliutil.Flagger
+	fieldUsageCfg, flags = cliutil.AddCommand(fieldCmd, fieldUsageCmd, qbclient.EnvPrefix)
+	flags.SetOptions(&qbcli.FieldUsageOptions{})
+}
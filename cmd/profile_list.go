@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var profileListCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "list",
+		Short: "List the profiles in the config file",
+	},
+
+	NoClient: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.ListProfiles(globalCfg.ConfigFile(), globalCfg.Profile())
+	},
+}
+
+func init() {
+	profileListCmd.Add(profileCmd, &globalCfg)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var profileSetCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "set NAME",
+		Short: "Write a profile to the config file",
+		Args:  cobra.ExactArgs(1),
+	},
+
+	Options:  func() interface{} { return &qbcli.ProfileSetOptions{} },
+	Args:     []string{"name"},
+	NoClient: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.SetProfile(globalCfg, opts.(*qbcli.ProfileSetOptions))
+	},
+}
+
+func init() {
+	profileSetCmd.Add(profileCmd, &globalCfg)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var profileShowCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "show [NAME]",
+		Short: "Show a profile's realm and auth method, defaulting to the active profile",
+		Args:  cobra.MaximumNArgs(1),
+	},
+
+	Options:  func() interface{} { return &qbcli.ProfileShowOptions{} },
+	Args:     []string{"name"},
+	NoClient: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.ShowProfile(globalCfg.ConfigFile(), opts.(*qbcli.ProfileShowOptions), globalCfg.Profile())
+	},
+}
+
+func init() {
+	profileShowCmd.Add(profileCmd, &globalCfg)
+}
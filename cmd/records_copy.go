@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var recordsCopyCfg *viper.Viper
+
+var recordsCopyCmd = &cobra.Command{
+	Use:   "copy",
+	Short: "Copy records to another table, mapping the fields between them",
+
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		if err = globalCfg.Validate(); err == nil {
+			qbcli.SetOptionFromArg(recordsCopyCfg, args, 0, "source")
+			qbcli.SetOptionFromArg(recordsCopyCfg, args, 1, "dest")
+		}
+		return
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
+
+		opts := &qbcli.CopyOptions{}
+		qbcli.GetOptions(ctx, logger, opts, recordsCopyCfg)
+
+		output, err := qbcli.Copy(ctx, logger, qb, globalCfg, opts)
+		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	},
+}
+
+func init() {
+	var flags *cliutil.Flagger
+	recordsCopyCfg, flags = cliutil.AddCommand(recordsCmd, recordsCopyCmd, qbclient.EnvPrefix)
+	flags.SetOptions(&qbcli.CopyOptions{})
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var recordsDedupCfg *viper.Viper
+
+var recordsDedupCmd = &cobra.Command{
+	Use:   "dedup",
+	Short: "Delete duplicate records in a table",
+
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		if err = globalCfg.Validate(); err == nil {
+			globalCfg.SetDefaultTableID(recordsDedupCfg)
+			qbcli.SetOptionFromArg(recordsDedupCfg, args, 0, qbclient.OptionTableID)
+		}
+		return
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
+
+		opts := &qbcli.DedupOptions{}
+		qbcli.GetOptions(ctx, logger, opts, recordsDedupCfg)
+
+		output, err := qbcli.Dedup(ctx, logger, qb, globalCfg, opts)
+		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	},
+}
+
+func init() {
+	var flags *cliutil.Flagger
+	recordsDedupCfg, flags = cliutil.AddCommand(recordsCmd, recordsDedupCmd, qbclient.EnvPrefix)
+	flags.SetOptions(&qbcli.DedupOptions{})
+}
@@ -13,6 +13,12 @@ var recordsDeleteCfg *viper.Viper
 var recordsDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete records in a table",
+	Long: `Delete records in a table
+
+Deletes the records matching the query passed through --where. The matching
+records are counted first and the count is logged, then the delete must be
+confirmed unless --yes is passed. In --quiet mode or when stdin isn't a
+terminal, the delete fails unless --yes is passed.`,
 
 	Args: func(cmd *cobra.Command, args []string) (err error) {
 		if err = globalCfg.Validate(); err == nil {
@@ -29,6 +35,14 @@ var recordsDeleteCmd = &cobra.Command{
 		input := &qbclient.DeleteRecordsInput{}
 		qbcli.GetOptions(ctx, logger, input, recordsDeleteCfg)
 
+		// Count the matching records and require confirmation.
+		ok, err := qbcli.ConfirmDeleteRecords(ctx, logger, qb, globalCfg, input, recordsDeleteCfg.GetBool("yes"))
+		qbcli.HandleError(ctx, logger, "delete not confirmed", err)
+		if !ok {
+			logger.Notice(ctx, "no records deleted")
+			return
+		}
+
 		output, err := qb.DeleteRecords(input)
 		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
 	},
@@ -38,4 +52,5 @@ func init() {
 	var flags *cliutil.Flagger
 	recordsDeleteCfg, flags = cliutil.AddCommand(recordsCmd, recordsDeleteCmd, qbclient.EnvPrefix)
 	flags.SetOptions(&qbclient.DeleteRecordsInput{})
+	flags.Bool("yes", "", false, "delete the records without prompting for confirmation")
 }
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var recordsDiffCfg *viper.Viper
+
+var recordsDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare two sets of records exported by records query",
+
+	Args: func(cmd *cobra.Command, args []string) error {
+		return globalCfg.ReadInConfig()
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, logger, _ := qbcli.NewLogger(cmd, globalCfg)
+
+		opts := &qbcli.RecordsDiffOptions{}
+		qbcli.GetOptions(ctx, logger, opts, recordsDiffCfg)
+
+		output, err := qbcli.RecordsDiff(opts)
+		if err == nil {
+			ctx := cliutil.ContextWithLogTag(ctx, "added", strconv.Itoa(output.Summary.Added))
+			ctx = cliutil.ContextWithLogTag(ctx, "removed", strconv.Itoa(output.Summary.Removed))
+			ctx = cliutil.ContextWithLogTag(ctx, "modified", strconv.Itoa(output.Summary.Modified))
+			ctx = cliutil.ContextWithLogTag(ctx, "unchanged", strconv.Itoa(output.Summary.Unchanged))
+			logger.Notice(ctx, "records compared")
+		}
+
+		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	},
+}
+
+func init() {
+	var flags *cliutil.Flagger
+	recordsDiffCfg, flags = cliutil.AddCommand(recordsCmd, recordsDiffCmd, qbclient.EnvPrefix)
+	flags.SetOptions(&qbcli.RecordsDiffOptions{})
+}
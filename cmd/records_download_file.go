@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var recordsDownloadFileCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "download-file",
+		Short: "Download a file attachment of a record",
+	},
+
+	Options:        func() interface{} { return &qbcli.DownloadFileOptions{} },
+	Args:           []string{qbclient.OptionTableID, "field-id", "record-id"},
+	DefaultTableID: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.DownloadFile(qb, opts.(*qbcli.DownloadFileOptions), globalCfg.OutputFile())
+	},
+
+	Output: qbcli.RenderDownloadFile,
+}
+
+func init() {
+	recordsDownloadFileCmd.Add(recordsCmd, &globalCfg)
+}
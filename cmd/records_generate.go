@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var recordsGenerateCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "generate",
+		Short: "Insert records with random values for testing",
+	},
+
+	Options:        func() interface{} { return &qbcli.GenerateOptions{} },
+	Args:           []string{qbclient.OptionTableID},
+	DefaultTableID: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.Generate(ctx, logger, qb, globalCfg, opts.(*qbcli.GenerateOptions))
+	},
+}
+
+func init() {
+	recordsGenerateCmd.Add(recordsCmd, &globalCfg)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var recordsHashCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "hash",
+		Short: "Output a hash of the records matching a query to detect changes",
+	},
+
+	Options:        func() interface{} { return &qbcli.HashOptions{} },
+	Args:           []string{qbclient.OptionTableID},
+	DefaultTableID: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.Hash(qb, opts.(*qbcli.HashOptions))
+	},
+}
+
+func init() {
+	recordsHashCmd.Add(recordsCmd, &globalCfg)
+}
@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/QuickBase/quickbase-cli/qbcli"
 	"github.com/QuickBase/quickbase-cli/qbclient"
 	"github.com/cpliakas/cliutil"
@@ -10,6 +12,9 @@ import (
 
 var recordsInsertCfg *viper.Viper
 
+// recordsInsertFiles holds the values of the repeatable --file option.
+var recordsInsertFiles []string
+
 var recordsInsertCmd = &cobra.Command{
 	Use:   "insert",
 	Short: "Insert and/or update records in a table",
@@ -29,9 +34,31 @@ var recordsInsertCmd = &cobra.Command{
 		err := qbcli.CacheTableSchema(qb, recordsInsertCfg.GetString("to"))
 		qbcli.HandleError(ctx, logger, "error setting field type map", err)
 
+		// Read the records from a CSV file if one is passed.
+		csvOpts := &qbcli.InsertCSVOptions{}
+		qbcli.GetOptions(ctx, logger, csvOpts, recordsInsertCfg)
+		if csvOpts.CSVFile != "" {
+			if len(recordsInsertFiles) > 0 {
+				qbcli.HandleError(ctx, logger, "file option not valid", fmt.Errorf("option %q can't be combined with %q", qbcli.OptionFile, "csv-file"))
+			}
+			output, err := qbcli.InsertCSV(ctx, logger, qb, globalCfg, recordsInsertCfg.GetString("to"), recordsInsertCfg.GetInt("merge-field-id"), csvOpts)
+			qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+			return
+		}
+
 		input := &qbclient.InsertRecordsInput{}
 		qbcli.GetOptions(ctx, logger, input, recordsInsertCfg)
 
+		// Attach the files passed through --file to the record.
+		if len(recordsInsertFiles) > 0 {
+			attachOpts := &qbcli.AttachOptions{}
+			qbcli.GetOptions(ctx, logger, attachOpts, recordsInsertCfg)
+
+			schema, _ := qbcli.GetCachedTableSchema(input.To)
+			err := qbcli.AttachFiles(schema, input.Data[0], recordsInsertFiles, attachOpts.MaxFileSize)
+			qbcli.HandleError(ctx, logger, "file option not valid", err)
+		}
+
 		output, err := qb.InsertRecords(input)
 		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
 	},
@@ -41,4 +68,7 @@ func init() {
 	var flags *cliutil.Flagger
 	recordsInsertCfg, flags = cliutil.AddCommand(recordsCmd, recordsInsertCmd, qbclient.EnvPrefix)
 	flags.SetOptions(&qbclient.InsertRecordsInput{})
+	flags.SetOptions(&qbcli.InsertCSVOptions{})
+	flags.SetOptions(&qbcli.AttachOptions{})
+	recordsInsertCmd.Flags().StringArrayVar(&recordsInsertFiles, qbcli.OptionFile, nil, qbcli.OptionFileDescription)
 }
@@ -1,41 +1,30 @@
 package cmd
 
 import (
+	"context"
+
 	"github.com/QuickBase/quickbase-cli/qbcli"
 	"github.com/QuickBase/quickbase-cli/qbclient"
 	"github.com/cpliakas/cliutil"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
-var recordsQueryCfg *viper.Viper
-
-var recordsQueryCmd = &cobra.Command{
-	Use:   "query",
-	Short: "Query records in a table using the Quick Base query language",
-
-	Args: func(cmd *cobra.Command, args []string) (err error) {
-		if err = globalCfg.Validate(); err == nil {
-			globalCfg.SetDefaultTableID(recordsQueryCfg)
-			qbcli.SetOptionFromArg(recordsQueryCfg, args, 0, qbclient.OptionTableID)
-			recordsQueryCfg.SetDefault("from", recordsQueryCfg.GetString(qbclient.OptionTableID))
-		}
-		return
+var recordsQueryCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "query",
+		Short: "Query records in a table using the Quick Base query language",
 	},
 
-	Run: func(cmd *cobra.Command, args []string) {
-		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
-
-		input := &qbclient.QueryRecordsInput{Options: &qbclient.QueryRecordsInputOptions{}}
-		qbcli.GetOptions(ctx, logger, input, recordsQueryCfg)
+	Options:        func() interface{} { return qbcli.NewQueryRecordsOptions() },
+	Args:           []string{qbclient.OptionTableID},
+	DefaultTableID: true,
+	Prepare:        qbcli.PrepareQueryRecords,
 
-		output, err := qb.QueryRecords(input)
-		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.QueryRecords(ctx, logger, qb, globalCfg, opts.(*qbcli.QueryRecordsOptions))
 	},
 }
 
 func init() {
-	var flags *cliutil.Flagger
-	recordsQueryCfg, flags = cliutil.AddCommand(recordsCmd, recordsQueryCmd, qbclient.EnvPrefix)
-	flags.SetOptions(&qbclient.QueryRecordsInput{Options: &qbclient.QueryRecordsInputOptions{}})
+	recordsQueryCmd.Add(recordsCmd, &globalCfg)
 }
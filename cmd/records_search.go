@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var recordsSearchCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "search",
+		Short: "Search a field for a term and output the records ranked by match quality",
+	},
+
+	Options:        func() interface{} { return &qbcli.RecordsSearchOptions{} },
+	Args:           []string{qbclient.OptionTableID},
+	DefaultTableID: true,
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.RecordsSearch(qb, opts.(*qbcli.RecordsSearchOptions))
+	},
+}
+
+func init() {
+	recordsSearchCmd.Add(recordsCmd, &globalCfg)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var recordsTouchCfg *viper.Viper
+
+var recordsTouchCmd = &cobra.Command{
+	Use:   "touch",
+	Short: "Update the Date Modified field of records without changing their data",
+
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		if err = globalCfg.Validate(); err == nil {
+			globalCfg.SetDefaultTableID(recordsTouchCfg)
+			qbcli.SetOptionFromArg(recordsTouchCfg, args, 0, qbclient.OptionTableID)
+		}
+		return
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
+
+		opts := &qbcli.TouchOptions{}
+		qbcli.GetOptions(ctx, logger, opts, recordsTouchCfg)
+
+		output, err := qbcli.Touch(ctx, logger, qb, globalCfg, opts)
+		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	},
+}
+
+func init() {
+	var flags *cliutil.Flagger
+	recordsTouchCfg, flags = cliutil.AddCommand(recordsCmd, recordsTouchCmd, qbclient.EnvPrefix)
+	flags.SetOptions(&qbcli.TouchOptions{})
+}
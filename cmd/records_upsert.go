@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// recordsUpsertFiles holds the values of the repeatable --file option.
+var recordsUpsertFiles []string
+
+var recordsUpsertCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "upsert",
+		Short: "Insert records, or update them if they match an existing record on a key field",
+	},
+
+	Options:        func() interface{} { return &qbcli.UpsertOptions{} },
+	Args:           []string{qbclient.OptionTableID},
+	DefaultTableID: true,
+
+	// The records passed through --data are parsed with the field types.
+	Prepare: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg *viper.Viper) {
+		cfg.SetDefault("to", cfg.GetString(qbclient.OptionTableID))
+		err := qbcli.CacheTableSchema(qb, cfg.GetString("to"))
+		qbcli.HandleError(ctx, logger, "error setting field type map", err)
+	},
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		uopts := opts.(*qbcli.UpsertOptions)
+		uopts.Files = recordsUpsertFiles
+		return qbcli.Upsert(ctx, logger, qb, globalCfg, uopts)
+	},
+}
+
+func init() {
+	recordsUpsertCmd.Add(recordsCmd, &globalCfg)
+	recordsUpsertCmd.Cmd.Flags().StringArrayVar(&recordsUpsertFiles, qbcli.OptionFile, nil, qbcli.OptionFileDescription)
+}
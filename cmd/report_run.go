@@ -26,10 +26,10 @@ var reportRunCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
 
-		input := &qbclient.RunReportInput{}
-		qbcli.GetOptions(ctx, logger, input, reportRunCfg)
+		opts := &qbcli.RunReportOptions{}
+		qbcli.GetOptions(ctx, logger, opts, reportRunCfg)
 
-		output, err := qb.RunReport(input)
+		output, err := qbcli.RunReport(ctx, logger, qb, globalCfg, opts)
 		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
 	},
 }
@@ -37,5 +37,5 @@ var reportRunCmd = &cobra.Command{
 func init() {
 	var flags *cliutil.Flagger
 	reportRunCfg, flags = cliutil.AddCommand(reportCmd, reportRunCmd, qbclient.EnvPrefix)
-	flags.SetOptions(&qbclient.RunReportInput{})
+	flags.SetOptions(&qbcli.RunReportOptions{})
 }
@@ -21,10 +21,12 @@ var rootCmd = &cobra.Command{
 	},
 }
 
-// Execute runs the command line tool.
+// Execute runs the command line tool. Errors returned by cobra are usage and
+// configuration errors, e.g., unknown flags or an invalid realm hostname, so
+// they exit with qbcli.ExitValidation.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(qbcli.ExitValidation)
 	}
 }
 
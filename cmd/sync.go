@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var syncCfg *viper.Viper
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Replicate records from a source table to a destination table",
+
+	Args: func(cmd *cobra.Command, args []string) (err error) {
+		if err = globalCfg.Validate(); err == nil {
+			qbcli.SetOptionFromArg(syncCfg, args, 0, "source")
+			qbcli.SetOptionFromArg(syncCfg, args, 1, "dest")
+		}
+		return
+	},
+
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx, logger, qb := qbcli.NewClient(cmd, globalCfg)
+
+		opts := &qbcli.SyncOptions{}
+		qbcli.GetOptions(ctx, logger, opts, syncCfg)
+
+		output, err := qbcli.Sync(ctx, logger, qb, globalCfg, opts)
+		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+	},
+}
+
+func init() {
+	var flags *cliutil.Flagger
+	syncCfg, flags = cliutil.AddCommand(rootCmd, syncCmd, qbclient.EnvPrefix)
+	flags.SetOptions(&qbcli.SyncOptions{})
+}
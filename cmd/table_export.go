@@ -28,7 +28,7 @@ var tableExportCmd = &cobra.Command{
 		opts := &qbcli.ExportOptions{}
 		qbcli.GetOptions(ctx, logger, opts, tableExportCfg)
 
-		err := qbcli.Export(qb, opts)
+		err := qbcli.Export(ctx, logger, qb, opts)
 		qbcli.HandleError(ctx, logger, "error exporting records", err)
 	},
 }
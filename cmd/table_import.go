@@ -16,6 +16,7 @@ var tableImportCmd = &cobra.Command{
 
 	Args: func(cmd *cobra.Command, args []string) (err error) {
 		if err = globalCfg.Validate(); err == nil {
+			globalCfg.SetDefaultAppID(tableImportCfg)
 			globalCfg.SetDefaultTableID(tableImportCfg)
 			qbcli.SetOptionFromArg(tableImportCfg, args, 0, qbclient.OptionTableID)
 		}
@@ -28,7 +29,14 @@ var tableImportCmd = &cobra.Command{
 		opts := &qbcli.ImportOptions{}
 		qbcli.GetOptions(ctx, logger, opts, tableImportCfg)
 
-		output, err := qbcli.Import(qb, opts)
+		// Validate the data without writing any records.
+		if opts.ValidateOnly {
+			output, err := qbcli.ValidateImport(ctx, logger, qb, opts)
+			qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
+			return
+		}
+
+		output, err := qbcli.Import(ctx, logger, qb, globalCfg, opts)
 		qbcli.Render(ctx, logger, cmd, globalCfg, output, err)
 	},
 }
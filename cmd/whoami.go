@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+var whoamiCmd = &qbcli.Command{
+	Cmd: &cobra.Command{
+		Use:   "whoami",
+		Short: "Verify the configured realm hostname and token",
+		Long: `Verify the configured realm hostname and token
+
+Makes a lightweight authenticated request with the credential resolved from
+the same flags, environment variables, and profile as every other command,
+then prints the realm hostname, the auth method, and whether the request
+succeeded. A user token lists the user's apps, and a temporary token reads the
+app passed through --app-id. Failures report a wrong realm hostname, an
+invalid token, or a network error.`,
+	},
+
+	Run: func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error) {
+		return qbcli.WhoAmI(qb, globalCfg)
+	},
+}
+
+func init() {
+	whoamiCmd.Add(rootCmd, &globalCfg)
+}
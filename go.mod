@@ -10,11 +10,14 @@ require (
 	github.com/go-playground/validator/v10 v10.6.1
 	github.com/hashicorp/go-retryablehttp v0.7.0
 	github.com/jedib0t/go-pretty/v6 v6.2.1
+	github.com/jmespath/go-jmespath v0.4.0
 	github.com/leodido/go-urn v1.2.1 // indirect
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/pkg/browser v0.0.0-20210115035449-ce105d075bb4
 	github.com/rs/xid v1.3.0
 	github.com/spf13/cobra v1.1.3
+	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.7.1
+	golang.org/x/text v0.3.5
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
@@ -0,0 +1,74 @@
+package qbcli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+)
+
+// OptionFile is the repeatable option of the commands that insert records
+// that attaches files to file attachment fields.
+const OptionFile = "file"
+
+// OptionFileDescription is the description of OptionFile.
+const OptionFileDescription = "file attached to a file attachment field as fieldID=path, repeat to attach several files"
+
+// AttachOptions are the options of the commands that attach files passed
+// through --file.
+type AttachOptions struct {
+	MaxFileSize int `validate:"min=1" cliutil:"option=max-file-size default=100 usage='maximum size in megabytes of the files attached through --file'"`
+}
+
+// AttachFiles adds the files passed as fieldID=path pairs to the record. Each
+// field must be a file attachment field in the table, and each file must be
+// readable and no larger than maxSize megabytes. All files are checked and
+// read before the record is changed, so nothing is sent if any of them isn't
+// valid. The file is uploaded under the base name of its path.
+func AttachFiles(schema FieldMap, record map[int]*qbclient.InsertRecordsInputData, files []string, maxSize int) error {
+	values := make(map[int]*qbclient.Value, len(files))
+
+	for _, file := range files {
+		parts := strings.SplitN(file, "=", 2)
+		fid, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if len(parts) != 2 || err != nil || parts[1] == "" {
+			return qberrors.Client(nil).Safef(qberrors.InvalidInput, "value %q for option %q must be a field ID and a path, e.g., 12=report.pdf", file, OptionFile)
+		}
+		path := parts[1]
+
+		field, ok := schema[fid]
+		switch {
+		case !ok:
+			return qberrors.Client(nil).Safef(qberrors.InvalidInput, "field %v not in table", fid)
+		case field.Type != qbclient.FieldFileAttachment:
+			return qberrors.Client(nil).Safef(qberrors.InvalidInput, "field %v (%s) is a %s field, not a file attachment", fid, field.Label, field.Type)
+		case values[fid] != nil:
+			return qberrors.Client(nil).Safef(qberrors.InvalidInput, "more than one file passed for field %v", fid)
+		}
+
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			return qberrors.Client(err).Safef(qberrors.InvalidInput, "file %q not readable", path)
+		case info.IsDir():
+			return qberrors.Client(nil).Safef(qberrors.InvalidInput, "file %q is a directory", path)
+		case info.Size() > int64(maxSize)<<20:
+			return qberrors.Client(nil).Safef(qberrors.InvalidInput, "file %q is larger than the %v MB allowed by --max-file-size", path, maxSize)
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return qberrors.Client(err).Safef(qberrors.InvalidInput, "file %q not readable", path)
+		}
+		values[fid] = qbclient.NewFileUploadValue(filepath.Base(path), b)
+	}
+
+	for fid, v := range values {
+		record[fid] = &qbclient.InsertRecordsInputData{Value: v}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package qbcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+	"github.com/araddon/dateparse"
+)
+
+// AppActivityOptions contains the options for AppActivity.
+type AppActivityOptions struct {
+	AppID  string `validate:"required" cliutil:"option=app-id"`
+	Since  string `validate:"required" cliutil:"option=since usage='start of the time range, e.g., 2021-06-01 or 2021-06-01T09:00:00Z'"`
+	Until  string `cliutil:"option=until usage='end of the time range, defaults to now'"`
+	Topics string `cliutil:"option=topic usage='comma-separated topic IDs of the events to return'"`
+	Users  string `cliutil:"option=user usage='comma-separated email addresses of the users whose events are returned'"`
+}
+
+// AppActivityOutput contains the audit log events of an app.
+type AppActivityOutput struct {
+	Events []*qbclient.GetAuditLogsOutputEvent
+}
+
+// MarshalJSON implements json.MarshalJSON by marshaling the events as an
+// array.
+func (o *AppActivityOutput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Events)
+}
+
+// TableHeader implements Tabular.TableHeader.
+func (o *AppActivityOutput) TableHeader() []string {
+	return []string{"Time", "Email", "Name", "Topic", "IP Address", "Description"}
+}
+
+// TableRows implements Tabular.TableRows.
+func (o *AppActivityOutput) TableRows() [][]string {
+	rows := make([][]string, len(o.Events))
+	for idx, e := range o.Events {
+		rows[idx] = []string{
+			e.Time,
+			e.Email,
+			strings.TrimSpace(e.FirstName + " " + e.LastName),
+			e.TopicID,
+			e.IPAddress,
+			e.Description,
+		}
+	}
+	return rows
+}
+
+// AppActivity returns the audit log events of an app in a time range. The
+// audit log is queried one day at a time, since that is what the API
+// supports, and each day is paged through. Events are kept if their
+// application is the app's ID or name, their time is in the range, and their
+// user is one of opts.Users if passed. Reading the audit log requires a realm
+// admin's token.
+func AppActivity(qb *qbclient.Client, opts *AppActivityOptions) (*AppActivityOutput, error) {
+	output := &AppActivityOutput{Events: []*qbclient.GetAuditLogsOutputEvent{}}
+
+	since, err := dateparse.ParseAny(opts.Since)
+	if err != nil {
+		return output, invalidTimeError("since", opts.Since)
+	}
+
+	until := time.Now()
+	if opts.Until != "" {
+		if until, err = dateparse.ParseAny(opts.Until); err != nil {
+			return output, invalidTimeError("until", opts.Until)
+		}
+	}
+
+	if until.Before(since) {
+		return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "option %q must be after option %q", "until", "since")
+	}
+
+	app, err := qb.GetAppByID(opts.AppID)
+	if err != nil {
+		return output, fmt.Errorf("error getting app: %w", err)
+	}
+
+	users := map[string]bool{}
+	for _, u := range splitList(opts.Users) {
+		users[strings.ToLower(u)] = true
+	}
+
+	day := since.UTC().Truncate(24 * time.Hour)
+	for !day.After(until.UTC()) {
+		input := &qbclient.GetAuditLogsInput{
+			Date:   day.Format(qbclient.FormatDate),
+			Topics: splitList(opts.Topics),
+		}
+
+		for {
+			logs, err := qb.GetAuditLogs(input)
+			if err != nil {
+				return output, fmt.Errorf("error getting audit logs for %s: %w", input.Date, err)
+			}
+
+			for _, e := range logs.Events {
+				if e.Application != opts.AppID && e.Application != app.Name {
+					continue
+				}
+				if len(users) > 0 && !users[strings.ToLower(e.Email)] {
+					continue
+				}
+				if tm, err := time.Parse(time.RFC3339, e.Time); err == nil && (tm.Before(since) || tm.After(until)) {
+					continue
+				}
+				output.Events = append(output.Events, e)
+			}
+
+			if logs.NextToken == "" {
+				break
+			}
+			input.QueryID = logs.QueryID
+			input.NextToken = logs.NextToken
+		}
+
+		day = day.Add(24 * time.Hour)
+	}
+
+	return output, nil
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// invalidTimeError returns an error for an option that isn't a valid time.
+func invalidTimeError(option, value string) error {
+	return qberrors.Client(nil).Safef(qberrors.InvalidInput, "value %q for option %q is not a valid time", value, option)
+}
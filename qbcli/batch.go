@@ -1,6 +1,7 @@
 package qbcli
 
 import (
+	"context"
 	"encoding/csv"
 	"errors"
 	"fmt"
@@ -8,33 +9,41 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+	"github.com/cpliakas/cliutil"
+)
+
+// LineEnding* constants contain the line terminators --line-endings accepts.
+const (
+	LineEndingLF   = "lf"
+	LineEndingCRLF = "crlf"
 )
 
 // ExportOptions are the options read through the command line.
 type ExportOptions struct {
-	TableID   string `validate:"required" cliutil:"option=table-id"`
-	Filepath  string `cliutil:"option=file usage='file the data is exported to'"`
-	BatchSize int    `cliutil:"option=batch-size default=10000"`
-	Delay     int    `cliutil:"option=delay"`
+	TableID  string `validate:"required" cliutil:"option=table-id"`
+	Filepath string `cliutil:"option=file usage='file the data is exported to'"`
+	Delay    int    `cliutil:"option=delay"`
+	SplitBy  int    `cliutil:"option=split-by usage='field ID whose values the records are split by, writing one file per value to --out-dir'"`
+	OutDir   string `cliutil:"option=out-dir usage='directory the files written by --split-by are written to'"`
+
+	SchemaCheckOptions
 
 	// Fields    []int  `cliutil:"option=fields"`
 }
 
-// Export exports data from a Quickbase table into an io.Writer.
-func Export(qb *qbclient.Client, opts *ExportOptions) error {
-
-	var file io.Writer
-	if opts.Filepath != "" {
-		var err error
-		file, err = os.OpenFile(opts.Filepath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
-		if err != nil {
-			return fmt.Errorf("error opening file: %w", err)
-		}
-	} else {
-		file = os.Stdout
+// Export exports data from a Quickbase table into an io.Writer, or into one
+// file per value of the opts.SplitBy field.
+func Export(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts *ExportOptions) error {
+	if opts.SplitBy > 0 && opts.Filepath != "" {
+		return qberrors.Client(nil).Safef(qberrors.InvalidInput, "options %q and %q are mutually exclusive", "split-by", "file")
+	}
+	if opts.SplitBy > 0 && opts.OutDir == "" {
+		return qberrors.Client(nil).Safef(qberrors.InvalidInput, "option %q is required with option %q", "out-dir", "split-by")
 	}
 
 	// Get the table's fields.
@@ -43,6 +52,10 @@ func Export(qb *qbclient.Client, opts *ExportOptions) error {
 		return fmt.Errorf("error getting table metadata: %w", err)
 	}
 
+	if _, ok := fields[opts.SplitBy]; opts.SplitBy > 0 && !ok {
+		return qberrors.Client(nil).Safef(qberrors.InvalidInput, "field %v not in table %s", opts.SplitBy, opts.TableID)
+	}
+
 	// Build a list of every fid.
 	fids := []int{}
 	for _, field := range fields {
@@ -50,50 +63,113 @@ func Export(qb *qbclient.Client, opts *ExportOptions) error {
 	}
 	sort.Ints(fids)
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	// Write the header.
+	// Build the header.
 	header := make([]string, len(fids))
 	for idx, fid := range fids {
 		header[idx] = fields[fid].Label
 	}
-	writer.Write(header)
 
-	// Batch read records.
-	size := opts.BatchSize
-	skip := 0
-	for {
+	// Batch read records, sorted by record ID.
+	input := &qbclient.QueryRecordsInput{
+		Select: fids,
+		From:   opts.TableID,
+		SortBy: []*qbclient.QueryRecordsInputSortBy{
+			{FieldID: 3, Order: qbclient.SortByASC},
+		},
+	}
 
-		// Query records, sorted by record ID.
-		qri := &qbclient.QueryRecordsInput{
-			Select: fids,
-			From:   opts.TableID,
-			SortBy: []*qbclient.QueryRecordsInputSortBy{
-				{FieldID: 3, Order: qbclient.SortByASC},
-			},
-			Options: &qbclient.QueryRecordsInputOptions{
-				Top:  size,
-				Skip: skip,
-			},
-		}
-		qro, err := qb.QueryRecords(qri)
+	if opts.SplitBy > 0 {
+		err = exportSplit(qb, opts, input, header)
+	} else {
+		err = exportFile(qb, opts, input, header)
+	}
+	if err != nil {
+		return err
+	}
+
+	return opts.checkSchema(ctx, logger, qb, opts.TableID)
+}
+
+// exportFile writes the records to opts.Filepath, or to stdout.
+func exportFile(qb *qbclient.Client, opts *ExportOptions, input *qbclient.QueryRecordsInput, header []string) error {
+	var file io.Writer
+	if opts.Filepath != "" {
+		f, err := os.OpenFile(opts.Filepath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 		if err != nil {
-			return fmt.Errorf("error querying records: %w", err)
+			return fmt.Errorf("error opening file: %w", err)
 		}
+		defer f.Close()
+		file = f
+	} else {
+		file = os.Stdout
+	}
+
+	writer := csv.NewWriter(file)
+	writer.UseCRLF = _crlf
+	defer writer.Flush()
+
+	// Write the header.
+	writer.Write(header)
+
+	return QueryRecordsPaged(qb, input, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
 
 		// Write the row data.
 		for _, record := range qro.Data {
-			row := make([]string, len(fids))
-			for idx, fid := range fids {
-				row[idx] = record[fid].Value.String()
-			}
-			writer.Write(row)
+			writer.Write(exportRow(record, input.Select))
 		}
 
 		// Flush the buffer.
 		writer.Flush()
-		if err = writer.Error(); err != nil {
+		return writer.Error()
+	})
+}
+
+// exportSplit writes the records to one file per value of the opts.SplitBy
+// field in opts.OutDir, routing each page of records as it is read.
+func exportSplit(qb *qbclient.Client, opts *ExportOptions, input *qbclient.QueryRecordsInput, header []string) error {
+	split, err := newSplitWriter(opts.OutDir, header, _crlf)
+	if err != nil {
+		return err
+	}
+
+	return QueryRecordsPaged(qb, input, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
+		rows := map[string][][]string{}
+		for _, record := range qro.Data {
+			value := recordString(record, opts.SplitBy)
+			rows[value] = append(rows[value], exportRow(record, input.Select))
+		}
+		return split.Write(rows)
+	})
+}
+
+// exportRow returns the values of the fields in a record.
+func exportRow(record map[int]*qbclient.RecordsData, fids []int) []string {
+	row := make([]string, len(fids))
+	for idx, fid := range fids {
+		row[idx] = record[fid].Value.String()
+	}
+	return row
+}
+
+// QueryRecordsPaged queries records in pages of size records, or of
+// --batch-size records if it is set, invoking fn with each page. The delay is
+// the number of milliseconds to pause between API calls. The input's options
+// are overwritten.
+func QueryRecordsPaged(qb *qbclient.Client, input *qbclient.QueryRecordsInput, size, delay int, fn func(*qbclient.QueryRecordsOutput) error) error {
+	size = batchSize(size)
+	skip := 0
+	for {
+		input.Options = &qbclient.QueryRecordsInputOptions{
+			Top:  size,
+			Skip: skip,
+		}
+
+		qro, err := qb.QueryRecords(input)
+		if err != nil {
+			return fmt.Errorf("error querying records: %w", err)
+		}
+
+		if err := fn(qro); err != nil {
 			return err
 		}
 
@@ -104,29 +180,264 @@ func Export(qb *qbclient.Client, opts *ExportOptions) error {
 		}
 
 		// Delay before the next API call.
-		if opts.Delay > 0 {
-			time.Sleep(time.Duration(opts.Delay) * time.Millisecond)
-		}
+		pauseBatch(delay)
 	}
 
 	return nil
 }
 
+// QueryAllRecords queries records like qb.QueryRecords, requesting the next
+// page until every record matching the query is read or max records are, 0 for
+// unlimited. The pages are concatenated into a single output, so rendering and
+// JMESPath filters apply to every record. The skip option sets the first
+// record, and the top option caps the number of records like max.
+func QueryAllRecords(qb *qbclient.Client, input *qbclient.QueryRecordsInput, max int) (*qbclient.QueryRecordsOutput, error) {
+	var output *qbclient.QueryRecordsOutput
+	return QueryPages(qb, input, max, func(qro *qbclient.QueryRecordsOutput) error {
+		if output == nil {
+			output = qro
+		} else {
+			output.Data = append(output.Data, qro.Data...)
+		}
+		return nil
+	})
+}
+
+// QueryPages reads the pages of records like QueryAllRecords, but passes each
+// page to fn as it is read instead of concatenating them, so the records can
+// be processed without holding all of them in memory. Each page requests up
+// to --batch-size records, or DefaultBatchSize if it isn't set, though the API
+// may return fewer. Reading stops at the first error returned by fn. The first page is returned with its metadata
+// describing every page read, and its data is whatever fn left in it.
+func QueryPages(qb *qbclient.Client, input *qbclient.QueryRecordsInput, max int, fn func(*qbclient.QueryRecordsOutput) error) (*qbclient.QueryRecordsOutput, error) {
+	if input.Options == nil {
+		input.Options = &qbclient.QueryRecordsInputOptions{}
+	}
+	skip, top := input.Options.Skip, input.Options.Top
+	if top > 0 && (max == 0 || top < max) {
+		max = top
+	}
+
+	var output *qbclient.QueryRecordsOutput
+	size := batchSize(DefaultBatchSize)
+	num := 0
+	for {
+		input.Options.Skip = skip + num
+		input.Options.Top = size
+		if max > 0 && max-num < size {
+			input.Options.Top = max - num
+		}
+
+		qro, err := qb.QueryRecords(input)
+		if output == nil {
+			if err != nil {
+				return qro, err
+			}
+			output = qro
+		} else if err != nil {
+			return output, fmt.Errorf("error querying records at skip %v: %w", input.Options.Skip, err)
+		}
+
+		n := len(qro.Data)
+		num += n
+		if err := fn(qro); err != nil {
+			return output, err
+		}
+		if qro.Metadata == nil || n == 0 || skip+num >= qro.Metadata.TotalRecords || (max > 0 && num >= max) {
+			break
+		}
+
+		// Delay before the next API call.
+		pauseBatch(0)
+	}
+
+	if output.Metadata != nil {
+		output.Metadata.NumRecords = num
+		output.Metadata.Skip = skip
+		output.Metadata.Top = top
+	}
+	return output, nil
+}
+
+// _crlf is set through --line-endings and terminates the lines of the files
+// written by bulk commands with \r\n instead of \n.
+var _crlf bool
+
+// _batchDelay is the minimum pause between batches set through --batch-delay.
+var _batchDelay time.Duration
+
+// _batchSize is the number of records in each API call set through
+// --batch-size, or 0 if each command uses its own default.
+var _batchSize int
+
+// batchSize returns the number of records in each API call, which is
+// --batch-size if it is set and def otherwise.
+func batchSize(def int) int {
+	if _batchSize > 0 {
+		return _batchSize
+	}
+	return def
+}
+
+// pauseBatch pauses between batches for the longer of delay, in milliseconds,
+// and _batchDelay. Callers skip it after the final batch.
+func pauseBatch(delay int) {
+	d := time.Duration(delay) * time.Millisecond
+	if _batchDelay > d {
+		d = _batchDelay
+	}
+	if d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// deleteBatchSize is the number of record IDs in each delete request, which
+// keeps the where clause to a reasonable length.
+const deleteBatchSize = 100
+
+// DeleteRecordIDs deletes records by ID in batches, returning the number of
+// records deleted and the batches that failed in best-effort mode. The delay
+// is the number of milliseconds to pause between API calls.
+func DeleteRecordIDs(qb *qbclient.Client, tableID string, rids []int, delay int, mode ErrorModeOptions) (int, []*BatchError, error) {
+	deleted := 0
+	errs := []*BatchError{}
+	for start := 0; start < len(rids); start += deleteBatchSize {
+		end := start + deleteBatchSize
+		if end > len(rids) {
+			end = len(rids)
+		}
+
+		clauses := make([]string, end-start)
+		for idx, rid := range rids[start:end] {
+			clauses[idx] = "{3.EX." + strconv.Itoa(rid) + "}"
+		}
+
+		dro, err := qb.DeleteRecords(&qbclient.DeleteRecordsInput{
+			From:  tableID,
+			Where: strings.Join(clauses, "OR"),
+		})
+		if err != nil {
+			err = fmt.Errorf("error deleting records: %w", err)
+			if err := mode.batchError(&errs, start/deleteBatchSize+1, err); err != nil {
+				return deleted, errs, err
+			}
+		} else {
+			deleted += dro.NumberDeleted
+		}
+
+		// Delay before the next API call.
+		if end < len(rids) {
+			pauseBatch(delay)
+		}
+	}
+
+	return deleted, errs, nil
+}
+
 // ImportOptions are the options read through the command line.
 type ImportOptions struct {
 	TableID      string            `validate:"required" cliutil:"option=table-id"`
 	Filepath     string            `cliutil:"option=file usage='file the data is imported from'"`
-	BatchSize    int               `cliutil:"option=batch-size default=10000"`
 	Map          map[string]string `cliutil:"option=map"`
+	MapFile      string            `cliutil:"option=map-file usage='YAML file that maps csv header labels to destination field labels, an empty label skips the column'"`
 	Delay        int               `cliutil:"option=delay"`
-	Timeout      int               `cliutil:"option=timeout default=5 usage='timeout in seconds waiting for data to be read from stdin'"`
-	MergeFieldID int               `cliutil:"option=merge-field-id"`
+	StdinTimeout int               `cliutil:"option=stdin-timeout default=5 usage='timeout in seconds waiting for data to be read from stdin'"`
+	MergeField   string            `cliutil:"option=merge-field-id default=auto usage='field ID used to merge records, or auto to use the key field of the table'"`
+	AppID        string            `cliutil:"option=app-id usage='unique identifier of the app, required to detect the key field'"`
+	IDMap        string            `cliutil:"option=id-map usage='YAML file that maps source record IDs to destination record IDs in reference fields'"`
+	OnUnmapped   string            `validate:"oneof=error null" cliutil:"option=on-unmapped default=error usage='action taken on record IDs not in the ID map, either error or null'"`
+	ValidateOnly bool              `cliutil:"option=validate-only usage='validate the data without importing it'"`
+	ErrorFile    string            `cliutil:"option=error-file usage='file rows that fail are written to'"`
+	ErrorSummary string            `cliutil:"option=error-summary usage='file a JSON summary of the errors grouped by message is written to'"`
+	MaxErrors    int               `cliutil:"option=max-field-errors usage='abort the import once this many rows fail, 0 for unlimited'"`
+
+	ErrorModeOptions
+	SchemaCheckOptions
+
+	AdaptiveBatch  bool `cliutil:"option=adaptive-batch usage='adjust the batch size based on the latency and error rate of each batch'"`
+	AdaptiveTarget int  `cliutil:"option=adaptive-target default=10 usage='target latency in seconds for each batch when --adaptive-batch is set'"`
+
+	// MergeFieldID is the field ID resolved from MergeField.
+	MergeFieldID int
+
+	// BatchSize is the number of records in each API call unless --batch-size
+	// is passed, DefaultBulkBatchSize if 0.
+	BatchSize int
 
 	// Fields    []int  `cliutil:"option=fields"`
 }
 
-// Import imports data from an io.Reader into a Quickbase table.
-func Import(qb *qbclient.Client, opts *ImportOptions) (*qbclient.InsertRecordsOutputMetadata, error) {
+// MergeFieldAuto is the --merge-field-id value that uses the table's key field.
+const MergeFieldAuto = "auto"
+
+// resolveMergeField sets opts.MergeFieldID from opts.MergeField. The "auto"
+// value uses the table's key field if it is in the header. Records are inserted
+// without merging if the key field is the record ID, since record IDs rarely
+// match across tables, or if the key field cannot be detected.
+func resolveMergeField(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts *ImportOptions, reader *importReader) error {
+	if opts.MergeField != MergeFieldAuto {
+		if opts.MergeField == "" {
+			opts.MergeFieldID = 0
+			return nil
+		}
+
+		fid, err := strconv.Atoi(opts.MergeField)
+		if err != nil {
+			return fmt.Errorf("merge-field-id option must be a field ID or %q: %w", MergeFieldAuto, err)
+		}
+		opts.MergeFieldID = fid
+		return nil
+	}
+
+	opts.MergeFieldID = 0
+
+	if opts.AppID == "" {
+		logger.Notice(ctx, "app ID not set, inserting records without detecting the key field")
+		return nil
+	}
+
+	table, err := qb.GetTable(&qbclient.GetTableInput{AppID: opts.AppID, TableID: opts.TableID})
+	if err != nil {
+		return fmt.Errorf("error getting table: %w", err)
+	}
+
+	ctx = cliutil.ContextWithLogTag(ctx, "fid", strconv.Itoa(table.KeyFieldID))
+	if table.KeyFieldID == 0 || table.KeyFieldID == 3 {
+		logger.Notice(ctx, "key field is the record ID, inserting records without merging")
+		return nil
+	}
+
+	for _, fid := range reader.fids {
+		if fid == table.KeyFieldID {
+			opts.MergeFieldID = fid
+			if field, ok := reader.fields[fid]; ok {
+				ctx = cliutil.ContextWithLogTag(ctx, "label", field.Label)
+			}
+			logger.Notice(ctx, "merging records on the table's key field")
+			return nil
+		}
+	}
+
+	logger.Notice(ctx, "key field not in data, inserting records without merging")
+	return nil
+}
+
+// ImportOutput is the result of importing data.
+type ImportOutput struct {
+	*qbclient.InsertRecordsOutputMetadata
+
+	BatchErrors []*BatchError `json:"batchErrors,omitempty"`
+}
+
+// Failures implements FailureCounter.Failures.
+func (o *ImportOutput) Failures() int { return len(o.LineErrors) + len(o.BatchErrors) }
+
+// Import imports data from an io.Reader into a Quickbase table. The data is
+// read row by row, so no more than a batch of rows are held in memory. The
+// rows of a file are counted first and require confirmation above the
+// confirm-count-threshold, but data read from stdin can't be read twice and
+// isn't counted.
+func Import(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *ImportOptions) (*ImportOutput, error) {
 	metadata := &qbclient.InsertRecordsOutputMetadata{
 		CreatedRecordIDs:              []int{},
 		LineErrors:                    map[string][]string{},
@@ -134,150 +445,183 @@ func Import(qb *qbclient.Client, opts *ImportOptions) (*qbclient.InsertRecordsOu
 		UnchangedRecordIDs:            []int{},
 		UpdatedRecordIDs:              []int{},
 	}
+	output := &ImportOutput{InsertRecordsOutputMetadata: metadata, BatchErrors: []*BatchError{}}
+
+	if err := opts.validate(); err != nil {
+		return output, err
+	}
 
-	var file io.Reader
 	if opts.Filepath != "" {
-		var err error
-		file, err = os.Open(opts.Filepath)
+		rows, err := countImportRows(opts.Filepath)
 		if err != nil {
-			return metadata, fmt.Errorf("error opening file: %w", err)
+			return output, err
 		}
-	} else {
-		file = os.Stdin
-		if err := waitStdin(opts.Timeout); err != nil {
-			return metadata, err
+
+		label := fmt.Sprintf("Import %v records into table %s?", rows, opts.TableID)
+		ok, err := ConfirmCount(cfg, label, rows, true)
+		if err != nil {
+			return output, err
+		}
+		if !ok {
+			logger.Notice(ctx, "no records imported")
+			return output, nil
 		}
 	}
 
-	// Get the table's fields.
-	fields, err := GetTableSchema(qb, opts.TableID)
+	file, err := openImportFile(opts)
 	if err != nil {
-		return metadata, fmt.Errorf("error getting table metadata: %w", err)
+		return output, err
 	}
+	defer file.Close()
 
-	// Build a map of field label to fid.
-	lmap := make(map[string]int, len(fields))
-	for _, field := range fields {
-		lmap[field.Label] = field.FieldID
+	reader, err := prepareImport(ctx, logger, qb, file, opts)
+	if err != nil {
+		return output, err
 	}
 
-	reader := csv.NewReader(file)
-	fmap := []int{}
+	ef, err := newErrorFile(opts.ErrorFile, reader.header)
+	if err != nil {
+		return output, err
+	}
+	defer ef.Close()
 
-	// Batch write records.
-	line := 0
+	// Stream the records, buffering only the rows in the current batch. The
+	// line numbers and original rows are tracked so that line errors can be
+	// mapped back to the input and written to the error file.
 	eof := false
 	records := []map[int]*qbclient.InsertRecordsInputData{}
+	lines := []int{}
+	rows := [][]string{}
+	sizer := newBatchSizer(opts)
+	batch := 0
 
 	for {
 
 		// Read each record from the CSV data.
-		// TODO better error handling.
-		row, err := reader.Read()
+		line, row, err := reader.Read()
 		if err == io.EOF {
 			eof = true
 		} else if err != nil {
-			return metadata, fmt.Errorf("error reading line %v: %w", line, err)
+			return output, err
 		}
 
-		// If first line, map the header to field IDs.
-		// Otherwise, build the data records.
+		// Build the data records. Rows that cannot be converted are skipped
+		// and reported unless --fail-fast is set.
 		if !eof {
-			if line == 0 {
-				for _, label := range row {
-
-					// Check the field label map first.
-					if destLabel, ok := opts.Map[label]; ok {
-						label = destLabel
-					}
-
-					// Now get the field ID.
-					fid, ok := lmap[label]
-					if !ok {
-						return metadata, fmt.Errorf("%s field not in destination table", label)
-					}
-
-					// Append the fid from the field map.
-					fmap = append(fmap, fid)
+			record, errs := reader.Convert(row)
+			if len(errs) > 0 {
+				if opts.FailFast {
+					return output, fmt.Errorf("line %v: %w", line, errs[0])
 				}
-			} else {
-
-				record := make(map[int]*qbclient.InsertRecordsInputData)
-				for idx, data := range row {
-
-					// TODO defensive coding ...
-					fid := fmap[idx]
-
-					// We cannot insert record metadata.
-					if fid != 3 && fid <= 5 {
-						continue
-					}
 
-					// Skip the record ID if it isn't the merge field.
-					if fid == 3 && opts.MergeFieldID != 3 {
-						continue
-					}
-
-					// TODO defensive coding ...
-					ftype := fields[fid].Type
-
-					// Create a *qbclient.Value from the string value and field type.
-					val, err := qbclient.NewValueFromString(data, ftype)
-					if err != nil {
-						return metadata, fmt.Errorf("value invalid for field %v: %w", fid, err)
-					}
-
-					// Add the value to the record .
-					record[fid] = &qbclient.InsertRecordsInputData{Value: val}
+				lerrs := make([]string, len(errs))
+				for idx, cerr := range errs {
+					lerrs[idx] = cerr.Error()
 				}
-
+				metadata.LineErrors[strconv.Itoa(line)] = lerrs
+				if err := ef.Write(line, lerrs, row); err != nil {
+					return output, err
+				}
+				if err := checkMaxErrors(opts, metadata.LineErrors); err != nil {
+					return output, err
+				}
+			} else {
 				records = append(records, record)
+				lines = append(lines, line)
+				rows = append(rows, row)
 			}
 		}
 
-		// Write the batch if we hit the batch size of the end of the data set.
-		if len(records) >= opts.BatchSize || (len(records) > 0 && eof) {
+		// Write batches while we have a full batch or are at the end of the
+		// data set. The batch size might shrink in adaptive mode, in which
+		// case the buffered records are written over multiple requests.
+		for len(records) >= sizer.size || (len(records) > 0 && eof) {
+			n := len(records)
+			if n > sizer.size {
+				n = sizer.size
+			}
+
 			input := &qbclient.InsertRecordsInput{
 				To:           opts.TableID,
-				Data:         records,
+				Data:         records[:n],
 				MergeFieldID: opts.MergeFieldID,
 			}
 
+			start := time.Now()
 			iro, err := qb.InsertRecords(input)
+			latency := time.Since(start)
+
+			// Retry with a smaller batch if the error is transient.
+			if err != nil && sizer.adaptive && isRetryable(err) && sizer.size > adaptiveBatchMinSize {
+				sizer.observe(latency, true)
+				logBatchSize(ctx, logger, sizer.size, latency, "batch failed, reducing batch size")
+				continue
+			}
+
+			// Otherwise skip the batch in best-effort mode. The rows are
+			// written to the error file so they can be imported again.
+			batch++
 			if err != nil {
-				return metadata, fmt.Errorf("error inserting records: %w", err)
+				err = fmt.Errorf("error inserting records: %w", err)
+				if err := opts.batchError(&output.BatchErrors, batch, err); err != nil {
+					return output, err
+				}
+				for idx := 0; idx < n; idx++ {
+					if err := ef.Write(lines[idx], []string{err.Error()}, rows[idx]); err != nil {
+						return output, err
+					}
+				}
+				logger.Error(cliutil.ContextWithLogTag(ctx, "line", strconv.Itoa(lines[n-1])), "batch failed", err)
+				records = append([]map[int]*qbclient.InsertRecordsInputData{}, records[n:]...)
+				lines = append([]int{}, lines[n:]...)
+				rows = append([][]string{}, rows[n:]...)
+				continue
 			}
 
-			// Empty the records for the next batch.
-			rlen := len(records)
-			records = []map[int]*qbclient.InsertRecordsInputData{}
+			if sizer.observe(latency, false) {
+				logBatchSize(ctx, logger, sizer.size, latency, "batch size adjusted")
+			}
 
 			metadata.CreatedRecordIDs = append(metadata.CreatedRecordIDs, iro.Metadata.CreatedRecordIDs...)
 			metadata.TotalNumberOfRecordsProcessed += iro.Metadata.TotalNumberOfRecordsProcessed
 			metadata.UnchangedRecordIDs = append(metadata.UnchangedRecordIDs, iro.Metadata.UnchangedRecordIDs...)
 			metadata.UpdatedRecordIDs = append(metadata.UpdatedRecordIDs, iro.Metadata.UpdatedRecordIDs...)
 
-			// Merging the line errors isn't so simple.
+			// The lineErrors keys are 1-based positions in the batch.
 			for k, v := range iro.Metadata.LineErrors {
-				n, err := strconv.Atoi(k)
+				pos, err := strconv.Atoi(k)
 				if err != nil {
-					return metadata, fmt.Errorf("%s: expecting lineErrors key to be an integer", k)
+					return output, fmt.Errorf("%s: expecting lineErrors key to be an integer", k)
 				}
-
-				// This works no matter the batch size, but I have no idea why
-				// we have to subtract 1 from n when eof is true. If this is
-				// buggy, blame cpliakas@quickbase.com.
-				n = line - rlen + n
-				if eof {
-					n--
+				if pos < 1 || pos > n {
+					return output, fmt.Errorf("%s: lineErrors key out of range", k)
+				}
+				metadata.LineErrors[strconv.Itoa(lines[pos-1])] = v
+				if err := ef.Write(lines[pos-1], v, rows[pos-1]); err != nil {
+					return output, err
 				}
-
-				metadata.LineErrors[strconv.Itoa(n)] = v
 			}
+			if err := checkMaxErrors(opts, metadata.LineErrors); err != nil {
+				return output, err
+			}
+			if err := opts.lineErrors(metadata.LineErrors); err != nil {
+				return output, err
+			}
+
+			// Report progress through the last line in the batch.
+			pctx := cliutil.ContextWithLogTag(ctx, "line", strconv.Itoa(lines[n-1]))
+			pctx = cliutil.ContextWithLogTag(pctx, "processed", strconv.Itoa(metadata.TotalNumberOfRecordsProcessed))
+			logger.Info(pctx, "batch imported")
+
+			// Remove the written records from the buffer, copying the rest so
+			// the written records can be garbage collected.
+			records = append([]map[int]*qbclient.InsertRecordsInputData{}, records[n:]...)
+			lines = append([]int{}, lines[n:]...)
+			rows = append([][]string{}, rows[n:]...)
 
 			// Delay before the next API call.
-			if opts.Delay > 0 && !eof {
-				time.Sleep(time.Duration(opts.Delay) * time.Millisecond)
+			if !eof || len(records) > 0 {
+				pauseBatch(opts.Delay)
 			}
 		}
 
@@ -285,11 +629,101 @@ func Import(qb *qbclient.Client, opts *ImportOptions) (*qbclient.InsertRecordsOu
 		if eof {
 			break
 		}
+	}
+
+	if err := writeErrorSummary(opts.ErrorSummary, metadata.LineErrors); err != nil {
+		return output, err
+	}
 
-		line++
+	return output, opts.checkSchema(ctx, logger, qb, opts.TableID)
+}
+
+// checkMaxErrors returns an error if the number of rows that failed reached
+// opts.MaxErrors. The error summary is written before aborting.
+func checkMaxErrors(opts *ImportOptions, lineErrors map[string][]string) error {
+	if opts.MaxErrors <= 0 || len(lineErrors) < opts.MaxErrors {
+		return nil
+	}
+
+	if err := writeErrorSummary(opts.ErrorSummary, lineErrors); err != nil {
+		return err
+	}
+
+	return TooManyErrorsError("import aborted after %v rows failed, the fields likely aren't mapped correctly or the file has a structural problem", len(lineErrors))
+}
+
+// Bounds of the batch size in adaptive mode.
+const (
+	adaptiveBatchMinSize = 10
+	adaptiveBatchMaxSize = MaxBatchSize
+)
+
+// batchSizer determines the number of records written in each batch.
+type batchSizer struct {
+	size     int
+	adaptive bool
+	target   time.Duration
+}
+
+func newBatchSizer(opts *ImportOptions) *batchSizer {
+	def := opts.BatchSize
+	if def < 1 {
+		def = DefaultBulkBatchSize
+	}
+
+	b := &batchSizer{
+		size:     batchSize(def),
+		adaptive: opts.AdaptiveBatch,
+		target:   time.Duration(opts.AdaptiveTarget) * time.Second,
 	}
+	if b.adaptive {
+		b.size = clampBatchSize(b.size)
+	}
+	return b
+}
+
+// observe adjusts the batch size in adaptive mode. The size is halved when a
+// batch fails or exceeds the target latency, and it grows by half when a batch
+// completes in under half the target latency. It returns whether the size
+// changed.
+func (b *batchSizer) observe(latency time.Duration, failed bool) bool {
+	if !b.adaptive {
+		return false
+	}
+
+	prev := b.size
+	switch {
+	case failed || latency > b.target:
+		b.size /= 2
+	case latency < b.target/2:
+		b.size += b.size / 2
+	}
+	b.size = clampBatchSize(b.size)
+
+	return b.size != prev
+}
+
+func clampBatchSize(size int) int {
+	if size < adaptiveBatchMinSize {
+		return adaptiveBatchMinSize
+	}
+	if size > adaptiveBatchMaxSize {
+		return adaptiveBatchMaxSize
+	}
+	return size
+}
+
+func logBatchSize(ctx context.Context, logger *cliutil.LeveledLogger, size int, latency time.Duration, message string) {
+	ctx = cliutil.ContextWithLogTag(ctx, "size", strconv.Itoa(size))
+	ctx = cliutil.ContextWithLogTag(ctx, "latency", latency.Round(time.Millisecond).String())
+	logger.Notice(ctx, message)
+}
 
-	return metadata, nil
+// isRetryable returns true if err is transient, e.g., a timeout or a 5xx
+// response from the API.
+func isRetryable(err error) bool {
+	var qerr qberrors.Error
+	return errors.As(err, &qerr) && qerr.Retry()
 }
 
 // TODO move this to cliutil.
@@ -5,6 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/QuickBase/quickbase-cli/qbclient"
 	"github.com/cpliakas/cliutil"
@@ -16,14 +19,32 @@ import (
 func NewLogger(cmd *cobra.Command, cfg GlobalConfig) (ctx context.Context, logger *cliutil.LeveledLogger, transid xid.ID) {
 	ctx, logger, transid = cliutil.NewLoggerWithContext(context.Background(), cfg.LogLevel())
 	logger.SetOutput(os.Stderr)
+	logger.SetMessageWriter(textMessageWriter)
+	_noColor = cfg.NoColor()
 
-	// Open the log file and set the logger to write to it.
+	// Open the log file and set the logger to write to it, as JSON objects
+	// if --log-format json was passed.
 	if logFile := cfg.LogFile(); logFile != "" {
 		file, err := os.OpenFile(logFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
 		HandleError(ctx, logger, "error opening log file", err)
 		logger.SetOutput(file)
+		if cfg.LogFormat() == LogFormatJSON {
+			logger.SetFlags(0)
+			logger.SetMessageWriter(jsonMessageWriter)
+		}
 	}
 
+	// Skipping validation can send requests the API rejects or misinterprets,
+	// so it's logged at the notice level to be visible by default.
+	_noValidate = cfg.NoValidate()
+	if _noValidate {
+		logger.Notice(ctx, "option validation disabled by --no-validate, requests are sent as-is")
+	}
+	_strictFIDs = cfg.StrictFIDs()
+	_jsonErrors = cfg.Format() == FormatJSON && !cfg.Quiet()
+	_compactErrors = cfg.CompactJSON(os.Stdout)
+	_crlf = cfg.LineEndings() == LineEndingCRLF
+
 	return
 }
 
@@ -34,20 +55,81 @@ func NewClient(cmd *cobra.Command, cfg GlobalConfig) (ctx context.Context, logge
 
 	// Instantiate the Quick Base API client with the logger plugin.
 	qb = qbclient.New(cfg)
+	qb.MaxRequests = cfg.MaxAPICalls()
+	qb.RetryBudget = cfg.RetryBudget()
+	qb.MaxRetries = cfg.MaxRetries()
+	qb.RetryMaxWait = cfg.RetryMaxWait()
+	qb.Timeout = cfg.Timeout()
+	qb.RateLimit = cfg.RateLimit()
+	qb.CompressRequests = cfg.CompressRequest()
+	qb.DryRun = cfg.DryRun()
+	_batchDelay = cfg.BatchDelay()
+	_batchSize = cfg.BatchSize()
 	qb.AddPlugin(NewLoggerPlugin(ctx, logger))
+	_clients = append(_clients, qb)
+
+	// Log the credential in play, since several can be configured.
+	method, _ := qbclient.SelectAuthMethod(cfg.AuthMethod(), cfg.UserToken(), cfg.TemporaryToken())
+	if method == "" {
+		method = AuthNone
+	}
+	logger.Debug(cliutil.ContextWithLogTag(ctx, "auth", method), "auth method selected")
+
+	// Keep the last request and response in memory for HandleError.
+	if cfg.DebugOnError() {
+		if _debug == nil {
+			_debug = &DebugPlugin{}
+		}
+		qb.AddPlugin(_debug)
+	}
+
+	// Trace requests and responses to stderr.
+	if cfg.Trace() {
+		qb.AddPlugin(NewTracePlugin(ctx))
+	}
 
 	// Dump raw requests and responses to the dump directory.
 	if dumpDir := cfg.DumpDirectory(); dumpDir != "" {
-		qb.AddPlugin(NewDumpPlugin(ctx, logger, transid.String(), dumpDir))
+		if cfg.DumpUnredacted() {
+			logger.Notice(ctx, "credentials are written to the dump files unredacted")
+		}
+		qb.AddPlugin(NewDumpPlugin(ctx, logger, transid.String(), dumpDir, cfg.DumpUnredacted()))
 	}
 
 	return
 }
 
+// _clients are the clients created by NewClient, whose requests are counted
+// in the summary logged by Render.
+var _clients []*qbclient.Client
+
+// APICalls returns the number of API requests made by the clients created by
+// NewClient, including retries.
+func APICalls() int {
+	n := 0
+	for _, qb := range _clients {
+		n += qb.Requests()
+	}
+	return n
+}
+
+// RetryWait returns the cumulative time the clients created by NewClient
+// waited to retry requests.
+func RetryWait() time.Duration {
+	var d time.Duration
+	for _, qb := range _clients {
+		d += qb.RetryWait()
+	}
+	return d
+}
+
 // FieldMap is a map of field IDs to field definitions.
 type FieldMap map[int]*qbclient.ListFieldsOutputField
 
-var _fmap map[string]FieldMap
+var (
+	_fmap   map[string]FieldMap
+	_fmapMu sync.RWMutex
+)
 
 // CacheTableSchema caches schema information for a table.
 func CacheTableSchema(qb *qbclient.Client, tableID string) error {
@@ -61,26 +143,31 @@ func CacheTableSchema(qb *qbclient.Client, tableID string) error {
 		m[field.FieldID] = field
 	}
 
+	_fmapMu.Lock()
 	_fmap[tableID] = m
+	_fmapMu.Unlock()
 	return nil
 }
 
 // GetTableSchema returns schema information for a table. If the schema is not
 // in the in-memory cache, it retrieves the data and caches it.
 func GetTableSchema(qb *qbclient.Client, tableID string) (FieldMap, error) {
-	var err error
-	_, ok := _fmap[tableID]
-	if !ok {
-		err = CacheTableSchema(qb, tableID)
+	if m, err := GetCachedTableSchema(tableID); err == nil {
+		return m, nil
 	}
-	return _fmap[tableID], err
+
+	err := CacheTableSchema(qb, tableID)
+	m, _ := GetCachedTableSchema(tableID)
+	return m, err
 }
 
 // GetCachedTableSchema returns schema information for a table.
 //
 // TODO Caching beyond in-memory caching?
 func GetCachedTableSchema(tableID string) (FieldMap, error) {
+	_fmapMu.RLock()
 	m, ok := _fmap[tableID]
+	_fmapMu.RUnlock()
 	if !ok {
 		err := errors.New("field metadata not set")
 		return FieldMap{}, fmt.Errorf("table %s: %w", tableID, err)
@@ -88,6 +175,24 @@ func GetCachedTableSchema(tableID string) (FieldMap, error) {
 	return m, nil
 }
 
+// LookupFieldIDs returns the sorted IDs of the lookup fields in a table.
+func LookupFieldIDs(qb *qbclient.Client, tableID string) ([]int, error) {
+	fields, err := GetTableSchema(qb, tableID)
+	if err != nil {
+		return nil, err
+	}
+
+	fids := []int{}
+	for fid, field := range fields {
+		if field.Mode == "lookup" {
+			fids = append(fids, fid)
+		}
+	}
+	sort.Ints(fids)
+
+	return fids, nil
+}
+
 func init() {
 	_fmap = make(map[string]FieldMap)
 }
@@ -0,0 +1,37 @@
+package qbcli
+
+import (
+	"io"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+)
+
+// _noColor is set through --no-color and disables colored output.
+var _noColor bool
+
+// colorEnabled returns whether output written to w is colored, which is when
+// w is stdout or stderr, it is a terminal, and colors weren't disabled through
+// --no-color or the NO_COLOR environment variable. Everything that writes
+// colored output consults it, so piped output and log files are never
+// colored.
+func colorEnabled(w io.Writer) bool {
+	return !_noColor && os.Getenv("NO_COLOR") == "" && isTerminalWriter(w)
+}
+
+// levelColors are the colors of the log levels written to a terminal.
+var levelColors = map[string]text.Colors{
+	"FATAL":  {text.Bold, text.FgHiRed},
+	"ERROR":  {text.FgHiRed},
+	"NOTICE": {text.FgHiYellow},
+	"DEBUG":  {text.FgHiBlack},
+}
+
+// colorLevel returns the log level colored as in levelColors if the log is
+// written to w and colorEnabled.
+func colorLevel(w io.Writer, level string) string {
+	if colors, ok := levelColors[level]; ok && colorEnabled(w) {
+		return colors.Sprint(level)
+	}
+	return level
+}
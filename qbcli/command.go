@@ -0,0 +1,139 @@
+package qbcli
+
+import (
+	"context"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// OutputWriter writes the output of a command, or its error. Render is the
+// default.
+type OutputWriter func(ctx context.Context, logger *cliutil.LeveledLogger, cmd *cobra.Command, cfg GlobalConfig, v interface{}, err error)
+
+// FlagReader is implemented by options that read flags after GetOptions,
+// e.g., to tell the flags passed on the command line from their defaults.
+type FlagReader interface {
+	ReadFlags(cmd *cobra.Command)
+}
+
+// PrepareFunc prepares a command before its options are read, e.g., to set
+// defaults derived from other options or to cache the schema that the options
+// are parsed with. Errors are handled by the function.
+type PrepareFunc func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg *viper.Viper)
+
+// RunFunc runs a command with the options returned by Command.Options after
+// they are read and validated.
+type RunFunc func(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts interface{}) (interface{}, error)
+
+// Command builds a command that follows the lifecycle shared by the commands:
+// the options are registered as flags, positional arguments and defaults are
+// set, the command is prepared, the options are read and validated with
+// GetOptions, and the output is written with the OutputWriter.
+type Command struct {
+
+	// Cmd is the command, whose Args and Run are set by Add. Args that are
+	// already set validate the positional arguments before the options are
+	// set from them.
+	Cmd *cobra.Command
+
+	// Options returns a pointer to a new options struct, and is nil if the
+	// command has no options.
+	Options func() interface{}
+
+	// Args are the options set from the positional arguments, in order.
+	Args []string
+
+	// DefaultAppID and DefaultTableID set the app-id and table-id options to
+	// the defaults in the profile.
+	DefaultAppID   bool
+	DefaultTableID bool
+
+	// NoClient only reads in the config file instead of validating the global
+	// options, and passes a nil client to Run. It is set by the commands that
+	// manage the config file, which must work before a realm is configured.
+	NoClient bool
+
+	// Prepare is optional and runs before the options are read.
+	Prepare PrepareFunc
+
+	// Run runs the command.
+	Run RunFunc
+
+	// Output writes the output, and defaults to Render.
+	Output OutputWriter
+}
+
+// Add adds the command to the parent and returns its configuration. The
+// global configuration is passed by reference because it is initialized after
+// the commands are added.
+func (c *Command) Add(parent *cobra.Command, globalCfg *GlobalConfig) *viper.Viper {
+	cfg, flags := cliutil.AddCommand(parent, c.Cmd, qbclient.EnvPrefix)
+	if c.Options != nil {
+		flags.SetOptions(c.Options())
+	}
+
+	output := c.Output
+	if output == nil {
+		output = Render
+	}
+
+	validateArgs := c.Cmd.Args
+	c.Cmd.Args = func(cmd *cobra.Command, args []string) (err error) {
+		if validateArgs != nil {
+			if err = validateArgs(cmd, args); err != nil {
+				return
+			}
+		}
+
+		if c.NoClient {
+			err = globalCfg.ReadInConfig()
+		} else {
+			err = globalCfg.Validate()
+		}
+
+		if err == nil {
+			if c.DefaultAppID {
+				globalCfg.SetDefaultAppID(cfg)
+			}
+			if c.DefaultTableID {
+				globalCfg.SetDefaultTableID(cfg)
+			}
+			for idx, option := range c.Args {
+				SetOptionFromArg(cfg, args, idx, option)
+			}
+		}
+		return
+	}
+
+	c.Cmd.Run = func(cmd *cobra.Command, args []string) {
+		var ctx context.Context
+		var logger *cliutil.LeveledLogger
+		var qb *qbclient.Client
+		if c.NoClient {
+			ctx, logger, _ = NewLogger(cmd, *globalCfg)
+		} else {
+			ctx, logger, qb = NewClient(cmd, *globalCfg)
+		}
+
+		if c.Prepare != nil {
+			c.Prepare(ctx, logger, qb, cfg)
+		}
+
+		var opts interface{}
+		if c.Options != nil {
+			opts = c.Options()
+			GetOptions(ctx, logger, opts, cfg)
+			if fr, ok := opts.(FlagReader); ok {
+				fr.ReadFlags(cmd)
+			}
+		}
+
+		v, err := c.Run(ctx, logger, qb, opts)
+		output(ctx, logger, cmd, *globalCfg, v, err)
+	}
+
+	return cfg
+}
@@ -0,0 +1,81 @@
+package qbcli
+
+import (
+	"sync"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// TableConcurrencyOptions are the options that control how app-wide commands
+// process tables in parallel.
+type TableConcurrencyOptions struct {
+	MaxConcurrentTables int  `validate:"min=1" cliutil:"option=max-concurrent-tables default=4 usage='maximum number of tables processed in parallel'"`
+	FailFast            bool `cliutil:"option=fail-fast usage='stop processing tables after the first error'"`
+}
+
+// TableError models an error processing a table.
+type TableError struct {
+	TableID string `json:"tableId"`
+	Error   string `json:"error"`
+}
+
+// forEachTable calls fn for each table, with at most MaxConcurrentTables
+// calls running in parallel. fn is passed the table's index so that results
+// can be aggregated in the order of the tables.
+//
+// Errors are collected and returned in the order of the tables, and the other
+// tables are still processed. With FailFast, tables that haven't started are
+// skipped after the first error, and the error is returned instead.
+func forEachTable(tables []*qbclient.ListTablesOutputTable, opts TableConcurrencyOptions, fn func(idx int, table *qbclient.ListTablesOutputTable) error) ([]*TableError, error) {
+	max := opts.MaxConcurrentTables
+	if max < 1 {
+		max = 1
+	}
+
+	errs := make([]error, len(tables))
+	sem := make(chan struct{}, max)
+	done := make(chan struct{})
+	var once sync.Once
+	var wg sync.WaitGroup
+
+loop:
+	for idx, table := range tables {
+		select {
+		case sem <- struct{}{}:
+		case <-done:
+			break loop
+		}
+
+		// Don't start another table if one already failed.
+		select {
+		case <-done:
+			<-sem
+			break loop
+		default:
+		}
+
+		wg.Add(1)
+		go func(idx int, table *qbclient.ListTablesOutputTable) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if errs[idx] = fn(idx, table); errs[idx] != nil && opts.FailFast {
+				once.Do(func() { close(done) })
+			}
+		}(idx, table)
+	}
+	wg.Wait()
+
+	terrs := []*TableError{}
+	for idx, err := range errs {
+		if err == nil {
+			continue
+		}
+		if opts.FailFast {
+			return terrs, err
+		}
+		terrs = append(terrs, &TableError{TableID: tables[idx].TableID, Error: err.Error()})
+	}
+
+	return terrs, nil
+}
@@ -3,6 +3,10 @@ package qbcli
 import (
 	"errors"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/QuickBase/quickbase-cli/qbclient"
 	"github.com/cpliakas/cliutil"
@@ -12,12 +16,53 @@ import (
 
 // Option* constants contain CLI options.
 const (
-	OptionDumpDirectory  = "dump-dir"
-	OptionFormat         = "format"
-	OptionJMESPathFilter = "filter"
-	OptionLogFile        = "log-file"
-	OptionLogLevel       = "log-level"
-	OptionQuiet          = "quiet"
+	OptionAppend          = "append"
+	OptionAssert          = "assert"
+	OptionBatchDelay      = "batch-delay"
+	OptionCompact         = "compact"
+	OptionCompressRequest = "compress-request"
+	OptionDebugOnError    = "debug-on-error"
+	OptionDecodeUsers     = "decode-users"
+	OptionDryRun          = "dry-run"
+	OptionDumpDirectory   = "dump-dir"
+	OptionDumpUnredacted  = "dump-unredacted"
+	OptionForce           = "force"
+	OptionLineEndings     = "line-endings"
+	OptionListSeparator   = "list-separator"
+	OptionLocale          = "locale"
+	OptionLogFile         = "log-file"
+	OptionLogFormat       = "log-format"
+	OptionLogLevel        = "log-level"
+	OptionMaxAPICalls     = "max-api-calls"
+	OptionMaxColWidth     = "max-col-width"
+	OptionMaxRetries      = "max-retries"
+	OptionNoColor         = "no-color"
+	OptionNoFormatNumbers = "no-format-numbers"
+	OptionNoValidate      = "no-validate"
+	OptionOutputFile      = "output"
+	OptionQuiet           = "quiet"
+	OptionRateLimit       = "rate-limit"
+	OptionRetryBudget     = "retry-budget"
+	OptionRetryMaxWait    = "retry-max-wait"
+	OptionSQLTable        = "sql-table"
+	OptionTemplate        = "template"
+	OptionTemplateFile    = "template-file"
+	OptionTimeout         = "timeout"
+	OptionTrace           = "trace"
+	OptionUnwrapValues    = "unwrap-values"
+	OptionWrap            = "wrap"
+)
+
+// DefaultBatchSize is the default number of records in each page of
+// paginated queries, DefaultBulkBatchSize is the default of the commands that
+// read and write records in bulk, DefaultInsertBatchSize is the default of the
+// commands that insert records from a file or generate them, and MaxBatchSize
+// is the most allowed.
+const (
+	DefaultBatchSize       = 1000
+	DefaultBulkBatchSize   = 10000
+	DefaultInsertBatchSize = 500
+	MaxBatchSize           = 50000
 )
 
 // Option*Description constants contain common option descriptions.
@@ -33,27 +78,114 @@ const (
 func NewGlobalConfig(cmd *cobra.Command, cfg *viper.Viper) GlobalConfig {
 	flags := cliutil.NewFlagger(cmd, cfg)
 
+	flags.PersistentBool(OptionAppend, "", false, "append to the file passed through --output instead of truncating it")
+	flags.PersistentString(OptionAssert, "", "", "JMESPath expression evaluated against the output, exits non-zero unless true")
+	flags.PersistentString(qbclient.OptionAuthMethod, "", "", "credential requests are authenticated with, either user or temporary, defaults to the user token if configured")
+	flags.PersistentString(OptionBatchDelay, "", "", "minimum pause between the batches of bulk commands, e.g., 500ms, overriding shorter --delay values")
+	flags.PersistentInt(qbclient.OptionBatchSize, "", 0, fmt.Sprintf("number of records in each API call of paginated queries and bulk commands, at most %v, 0 for the command's default", MaxBatchSize))
+	flags.PersistentBool(OptionCompact, "", false, "write JSON output on a single line, defaults to indented output when stdout is a terminal and single-line output otherwise")
+	flags.PersistentBool(OptionCompressRequest, "", false, "gzip-compress large request bodies, falling back to uncompressed bodies if the API rejects them")
+	flags.PersistentString(qbclient.OptionConfigFile, "", "", "configuration file read instead of the one in the configuration directory, which must exist")
+	flags.PersistentInt(qbclient.OptionConfirmCount, "", 1000, "require interactive confirmation or --force for mutations affecting more records than this, 0 to disable")
+	flags.PersistentBool(OptionDebugOnError, "", false, "write the last request and response to stderr when the command fails, with the credentials redacted")
+	flags.PersistentBool(OptionDecodeUsers, "", false, "fill in the email and name of users returned as IDs, and render users as emails in table and csv output")
+	flags.PersistentBool(OptionDryRun, "", false, "write requests that change data to stderr instead of sending them, read-only requests are still sent")
 	flags.PersistentString(OptionDumpDirectory, "d", "", "directory for files that request/response are dumped to for debugging")
-	flags.PersistentString(OptionFormat, "", "", "display data in an alternate format, e.g., table")
-	flags.PersistentString(OptionJMESPathFilter, "F", "", "JMESPath filter applied to output")
+	flags.PersistentBool(OptionDumpUnredacted, "", false, "write the credentials to the files in --dump-dir instead of redacting them")
+	flags.PersistentBool(OptionForce, "", false, "skip the confirmation required by --confirm-count-threshold and records query --estimate")
+	flags.PersistentString(qbclient.OptionFormat, "", "", "display data in an alternate format, e.g., table, csv, markdown, ndjson, xlsx, yaml, sql, or template")
+	filters := cmd.PersistentFlags().StringArrayP(qbclient.OptionJMESPathFilter, "F", nil, "JMESPath filter applied to output, repeat to apply each filter to the result of the previous one")
+	flags.PersistentString(OptionLineEndings, "", LineEndingLF, "line terminator of csv and ndjson output and of the files written by table export and import, either lf or crlf")
+	flags.PersistentString(OptionListSeparator, "", "; ", "separator that multiple-choice values are joined with in xlsx output and decoded user lists")
+	flags.PersistentString(OptionLocale, "", "", "BCP 47 language tag, e.g., de-DE, that numbers and dates in table, csv, and xlsx output are formatted for")
 	flags.PersistentString(OptionLogFile, "f", "", "file log messages are written to")
+	flags.PersistentString(OptionLogFormat, "", LogFormatText, "format of the messages written to --log-file, either text or json with one object per line")
 	flags.PersistentString(OptionLogLevel, "l", cliutil.LogNotice, "minimum log level")
+	flags.PersistentInt(OptionMaxAPICalls, "", 0, "abort the command once this many API requests are made, including retries, 0 for unlimited")
+	flags.PersistentInt(OptionMaxColWidth, "", 0, "truncate table cells longer than this number of characters, 0 to disable")
+	flags.PersistentInt(OptionMaxRetries, "", qbclient.DefaultMaxRetries, "maximum number of times a request is retried after a rate limit, server, or connection error, 0 to disable")
+	flags.PersistentBool(OptionNoColor, "", false, "disable colored output, which is also disabled when the output isn't a terminal or NO_COLOR is set")
+	flags.PersistentBool(OptionNoFormatNumbers, "", false, "render currency, percent, and duration values as raw numbers in table and csv output")
+	flags.PersistentBool(OptionNoValidate, "", false, "skip the validation of command options and send the request as-is, letting the API reject invalid input")
+	flags.PersistentString(qbclient.OptionOutputFields, "", FieldsOrderResponse, "column order of table and csv output, either response or schema")
+	flags.PersistentString(OptionOutputFile, "o", "", "file the output is written to instead of stdout, required for xlsx")
 	flags.PersistentString(qbclient.OptionProfile, "p", "default", "configuration profile")
 	flags.PersistentBool(OptionQuiet, "q", false, OptionQuietDescription)
+	flags.PersistentFloat64(OptionRateLimit, "", 0, "maximum number of API requests per second, including retries, 0 for unlimited")
 	flags.PersistentString(qbclient.OptionRealmHostname, "r", "", "realm hostname, e.g., example.quickbase.com")
-	flags.PersistentString(qbclient.OptionUserToken, "u", "", "user token used to authenticate API requests")
+	flags.PersistentString(OptionRetryBudget, "", "", "cap on the cumulative time spent waiting to retry failed requests, e.g., 2m, 0 for unlimited")
+	flags.PersistentString(OptionRetryMaxWait, "", qbclient.DefaultRetryMaxWait.String(), "maximum wait between retries, unless the API asks for longer through Retry-After")
+	flags.PersistentString(OptionSQLTable, "", "", "table that INSERT statements are written for, required for sql")
+	flags.PersistentBool(qbclient.OptionStrictFIDs, "", false, "fail when a field label matches more than one field instead of using the lowest field ID")
+	flags.PersistentString(qbclient.OptionTemporaryToken, "", "", "temporary token used to authenticate API requests if no user token is configured")
+	flags.PersistentString(OptionTemplate, "", "", "Go template the output is rendered with, implies --format template")
+	flags.PersistentString(OptionTemplateFile, "", "", "file containing the Go template the output is rendered with, implies --format template")
+	flags.PersistentString(OptionTimeout, "", qbclient.DefaultTimeout.String(), "maximum time to wait for each attempt of an API request, 0 to disable")
+	flags.PersistentString(qbclient.OptionTokenHelper, "", "", "command that writes the user token to stdout, run when no token is configured")
+	flags.PersistentBool(OptionTrace, "", false, "log each API request and response, including their bodies, to stderr with credentials redacted")
+	flags.PersistentBool(OptionUnwrapValues, "", false, "replace {\"value\": x} objects in JSON output with x")
+	flags.PersistentString(qbclient.OptionUserToken, "u", "", "user token used to authenticate API requests, - to read it from stdin")
+	flags.PersistentString(qbclient.OptionUserTokenFile, "", "", "file the user token used to authenticate API requests is read from")
+	flags.PersistentBool(OptionWrap, "", false, "wrap table cells longer than --max-col-width instead of truncating them")
 
-	return GlobalConfig{cfg: cfg}
+	return GlobalConfig{cfg: cfg, filters: filters}
 }
 
 // GlobalConfig contains configuration common to all commands.
 type GlobalConfig struct {
 	cfg *viper.Viper
+
+	// filters holds the values of the repeatable --filter flag, which isn't
+	// bound to cfg since viper can't read string array flags.
+	filters *[]string
 }
 
+// Append returns whether to append to the output file instead of truncating it.
+func (c GlobalConfig) Append() bool { return c.cfg.GetBool(OptionAppend) }
+
+// Assert returns the JMESPath expression used as a post-condition.
+func (c GlobalConfig) Assert() string { return c.cfg.GetString(OptionAssert) }
+
+// AuthMethod returns the explicitly selected auth method.
+func (c GlobalConfig) AuthMethod() string { return c.cfg.GetString(qbclient.OptionAuthMethod) }
+
+// BatchDelay returns the minimum pause between the batches of bulk commands.
+func (c GlobalConfig) BatchDelay() time.Duration { return c.cfg.GetDuration(OptionBatchDelay) }
+
+// BatchSize returns the number of records in each API call of paginated
+// queries and bulk commands, or 0 if each command uses its own default.
+func (c GlobalConfig) BatchSize() int { return c.cfg.GetInt(qbclient.OptionBatchSize) }
+
+// CompactJSON returns whether JSON output written to w is written on a single
+// line. Unless --compact is set, it is when w isn't a terminal.
+func (c GlobalConfig) CompactJSON(w io.Writer) bool {
+	if c.cfg.IsSet(OptionCompact) {
+		return c.cfg.GetBool(OptionCompact)
+	}
+	return !isTerminalWriter(w)
+}
+
+// CompressRequest returns whether to gzip-compress large request bodies.
+func (c GlobalConfig) CompressRequest() bool { return c.cfg.GetBool(OptionCompressRequest) }
+
 // ConfigDir returns the configuration directory.
 func (c GlobalConfig) ConfigDir() string { return c.cfg.GetString(qbclient.OptionConfigDir) }
 
+// ConfigFile returns the path of the configuration file, which is the file
+// passed through --config, or else the file in the configuration directory.
+func (c GlobalConfig) ConfigFile() string { return qbclient.ConfigFilePath(c.cfg) }
+
+// ConfirmCountThreshold returns the number of records a mutation can affect
+// before it requires confirmation.
+func (c GlobalConfig) ConfirmCountThreshold() int { return c.cfg.GetInt(qbclient.OptionConfirmCount) }
+
+// DebugOnError returns whether to write the last request and response to
+// stderr when the command fails.
+func (c GlobalConfig) DebugOnError() bool { return c.cfg.GetBool(OptionDebugOnError) }
+
+// DecodeUsers returns whether users returned as IDs are decoded.
+func (c GlobalConfig) DecodeUsers() bool { return c.cfg.GetBool(OptionDecodeUsers) }
+
 // DefaultAppID returns the default app ID.
 func (c GlobalConfig) DefaultAppID() string { return c.cfg.GetString(qbclient.OptionAppID) }
 
@@ -63,33 +195,142 @@ func (c GlobalConfig) DefaultFieldID() int { return c.cfg.GetInt(qbclient.Option
 // DefaultTableID returns the default table ID.
 func (c GlobalConfig) DefaultTableID() string { return c.cfg.GetString(qbclient.OptionTableID) }
 
+// DryRun returns whether to write requests that change data to stderr
+// instead of sending them.
+func (c GlobalConfig) DryRun() bool { return c.cfg.GetBool(OptionDryRun) }
+
 // DumpDirectory returns the configured dump file directory.
 func (c GlobalConfig) DumpDirectory() string { return c.cfg.GetString(OptionDumpDirectory) }
 
-// Format returns the configured output format, e.g., table. No config == JSON.
-func (c GlobalConfig) Format() string { return c.cfg.GetString(OptionFormat) }
+// DumpUnredacted returns whether to write credentials to the dump files.
+func (c GlobalConfig) DumpUnredacted() bool { return c.cfg.GetBool(OptionDumpUnredacted) }
+
+// Force returns whether to skip the confirmation for large mutations.
+func (c GlobalConfig) Force() bool { return c.cfg.GetBool(OptionForce) }
 
-// JMESPathFilter returns the JMESPath filter.
-func (c GlobalConfig) JMESPathFilter() string { return c.cfg.GetString(OptionJMESPathFilter) }
+// Format returns the configured output format, i.e., table, csv, markdown,
+// xlsx, or yaml. No config == JSON.
+func (c GlobalConfig) Format() string { return c.cfg.GetString(qbclient.OptionFormat) }
+
+// JMESPathFilters returns the JMESPath filters in the order they are applied.
+// Filters passed on the command line take precedence over the single filter
+// set through the environment or the profile.
+func (c GlobalConfig) JMESPathFilters() []string {
+	if c.filters != nil && len(*c.filters) > 0 {
+		return *c.filters
+	}
+	if filter := c.cfg.GetString(qbclient.OptionJMESPathFilter); filter != "" {
+		return []string{filter}
+	}
+	return nil
+}
+
+// LineEndings returns the line terminator of csv and ndjson output and of the
+// files written by bulk commands, either lf or crlf.
+func (c GlobalConfig) LineEndings() string { return c.cfg.GetString(OptionLineEndings) }
+
+// ListSeparator returns the separator that multiple-choice values are joined
+// with.
+func (c GlobalConfig) ListSeparator() string { return c.cfg.GetString(OptionListSeparator) }
+
+// Locale returns the language tag that output is formatted for.
+func (c GlobalConfig) Locale() string { return c.cfg.GetString(OptionLocale) }
 
 // LogFile returns the configured log file.
 func (c GlobalConfig) LogFile() string { return c.cfg.GetString(OptionLogFile) }
 
+// LogFormat returns the format of the messages written to the log file.
+func (c GlobalConfig) LogFormat() string { return c.cfg.GetString(OptionLogFormat) }
+
 // LogLevel returns the configured log level.
 func (c GlobalConfig) LogLevel() string { return c.cfg.GetString(OptionLogLevel) }
 
+// MaxAPICalls returns the maximum number of API requests a command can make.
+func (c GlobalConfig) MaxAPICalls() int { return c.cfg.GetInt(OptionMaxAPICalls) }
+
+// MaxColWidth returns the maximum width of table cells.
+func (c GlobalConfig) MaxColWidth() int { return c.cfg.GetInt(OptionMaxColWidth) }
+
+// MaxRetries returns the maximum number of times a failed request is retried.
+func (c GlobalConfig) MaxRetries() int { return c.cfg.GetInt(OptionMaxRetries) }
+
+// NoColor returns whether to disable colored output.
+func (c GlobalConfig) NoColor() bool { return c.cfg.GetBool(OptionNoColor) }
+
+// NoFormatNumbers returns whether to render numeric subtypes as raw numbers.
+func (c GlobalConfig) NoFormatNumbers() bool { return c.cfg.GetBool(OptionNoFormatNumbers) }
+
+// NoValidate returns whether to skip the validation of command options.
+func (c GlobalConfig) NoValidate() bool { return c.cfg.GetBool(OptionNoValidate) }
+
+// OutputFieldsOrder returns the column order of table output.
+func (c GlobalConfig) OutputFieldsOrder() string { return c.cfg.GetString(qbclient.OptionOutputFields) }
+
+// OutputFile returns the file the output is written to.
+func (c GlobalConfig) OutputFile() string { return c.cfg.GetString(OptionOutputFile) }
+
 // Profile returns the configured profile.
 func (c GlobalConfig) Profile() string { return c.cfg.GetString(qbclient.OptionProfile) }
 
 // Quiet returns whehter to suppress output written to stdout.
 func (c GlobalConfig) Quiet() bool { return c.cfg.GetBool(OptionQuiet) }
 
-// RealmHostname returns the configured realm hostname.
+// RateLimit returns the maximum number of API requests per second.
+func (c GlobalConfig) RateLimit() float64 { return c.cfg.GetFloat64(OptionRateLimit) }
+
+// RealmHostname returns the configured realm hostname. The --realm-hostname
+// flag takes precedence over the QUICKBASE_REALM_HOSTNAME and
+// QB_REALM_HOSTNAME environment variables, in that order, which take
+// precedence over the project file and the profile.
 func (c GlobalConfig) RealmHostname() string { return c.cfg.GetString(qbclient.OptionRealmHostname) }
 
-// UserToken returns the configured log level.
+// RetryBudget returns the maximum cumulative time spent waiting to retry
+// failed requests.
+func (c GlobalConfig) RetryBudget() time.Duration { return c.cfg.GetDuration(OptionRetryBudget) }
+
+// RetryMaxWait returns the maximum wait between retries.
+func (c GlobalConfig) RetryMaxWait() time.Duration { return c.cfg.GetDuration(OptionRetryMaxWait) }
+
+// SQLTable returns the table that INSERT statements are written for.
+func (c GlobalConfig) SQLTable() string { return c.cfg.GetString(OptionSQLTable) }
+
+// StrictFIDs returns whether labels that match more than one field are an
+// error.
+func (c GlobalConfig) StrictFIDs() bool { return c.cfg.GetBool(qbclient.OptionStrictFIDs) }
+
+// TemporaryToken returns the configured temporary token. The --temp-token
+// flag takes precedence over the QUICKBASE_TEMP_TOKEN and QB_TEMP_TOKEN
+// environment variables, in that order, which take precedence over the
+// profile.
+func (c GlobalConfig) TemporaryToken() string { return c.cfg.GetString(qbclient.OptionTemporaryToken) }
+
+// Template returns the Go template the output is rendered with.
+func (c GlobalConfig) Template() string { return c.cfg.GetString(OptionTemplate) }
+
+// TemplateFile returns the file containing the Go template the output is
+// rendered with.
+func (c GlobalConfig) TemplateFile() string { return c.cfg.GetString(OptionTemplateFile) }
+
+// Timeout returns the maximum duration of each attempt of an API request.
+func (c GlobalConfig) Timeout() time.Duration { return c.cfg.GetDuration(OptionTimeout) }
+
+// Trace returns whether to log each API request and response to stderr.
+func (c GlobalConfig) Trace() bool { return c.cfg.GetBool(OptionTrace) }
+
+// UnwrapValues returns whether to replace value objects in JSON output with
+// their values.
+func (c GlobalConfig) UnwrapValues() bool { return c.cfg.GetBool(OptionUnwrapValues) }
+
+// UserToken returns the configured user token. The --user-token flag takes
+// precedence over the QUICKBASE_USER_TOKEN and QB_USER_TOKEN environment
+// variables, in that order, which take precedence over the profile and the
+// token helper. A token read from --user-token-file, or from stdin if the
+// token is "-", replaces the value once the configuration is read in.
 func (c GlobalConfig) UserToken() string { return c.cfg.GetString(qbclient.OptionUserToken) }
 
+// Wrap returns whether to wrap table cells instead of truncating them.
+func (c GlobalConfig) Wrap() bool { return c.cfg.GetBool(OptionWrap) }
+
 // ReadInConfig reads in the config file.
 func (c *GlobalConfig) ReadInConfig() error { return qbclient.ReadInConfig(c.cfg) }
 
@@ -100,6 +341,74 @@ func (c *GlobalConfig) Validate() error {
 		return fmt.Errorf("value %q for option %q: %w", c.LogLevel(), OptionLogLevel, errors.New("invalid value"))
 	}
 
+	// Messages written to stderr are always text, so a JSON log needs a file.
+	switch f := c.LogFormat(); {
+	case f != LogFormatText && f != LogFormatJSON:
+		return fmt.Errorf("value %q for option %q: %w", f, OptionLogFormat, errors.New("invalid value"))
+	case f == LogFormatJSON && c.LogFile() == "":
+		return fmt.Errorf("option %q: %w", OptionLogFile, errors.New("value required for json log format"))
+	}
+
+	if e := c.LineEndings(); e != LineEndingLF && e != LineEndingCRLF {
+		return fmt.Errorf("value %q for option %q: %w", e, OptionLineEndings, errors.New("invalid value"))
+	}
+
+	if o := c.OutputFieldsOrder(); o != FieldsOrderResponse && o != FieldsOrderSchema {
+		return fmt.Errorf("value %q for option %q: %w", o, qbclient.OptionOutputFields, errors.New("invalid value"))
+	}
+
+	for _, option := range []string{OptionBatchDelay, OptionRetryBudget, OptionRetryMaxWait, OptionTimeout} {
+		if d := c.cfg.GetString(option); d != "" {
+			if _, err := time.ParseDuration(d); err != nil {
+				return fmt.Errorf("value %q for option %q: %w", d, option, errors.New("invalid duration"))
+			}
+		}
+	}
+
+	if n := c.BatchSize(); n < 0 || n > MaxBatchSize {
+		return fmt.Errorf("value %v for option %q: %w", n, qbclient.OptionBatchSize, fmt.Errorf("must be between 0 and %v", MaxBatchSize))
+	}
+
+	if r := c.RateLimit(); r < 0 {
+		return fmt.Errorf("value %v for option %q: %w", r, OptionRateLimit, errors.New("must not be negative"))
+	}
+
+	if l := c.Locale(); l != "" {
+		if _, err := ParseLocale(l); err != nil {
+			return err
+		}
+	}
+
+	// Binary output can't be written to a terminal.
+	if c.Format() == FormatXLSX && c.OutputFile() == "" {
+		return fmt.Errorf("option %q: %w", OptionOutputFile, errors.New("value required for xlsx format"))
+	}
+
+	// The table is written into the statements as-is, so it must be a plain
+	// identifier, optionally qualified by a schema.
+	if c.Format() == FormatSQL {
+		if t := c.SQLTable(); t == "" {
+			return fmt.Errorf("option %q: %w", OptionSQLTable, errors.New("value required for sql format"))
+		} else if !reSQLTable.MatchString(t) {
+			return fmt.Errorf("value %q for option %q: %w", t, OptionSQLTable, errors.New("invalid table name"))
+		}
+	}
+
+	// A template implies the template format, which requires one.
+	if c.Template() != "" && c.TemplateFile() != "" {
+		return fmt.Errorf("option %q: %w", OptionTemplate, fmt.Errorf("cannot be used with option %q", OptionTemplateFile))
+	}
+	if c.Template() != "" || c.TemplateFile() != "" {
+		if c.Format() == "" {
+			c.cfg.Set(qbclient.OptionFormat, FormatTemplate)
+		} else if c.Format() != FormatTemplate {
+			return fmt.Errorf("option %q: %w", OptionTemplate, fmt.Errorf("cannot be used with %s format", c.Format()))
+		}
+	}
+	if c.Format() == FormatTemplate && c.Template() == "" && c.TemplateFile() == "" {
+		return fmt.Errorf("option %q: %w", OptionTemplate, errors.New("value required for template format"))
+	}
+
 	if err := c.ReadInConfig(); err != nil {
 		return err
 	}
@@ -108,6 +417,27 @@ func (c *GlobalConfig) Validate() error {
 		return fmt.Errorf("option %q: %w", qbclient.OptionRealmHostname, errors.New("value required"))
 	}
 
+	// Forgive a URL pasted from the browser, but reject anything else that
+	// isn't a bare hostname, which would fail with a confusing API error.
+	if h := c.RealmHostname(); strings.HasPrefix(strings.ToLower(h), "https://") {
+		c.cfg.Set(qbclient.OptionRealmHostname, strings.TrimSuffix(h[len("https://"):], "/"))
+	}
+	if h := c.RealmHostname(); qbclient.ValidateHostname(h) != nil {
+		if aliases, _ := qbclient.ReadRealmAliasesAt(c.ConfigFile()); len(aliases) > 0 {
+			names := make([]string, 0, len(aliases))
+			for alias := range aliases {
+				names = append(names, alias)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("value %q for option %q: %w", h, qbclient.OptionRealmHostname, fmt.Errorf("invalid hostname or unknown realm alias, known aliases: %s", strings.Join(names, ", ")))
+		}
+		return fmt.Errorf("value %q for option %q: %w", h, qbclient.OptionRealmHostname, errors.New("invalid hostname, expecting format example.quickbase.com"))
+	}
+
+	if _, err := qbclient.SelectAuthMethod(c.AuthMethod(), c.UserToken(), c.TemporaryToken()); err != nil {
+		return err
+	}
+
 	return nil
 }
 
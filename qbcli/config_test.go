@@ -0,0 +1,42 @@
+package qbcli_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// TestTimeoutOptions tests that the global --timeout isn't shadowed by the
+// --stdin-timeout option of table import.
+func TestTimeoutOptions(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "quickbase-cli"}
+	globalCfg := qbcli.NewGlobalConfig(rootCmd, viper.New())
+
+	importCmd := &cobra.Command{Use: "import", Run: func(cmd *cobra.Command, args []string) {}}
+	importCfg, flags := cliutil.AddCommand(rootCmd, importCmd, qbclient.EnvPrefix)
+	if err := flags.SetOptions(&qbcli.ImportOptions{}); err != nil {
+		t.Fatalf("got %q, expected nil", err)
+	}
+
+	rootCmd.SetArgs([]string{"import", "--timeout", "10s", "--stdin-timeout", "3"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("got %q, expected nil", err)
+	}
+
+	if got := globalCfg.Timeout(); got != 10*time.Second {
+		t.Errorf("got timeout %v, expected 10s", got)
+	}
+
+	opts := &qbcli.ImportOptions{}
+	if err := cliutil.ReadOptions(opts, importCfg); err != nil {
+		t.Fatalf("got %q, expected nil", err)
+	}
+	if opts.StdinTimeout != 3 {
+		t.Errorf("got stdin timeout %v, expected 3", opts.StdinTimeout)
+	}
+}
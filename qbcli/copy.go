@@ -0,0 +1,203 @@
+package qbcli
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"gopkg.in/yaml.v3"
+)
+
+// CopyOptions are the options read through the command line.
+type CopyOptions struct {
+	Source     string `validate:"required" cliutil:"option=source usage='unique identifier (dbid) of the source table (required)'"`
+	Dest       string `validate:"required" cliutil:"option=dest usage='unique identifier (dbid) of the destination table (required)'"`
+	MapFile    string `validate:"required" cliutil:"option=map-file usage='YAML file that maps source field IDs to destination field IDs (required)'"`
+	Where      string `cliutil:"option=where usage='query that filters the source records, all records if empty'"`
+	MergeField int    `cliutil:"option=merge-field usage='unique field in the destination table that records are matched on, records are created if empty'"`
+	Delay      int    `cliutil:"option=delay"`
+
+	ErrorModeOptions
+	SchemaCheckOptions
+}
+
+// CopyOutput is the result of copying records between tables.
+type CopyOutput struct {
+	Read       int                 `json:"read"`
+	Created    int                 `json:"created"`
+	Updated    int                 `json:"updated"`
+	Unchanged  int                 `json:"unchanged"`
+	Dropped    []int               `json:"droppedFields"`
+	LineErrors map[string][]string `json:"lineErrors,omitempty"`
+
+	BatchErrors []*BatchError `json:"batchErrors,omitempty"`
+}
+
+// Failures implements FailureCounter.Failures.
+func (o *CopyOutput) Failures() int { return len(o.LineErrors) + len(o.BatchErrors) }
+
+// ReadCopyMap reads and parses a file that maps source field IDs to
+// destination field IDs, e.g., "6: 8".
+func ReadCopyMap(path string) (map[int]int, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading field map: %w", err)
+	}
+
+	m := map[int]int{}
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("error parsing field map: %w", err)
+	}
+	if len(m) == 0 {
+		return nil, fmt.Errorf("field map %s is empty", path)
+	}
+
+	return m, nil
+}
+
+// Copy reads the matching records from a source table and upserts them into a
+// destination table, translating the field IDs through the map file. Source
+// fields that aren't mapped are dropped. The matching records are counted
+// first and require confirmation above the confirm-count-threshold.
+func Copy(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *CopyOptions) (*CopyOutput, error) {
+	output := &CopyOutput{Dropped: []int{}, LineErrors: map[string][]string{}, BatchErrors: []*BatchError{}}
+
+	if err := opts.validate(); err != nil {
+		return output, err
+	}
+
+	fmap, err := ReadCopyMap(opts.MapFile)
+	if err != nil {
+		return output, err
+	}
+
+	sfields, err := GetTableSchema(qb, opts.Source)
+	if err != nil {
+		return output, fmt.Errorf("error getting source table metadata: %w", err)
+	}
+	dfields, err := GetTableSchema(qb, opts.Dest)
+	if err != nil {
+		return output, fmt.Errorf("error getting destination table metadata: %w", err)
+	}
+
+	// Validate the mapping, sorted by source field ID.
+	fields := []*syncField{}
+	for sfid, dfid := range fmap {
+		if _, ok := sfields[sfid]; !ok {
+			return output, fmt.Errorf("field %v not in source table %s", sfid, opts.Source)
+		}
+		df, ok := dfields[dfid]
+		if !ok {
+			return output, fmt.Errorf("field %v not in destination table %s", dfid, opts.Dest)
+		}
+		fields = append(fields, &syncField{source: sfid, dest: dfid, ftype: df.Type})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].source < fields[j].source })
+
+	if opts.MergeField > 0 {
+		if _, ok := dfields[opts.MergeField]; !ok {
+			return output, fmt.Errorf("merge field %v not in destination table %s", opts.MergeField, opts.Dest)
+		}
+	}
+
+	// Warn about the source fields that are dropped, skipping the built-in
+	// fields, which usually aren't copied.
+	for _, f := range sortedFields(sfields) {
+		if _, ok := fmap[f.FieldID]; !ok && f.FieldID > 5 {
+			output.Dropped = append(output.Dropped, f.FieldID)
+		}
+	}
+	if len(output.Dropped) > 0 {
+		dropped := make([]string, len(output.Dropped))
+		for idx, fid := range output.Dropped {
+			dropped[idx] = strconv.Itoa(fid)
+		}
+		logger.Notice(cliutil.ContextWithLogTag(ctx, "fields", strings.Join(dropped, ",")), "unmapped source fields dropped")
+	}
+
+	matched, err := countRecords(qb, opts.Source, opts.Where)
+	if err != nil {
+		return output, err
+	}
+
+	label := fmt.Sprintf("Copy %v records to table %s?", matched, opts.Dest)
+	ok, err := ConfirmCount(cfg, label, matched, true)
+	if err != nil {
+		return output, err
+	}
+	if !ok {
+		logger.Notice(ctx, "no records copied")
+		return output, nil
+	}
+
+	sselect := make([]int, len(fields))
+	for idx, f := range fields {
+		sselect[idx] = f.source
+	}
+	input := &qbclient.QueryRecordsInput{Select: sselect, From: opts.Source, Where: opts.Where}
+
+	batch := 0
+	err = QueryRecordsPaged(qb, input, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
+		records := make([]map[int]*qbclient.InsertRecordsInputData, len(qro.Data))
+		for ridx, record := range qro.Data {
+			data := make(map[int]*qbclient.InsertRecordsInputData, len(fields))
+			for _, f := range fields {
+				val, err := qbclient.NewValueFromString(recordString(record, f.source), f.ftype)
+				if err != nil {
+					return fmt.Errorf("value invalid for field %v: %w", f.dest, err)
+				}
+				data[f.dest] = &qbclient.InsertRecordsInputData{Value: val}
+			}
+			records[ridx] = data
+		}
+		output.Read += len(records)
+
+		batch++
+		if err := opts.batchError(&output.BatchErrors, batch, copyUpsert(qb, opts, records, output.Read-len(records), output)); err != nil {
+			return err
+		}
+		return opts.lineErrors(output.LineErrors)
+	})
+	if err != nil {
+		return output, err
+	}
+
+	logger.Notice(cliutil.ContextWithLogTag(ctx, "read", strconv.Itoa(output.Read)), "records copied")
+
+	return output, opts.checkSchema(ctx, logger, qb, opts.Source, opts.Dest)
+}
+
+// copyUpsert upserts a page of records into the destination table and adds the
+// results to output. Line errors are keyed by the position of the record in
+// the source query, starting at 1.
+func copyUpsert(qb *qbclient.Client, opts *CopyOptions, records []map[int]*qbclient.InsertRecordsInputData, offset int, output *CopyOutput) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	iro, err := qb.InsertRecords(&qbclient.InsertRecordsInput{
+		To:           opts.Dest,
+		Data:         records,
+		MergeFieldID: opts.MergeField,
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting records: %w", err)
+	}
+
+	output.Created += len(iro.Metadata.CreatedRecordIDs)
+	output.Updated += len(iro.Metadata.UpdatedRecordIDs)
+	output.Unchanged += len(iro.Metadata.UnchangedRecordIDs)
+	for k, v := range iro.Metadata.LineErrors {
+		if pos, err := strconv.Atoi(k); err == nil {
+			k = strconv.Itoa(offset + pos)
+		}
+		output.LineErrors[k] = v
+	}
+
+	return nil
+}
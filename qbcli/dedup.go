@@ -0,0 +1,117 @@
+package qbcli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// Keep* constants contain the rules for choosing which duplicate to keep.
+const (
+	KeepFirst  = "first"
+	KeepNewest = "newest"
+	KeepOldest = "oldest"
+)
+
+// DedupOptions are the options read through the command line.
+type DedupOptions struct {
+	TableID string `validate:"required" cliutil:"option=table-id"`
+	On      []int  `validate:"required,min=1" cliutil:"option=on usage='field IDs that identify duplicate records'"`
+	Keep    string `validate:"oneof=first newest oldest" cliutil:"option=keep default=first usage='record that is kept, either first (lowest record ID), newest, or oldest by Date Created'"`
+	Delay   int    `cliutil:"option=delay"`
+	Yes     bool   `cliutil:"option=yes usage='delete the duplicates without prompting for confirmation'"`
+
+	ErrorModeOptions
+}
+
+// DedupOutput is the result of removing duplicate records.
+type DedupOutput struct {
+	TotalRecords  int   `json:"totalRecords"`
+	Duplicates    int   `json:"duplicates"`
+	NumberDeleted int   `json:"numberDeleted"`
+	RecordIDs     []int `json:"recordIds"`
+
+	BatchErrors []*BatchError `json:"batchErrors,omitempty"`
+}
+
+// Failures implements FailureCounter.Failures.
+func (o *DedupOutput) Failures() int { return len(o.BatchErrors) }
+
+// Dedup finds records in a table that have the same values for the configured
+// fields, keeps one record in each set of duplicates, and deletes the rest.
+func Dedup(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *DedupOptions) (*DedupOutput, error) {
+	output := &DedupOutput{RecordIDs: []int{}}
+
+	if err := opts.validate(); err != nil {
+		return output, err
+	}
+
+	// Records are sorted so that the record to keep is the first one seen.
+	sortBy := []*qbclient.QueryRecordsInputSortBy{}
+	switch opts.Keep {
+	case KeepNewest:
+		sortBy = append(sortBy, &qbclient.QueryRecordsInputSortBy{FieldID: 1, Order: qbclient.SortByDESC})
+	case KeepOldest:
+		sortBy = append(sortBy, &qbclient.QueryRecordsInputSortBy{FieldID: 1, Order: qbclient.SortByASC})
+	}
+	sortBy = append(sortBy, &qbclient.QueryRecordsInputSortBy{FieldID: 3, Order: qbclient.SortByASC})
+
+	input := &qbclient.QueryRecordsInput{
+		Select: append([]int{3}, opts.On...),
+		From:   opts.TableID,
+		SortBy: sortBy,
+	}
+
+	// Find the duplicates.
+	seen := map[string]bool{}
+	err := QueryRecordsPaged(qb, input, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
+		output.TotalRecords = qro.Metadata.TotalRecords
+		for _, record := range qro.Data {
+			vals := make([]string, len(opts.On))
+			for idx, fid := range opts.On {
+				vals[idx] = recordString(record, fid)
+			}
+
+			key := strings.Join(vals, "\x1f")
+			if seen[key] {
+				output.RecordIDs = append(output.RecordIDs, int(record[3].Value.Float64))
+			} else {
+				seen[key] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	output.Duplicates = len(output.RecordIDs)
+	if output.Duplicates == 0 {
+		return output, nil
+	}
+
+	// Confirm the deletion.
+	label := fmt.Sprintf("Delete %v duplicate records from table %s?", output.Duplicates, opts.TableID)
+	ok, err := ConfirmCount(cfg, label, output.Duplicates, opts.Yes)
+	if err != nil {
+		return output, err
+	}
+	if !ok {
+		logger.Notice(ctx, "no records deleted")
+		return output, nil
+	}
+
+	// Delete the duplicates in batches.
+	output.NumberDeleted, output.BatchErrors, err = DeleteRecordIDs(qb, opts.TableID, output.RecordIDs, opts.Delay, opts.ErrorModeOptions)
+	if err != nil {
+		return output, err
+	}
+
+	logger.Notice(cliutil.ContextWithLogTag(ctx, "deleted", strconv.Itoa(output.NumberDeleted)), "duplicate records deleted")
+
+	return output, nil
+}
@@ -0,0 +1,213 @@
+package qbcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// Change* constants contain the types of changes between two records.
+const (
+	ChangeAdded    = "added"
+	ChangeModified = "modified"
+	ChangeRemoved  = "removed"
+)
+
+// RecordsDiffOptions are the options read through the command line.
+type RecordsDiffOptions struct {
+	Old      string `validate:"required" cliutil:"option=old usage='JSON file of the old records, i.e., the output of records query (required)'"`
+	New      string `validate:"required" cliutil:"option=new usage='JSON file of the new records, i.e., the output of records query (required)'"`
+	KeyField int    `cliutil:"option=key-field default=3 usage='field that identifies the same record in both files'"`
+}
+
+// RecordsDiffOutput is the difference between two sets of records.
+type RecordsDiffOutput struct {
+	Summary  *RecordsDiffSummary  `json:"summary"`
+	Added    []string             `json:"added"`
+	Removed  []string             `json:"removed"`
+	Modified []*RecordsDiffRecord `json:"modified"`
+}
+
+// RecordsDiffSummary counts the changes between two sets of records.
+type RecordsDiffSummary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Modified  int `json:"modified"`
+	Unchanged int `json:"unchanged"`
+}
+
+// RecordsDiffRecord models a record that was modified.
+type RecordsDiffRecord struct {
+	Key    string              `json:"key"`
+	Fields []*RecordsDiffField `json:"fields"`
+}
+
+// RecordsDiffField models a field value that was modified.
+type RecordsDiffField struct {
+	FieldID int    `json:"fieldId"`
+	Label   string `json:"label"`
+	Old     string `json:"old"`
+	New     string `json:"new"`
+}
+
+// TableHeader implements Tabular.TableHeader.
+func (o *RecordsDiffOutput) TableHeader() []string {
+	return []string{"Change", "Key", "Field", "Old", "New"}
+}
+
+// TableRows implements Tabular.TableRows.
+func (o *RecordsDiffOutput) TableRows() [][]string {
+	rows := [][]string{}
+	for _, key := range o.Added {
+		rows = append(rows, []string{ChangeAdded, key, "", "", ""})
+	}
+	for _, key := range o.Removed {
+		rows = append(rows, []string{ChangeRemoved, key, "", "", ""})
+	}
+	for _, r := range o.Modified {
+		for _, f := range r.Fields {
+			rows = append(rows, []string{ChangeModified, r.Key, f.Label, f.Old, f.New})
+		}
+	}
+	return rows
+}
+
+// ReadRecordsFile reads a JSON file of records in the format output by the
+// records query command.
+func ReadRecordsFile(path string) (*qbclient.QueryRecordsOutput, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading records: %w", err)
+	}
+
+	output := &qbclient.QueryRecordsOutput{}
+	if err := json.Unmarshal(b, output); err != nil {
+		return nil, fmt.Errorf("error parsing records in %s: %w", path, err)
+	}
+
+	return output, nil
+}
+
+// RecordsDiff compares two sets of records, matching them on the key field,
+// and reports the records that were added, removed, and modified. Only the
+// fields in both files are compared.
+func RecordsDiff(opts *RecordsDiffOptions) (*RecordsDiffOutput, error) {
+	output := &RecordsDiffOutput{
+		Summary:  &RecordsDiffSummary{},
+		Added:    []string{},
+		Removed:  []string{},
+		Modified: []*RecordsDiffRecord{},
+	}
+
+	before, err := ReadRecordsFile(opts.Old)
+	if err != nil {
+		return output, err
+	}
+	after, err := ReadRecordsFile(opts.New)
+	if err != nil {
+		return output, err
+	}
+
+	// Compare the fields in both files, ordered by field ID.
+	labels := map[int]string{}
+	for _, f := range before.Fields {
+		labels[f.FieldID] = f.Label
+	}
+	fields := []*qbclient.RecordsField{}
+	for _, f := range after.Fields {
+		if _, ok := labels[f.FieldID]; ok {
+			fields = append(fields, f)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].FieldID < fields[j].FieldID })
+
+	if _, ok := labels[opts.KeyField]; !ok {
+		return output, fmt.Errorf("key field %v not in the old records", opts.KeyField)
+	}
+
+	oldByKey, err := recordsByKey(before, opts.KeyField, opts.Old)
+	if err != nil {
+		return output, err
+	}
+	newByKey, err := recordsByKey(after, opts.KeyField, opts.New)
+	if err != nil {
+		return output, err
+	}
+
+	for _, key := range sortedKeys(newByKey) {
+		orec, ok := oldByKey[key]
+		if !ok {
+			output.Added = append(output.Added, key)
+			continue
+		}
+
+		changed := []*RecordsDiffField{}
+		for _, f := range fields {
+			ov, nv := recordString(orec, f.FieldID), recordString(newByKey[key], f.FieldID)
+			if ov != nv {
+				changed = append(changed, &RecordsDiffField{FieldID: f.FieldID, Label: f.Label, Old: ov, New: nv})
+			}
+		}
+
+		if len(changed) == 0 {
+			output.Summary.Unchanged++
+		} else {
+			output.Modified = append(output.Modified, &RecordsDiffRecord{Key: key, Fields: changed})
+		}
+	}
+
+	for _, key := range sortedKeys(oldByKey) {
+		if _, ok := newByKey[key]; !ok {
+			output.Removed = append(output.Removed, key)
+		}
+	}
+
+	output.Summary.Added = len(output.Added)
+	output.Summary.Removed = len(output.Removed)
+	output.Summary.Modified = len(output.Modified)
+
+	return output, nil
+}
+
+// recordsByKey maps the records to the value of the key field.
+func recordsByKey(records *qbclient.QueryRecordsOutput, fid int, path string) (map[string]map[int]*qbclient.RecordsData, error) {
+	m := make(map[string]map[int]*qbclient.RecordsData, len(records.Data))
+	for idx, record := range records.Data {
+		key := recordString(record, fid)
+		if key == "" {
+			return nil, fmt.Errorf("record %v in %s: key field %v is empty", idx+1, path, fid)
+		}
+		if _, ok := m[key]; ok {
+			return nil, fmt.Errorf("record %v in %s: duplicate key %q", idx+1, path, key)
+		}
+		m[key] = record
+	}
+	return m, nil
+}
+
+// sortedKeys returns the keys sorted numerically if they are all numbers,
+// e.g., record IDs, and as strings otherwise.
+func sortedKeys(m map[string]map[int]*qbclient.RecordsData) []string {
+	keys := make([]string, 0, len(m))
+	numeric := true
+	for key := range m {
+		keys = append(keys, key)
+		if _, err := strconv.ParseFloat(key, 64); err != nil {
+			numeric = false
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if numeric {
+			a, _ := strconv.ParseFloat(keys[i], 64)
+			b, _ := strconv.ParseFloat(keys[j], 64)
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,129 @@
+package qbcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// distinctPageSize is the number of records read in each page by Distinct.
+// Only the distinct field is selected, so large pages are cheap.
+const distinctPageSize = 10000
+
+// DistinctOutput contains the unique values of a field across records.
+type DistinctOutput struct {
+	Label      string
+	WithCounts bool
+	Values     []*DistinctValue
+}
+
+// DistinctValue is a unique value and the number of records it is in.
+type DistinctValue struct {
+	Value *qbclient.Value `json:"value"`
+	Count int             `json:"count"`
+}
+
+// MarshalJSON implements json.MarshalJSON by marshaling the values as an
+// array, or as an array of value and count objects if WithCounts is set.
+func (o *DistinctOutput) MarshalJSON() ([]byte, error) {
+	if o.WithCounts {
+		return json.Marshal(o.Values)
+	}
+
+	values := make([]*qbclient.Value, len(o.Values))
+	for idx, v := range o.Values {
+		values[idx] = v.Value
+	}
+	return json.Marshal(values)
+}
+
+// TableHeader implements Tabular.TableHeader.
+func (o *DistinctOutput) TableHeader() []string {
+	if o.WithCounts {
+		return []string{o.Label, "Count"}
+	}
+	return []string{o.Label}
+}
+
+// TableRows implements Tabular.TableRows.
+func (o *DistinctOutput) TableRows() [][]string {
+	rows := make([][]string, len(o.Values))
+	for idx, v := range o.Values {
+		rows[idx] = []string{v.Value.String()}
+		if o.WithCounts {
+			rows[idx] = append(rows[idx], strconv.Itoa(v.Count))
+		}
+	}
+	return rows
+}
+
+// Distinct queries the records in pages and returns the unique values of the
+// field, which replaces the input's select clause. Values are compared by
+// their string form, empty values are skipped, and the values are sorted
+// numerically for numeric and date fields and lexically otherwise.
+func Distinct(qb *qbclient.Client, input *qbclient.QueryRecordsInput, fid int, withCounts bool) (*DistinctOutput, error) {
+	output := &DistinctOutput{WithCounts: withCounts, Values: []*DistinctValue{}}
+	input.Select = []int{fid}
+
+	values := map[string]*DistinctValue{}
+	err := QueryRecordsPaged(qb, input, distinctPageSize, 0, func(qro *qbclient.QueryRecordsOutput) error {
+		if output.Label == "" {
+			for _, f := range qro.Fields {
+				if f.FieldID == fid {
+					output.Label = f.Label
+				}
+			}
+			if output.Label == "" {
+				return fmt.Errorf("field %v not in the result", fid)
+			}
+		}
+
+		for _, record := range qro.Data {
+			data, ok := record[fid]
+			if !ok || data.Value == nil {
+				continue
+			}
+
+			s := data.Value.String()
+			if s == "" {
+				continue
+			}
+
+			if dv, ok := values[s]; ok {
+				dv.Count++
+			} else {
+				dv = &DistinctValue{Value: data.Value, Count: 1}
+				values[s] = dv
+				output.Values = append(output.Values, dv)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	sort.SliceStable(output.Values, func(i, j int) bool {
+		return lessValue(output.Values[i].Value, output.Values[j].Value)
+	})
+
+	return output, nil
+}
+
+// lessValue returns whether a sorts before b, comparing numbers and dates by
+// value and everything else by its string form.
+func lessValue(a, b *qbclient.Value) bool {
+	switch a.QuickBaseType {
+	case qbclient.FieldRecordID, qbclient.FieldNumeric, qbclient.FieldNumericCurrency, qbclient.FieldNumericPercent, qbclient.FieldNumericRating:
+		return a.Float64 < b.Float64
+	case qbclient.FieldDate, qbclient.FieldDateTime, qbclient.FieldTimeOfDay:
+		return a.Time.Before(b.Time)
+	case qbclient.FieldDuration:
+		return a.Duration < b.Duration
+	default:
+		return a.String() < b.String()
+	}
+}
@@ -0,0 +1,158 @@
+package qbcli
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+)
+
+// DownloadFileOptions are the options read through the command line.
+type DownloadFileOptions struct {
+	TableID  string `validate:"required" cliutil:"option=table-id"`
+	RecordID int    `validate:"required" cliutil:"option=record-id"`
+	FieldID  int    `validate:"required" cliutil:"option=field-id usage='file attachment field the file is downloaded from (required)'"`
+	Version  int    `cliutil:"option=version usage='version of the file to download, the latest if 0'"`
+}
+
+// DownloadFileOutput is the result of downloading a file.
+type DownloadFileOutput struct {
+	FileName string `json:"fileName"`
+	Version  int    `json:"version"`
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+}
+
+// DownloadFile downloads a file attachment of a record. The record is read
+// first to check that the field is a file attachment and to get the name of
+// the file, which is the default path. The path is the one passed through
+// --output, and if it is a directory, the file is written to it under its
+// original name. The file is streamed to a
+// temporary file in the same directory that is renamed once the download
+// completes, so a failed download never leaves a partial file behind.
+func DownloadFile(qb *qbclient.Client, opts *DownloadFileOptions, path string) (*DownloadFileOutput, error) {
+	output := &DownloadFileOutput{}
+
+	schema, err := GetTableSchema(qb, opts.TableID)
+	if err != nil {
+		return output, fmt.Errorf("error getting table metadata: %w", err)
+	}
+	field, ok := schema[opts.FieldID]
+	if !ok {
+		return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "field %v not in table", opts.FieldID)
+	}
+	if field.Type != qbclient.FieldFileAttachment {
+		return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "field %v (%s) is a %s field, not a file attachment", opts.FieldID, field.Label, field.Type)
+	}
+
+	version, err := fileVersion(qb, opts)
+	if err != nil {
+		return output, err
+	}
+	output.FileName = version.FileName
+	output.Version = version.Version
+
+	// Never write outside of the directory, whatever the name of the file.
+	name := filepath.Base(version.FileName)
+	if name == "." || name == string(filepath.Separator) {
+		name = fmt.Sprintf("%v-%v-%v", opts.RecordID, opts.FieldID, version.Version)
+	}
+
+	output.Path = path
+	if output.Path == "" {
+		output.Path = name
+	} else if qbclient.DirExists(output.Path) {
+		output.Path = filepath.Join(output.Path, name)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(output.Path), ".download-*")
+	if err != nil {
+		return output, fmt.Errorf("error creating file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// Temporary files are only readable by their owner.
+	if err := tmp.Chmod(0644); err != nil {
+		return output, fmt.Errorf("error creating file: %w", err)
+	}
+
+	dfo, err := qb.DownloadFile(&qbclient.DownloadFileInput{
+		TableID:  opts.TableID,
+		RecordID: opts.RecordID,
+		FieldID:  opts.FieldID,
+		Version:  version.Version,
+	}, tmp)
+	if err != nil {
+		return output, fmt.Errorf("error downloading file: %w", err)
+	}
+	output.Size = dfo.Size
+
+	if err := tmp.Close(); err != nil {
+		return output, fmt.Errorf("error writing file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), output.Path); err != nil {
+		return output, fmt.Errorf("error writing file: %w", err)
+	}
+
+	return output, nil
+}
+
+// RenderDownloadFile renders the output of DownloadFile to stdout, since
+// --output is the path the file is written to.
+func RenderDownloadFile(
+	ctx context.Context,
+	logger *cliutil.LeveledLogger,
+	cmd *cobra.Command,
+	cfg GlobalConfig,
+	v interface{},
+	err error,
+) {
+	cfg.cfg.Set(OptionOutputFile, "")
+	Render(ctx, logger, cmd, cfg, v, err)
+}
+
+// fileVersion returns the version of the file in the record's field that is
+// downloaded, which is the latest unless opts.Version is passed.
+func fileVersion(qb *qbclient.Client, opts *DownloadFileOptions) (*qbclient.FileVersion, error) {
+	qro, err := qb.QueryRecords(&qbclient.QueryRecordsInput{
+		From:   opts.TableID,
+		Select: []int{opts.FieldID},
+		Where:  fmt.Sprintf("{3.EX.%v}", opts.RecordID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading record: %w", err)
+	}
+	if len(qro.Data) == 0 {
+		return nil, qberrors.Client(nil).Safef(qberrors.NotFound, "record %v not found", opts.RecordID)
+	}
+
+	var latest *qbclient.FileVersion
+	if d, ok := qro.Data[0][opts.FieldID]; ok && d.Value != nil && d.Value.File != nil {
+		for _, v := range d.Value.File.Version {
+			if v == nil {
+				continue
+			}
+			if opts.Version != 0 && v.Version == opts.Version {
+				return v, nil
+			}
+			if latest == nil || v.Version > latest.Version {
+				latest = v
+			}
+		}
+	}
+
+	switch {
+	case latest == nil:
+		return nil, qberrors.Client(nil).Safef(qberrors.NotFound, "record %v has no file in field %v", opts.RecordID, opts.FieldID)
+	case opts.Version != 0:
+		return nil, qberrors.Client(nil).Safef(qberrors.NotFound, "version %v of the file in field %v not found", opts.Version, opts.FieldID)
+	}
+	return latest, nil
+}
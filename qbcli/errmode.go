@@ -0,0 +1,82 @@
+package qbcli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// ErrorModeOptions are the options that control how bulk commands handle
+// records and batches that fail. Best effort is the default, so the flag only
+// documents the behavior in scripts.
+type ErrorModeOptions struct {
+	FailFast   bool `cliutil:"option=fail-fast usage='stop at the first record or batch that fails'"`
+	BestEffort bool `cliutil:"option=best-effort usage='continue past records and batches that fail and report the errors, the default'"`
+}
+
+// BatchError models a batch that failed in best-effort mode.
+type BatchError struct {
+	Batch int    `json:"batch"`
+	Error string `json:"error"`
+}
+
+// FailureCounter is implemented by the output of bulk commands. Render exits
+// with a non-zero status after writing the output if there were failures.
+type FailureCounter interface {
+
+	// Failures returns the number of records and batches that failed.
+	Failures() int
+}
+
+// validate returns an error if both modes are set.
+func (o ErrorModeOptions) validate() error {
+	if o.FailFast && o.BestEffort {
+		return errors.New("options --fail-fast and --best-effort are mutually exclusive")
+	}
+	return nil
+}
+
+// batchError returns err in fail-fast mode. In best-effort mode, err is
+// appended to errs and nil is returned so that the next batch is processed.
+// batch is the 1-based position of the batch. The command always stops when
+// the API call or retry budget is exhausted, since every other batch would
+// fail too. Batches skipped by --dry-run aren't failures, so every batch is
+// written in either mode.
+func (o ErrorModeOptions) batchError(errs *[]*BatchError, batch int, err error) error {
+	if errors.Is(err, qbclient.ErrDryRun) {
+		return nil
+	}
+	if err == nil || o.FailFast || errors.Is(err, qbclient.BudgetExhausted) || errors.Is(err, qbclient.RetryBudgetExhausted) {
+		return err
+	}
+	*errs = append(*errs, &BatchError{Batch: batch, Error: err.Error()})
+	return nil
+}
+
+// lineErrors returns an error for the first line that failed in fail-fast
+// mode.
+func (o ErrorModeOptions) lineErrors(lineErrors map[string][]string) error {
+	if !o.FailFast || len(lineErrors) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(lineErrors))
+	for k := range lineErrors {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, aerr := strconv.Atoi(keys[i])
+		b, berr := strconv.Atoi(keys[j])
+		if aerr == nil && berr == nil {
+			return a < b
+		}
+		return keys[i] < keys[j]
+	})
+
+	k := keys[0]
+	return fmt.Errorf("record %s failed with --fail-fast set: %s", k, strings.Join(lineErrors[k], "; "))
+}
@@ -2,26 +2,228 @@ package qbcli
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 
+	"github.com/QuickBase/quickbase-cli/qbclient"
 	"github.com/QuickBase/quickbase-cli/qberrors"
 	"github.com/cpliakas/cliutil"
 )
 
 var (
-	TestsFailed = qberrors.ErrSafe{Message: "tests failed", StatusCode: http.StatusBadRequest}
+	TestsFailed     = qberrors.ErrSafe{Message: "tests failed", StatusCode: http.StatusBadRequest}
+	AssertionFailed = qberrors.ErrSafe{Message: "assertion failed", StatusCode: http.StatusBadRequest}
+	NotConfirmed    = qberrors.ErrSafe{Message: "confirmation required", StatusCode: http.StatusBadRequest}
+	TooManyErrors   = qberrors.ErrSafe{Message: "too many errors", StatusCode: http.StatusBadRequest}
+	PartialFailure  = qberrors.ErrSafe{Message: "partial failure", StatusCode: http.StatusBadRequest}
+	SchemaChanged   = qberrors.ErrSafe{Message: "schema changed", StatusCode: http.StatusConflict}
+	WrongRealm      = qberrors.ErrSafe{Message: "wrong realm hostname", StatusCode: http.StatusNotFound}
+	InvalidToken    = qberrors.ErrSafe{Message: "invalid token", StatusCode: http.StatusUnauthorized}
+	Unreachable     = qberrors.ErrSafe{Message: "network error", StatusCode: http.StatusServiceUnavailable}
 )
 
 func TestsFailedError(format string, a ...interface{}) error {
 	return qberrors.Client(nil).Safef(TestsFailed, format, a...)
 }
 
-// HandleError handles an error by logging it and returning a non-zero status.
-// We reserve Fatal errors for internal problems.
+// AssertionFailedError returns an error for an --assert expression that did
+// not evaluate to true.
+func AssertionFailedError(format string, a ...interface{}) error {
+	return qberrors.Client(nil).Safef(AssertionFailed, format, a...)
+}
+
+// NotConfirmedError returns an error for a mutation that requires
+// confirmation when stdin is not a terminal.
+func NotConfirmedError(format string, a ...interface{}) error {
+	return qberrors.Client(nil).Safef(NotConfirmed, format, a...)
+}
+
+// TooManyErrorsError returns an error for an import aborted because the
+// number of row errors reached --max-field-errors.
+func TooManyErrorsError(format string, a ...interface{}) error {
+	return qberrors.Client(nil).Safef(TooManyErrors, format, a...)
+}
+
+// PartialFailureError returns an error for a bulk command that completed in
+// best-effort mode after some records or batches failed.
+func PartialFailureError(format string, a ...interface{}) error {
+	return qberrors.Client(nil).Safef(PartialFailure, format, a...)
+}
+
+// SchemaChangedError returns an error for a command whose tables' schema
+// changed while it ran and --fail-on-schema-change was passed.
+func SchemaChangedError(format string, a ...interface{}) error {
+	return qberrors.Client(nil).Safef(SchemaChanged, format, a...)
+}
+
+// WrongRealmError returns an error for a request that failed because the
+// realm hostname doesn't resolve or isn't a Quickbase realm.
+func WrongRealmError(err error, format string, a ...interface{}) error {
+	return qberrors.Client(err).Safef(WrongRealm, format, a...)
+}
+
+// InvalidTokenError returns an error for a request whose token was missing or
+// rejected by the realm.
+func InvalidTokenError(err error, format string, a ...interface{}) error {
+	return qberrors.Client(err).Safef(InvalidToken, format, a...)
+}
+
+// UnreachableError returns an error for a request that got no response from
+// the realm.
+func UnreachableError(err error, format string, a ...interface{}) error {
+	return qberrors.Service(err).Safef(Unreachable, format, a...)
+}
+
+// Exit* constants are the exit codes of the CLI, so scripts can branch on the
+// class of a failure without parsing the log. ExitCode maps errors to them:
+//
+//	0  success, including requests skipped by --dry-run
+//	1  any other error, e.g., a failed assertion or a partial failure
+//	2  options or input not valid, whether rejected by the CLI or the API
+//	3  authentication failed, i.e., a 401 or 403 response, or a wrong realm
+//	4  rate limited, i.e., a 429 response or --max-api-calls reached
+//	5  network error, i.e., no response, a timeout, or a 502, 503, or 504
+//
+// Failures caused by several requests are classified by the first match in
+// the order 3, 4, 5, 2, e.g., a 429 that was retried until the client gave up
+// exits with 4.
+const (
+	ExitOK         = 0
+	ExitError      = 1
+	ExitValidation = 2
+	ExitAuth       = 3
+	ExitRateLimit  = 4
+	ExitNetwork    = 5
+)
+
+// ExitCode returns the exit code of a command that failed with err. The
+// statuses of the upstream errors are checked too, since a wrapping error,
+// e.g., one returned after retries ran out, has a status of its own.
+func ExitCode(err error) int {
+	var netErr net.Error
+	switch {
+	case err == nil || errors.Is(err, qbclient.ErrDryRun):
+		return ExitOK
+	case errors.Is(err, InvalidToken) || errors.Is(err, WrongRealm) || causeStatus(err, http.StatusUnauthorized, http.StatusForbidden):
+		return ExitAuth
+	case causeStatus(err, http.StatusTooManyRequests):
+		return ExitRateLimit
+	case errors.Is(err, qbclient.ErrTimeout) || errors.Is(err, qbclient.ErrRequestFailed) || errors.Is(err, Unreachable) || causeAs(err, &netErr) ||
+		causeStatus(err, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout):
+		return ExitNetwork
+	case outcomeError(err):
+		return ExitError
+	case qberrors.StatusCode(err) == http.StatusBadRequest || qberrors.StatusCode(err) == http.StatusUnprocessableEntity:
+		return ExitValidation
+	}
+	return ExitError
+}
+
+// outcomeError returns whether err reports the outcome of a command that ran,
+// which has a 400 status but isn't a validation error.
+func outcomeError(err error) bool {
+	for _, target := range []error{TestsFailed, AssertionFailed, NotConfirmed, TooManyErrors, PartialFailure} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// causeStatus returns whether the status code of err, or of any error that
+// caused it, is one of the codes.
+func causeStatus(err error, codes ...int) bool {
+	for err != nil {
+		status := qberrors.StatusCode(err)
+		for _, code := range codes {
+			if status == code {
+				return true
+			}
+		}
+		if !qberrors.IsSafe(err) {
+			return false
+		}
+		err = qberrors.Upstream(err)
+	}
+	return false
+}
+
+// causeAs is like errors.As, but also follows the upstream errors that caused
+// the safe errors in the chain, which errors.Unwrap doesn't return.
+func causeAs(err error, target interface{}) bool {
+	for err != nil {
+		if errors.As(err, target) {
+			return true
+		}
+		if !qberrors.IsSafe(err) {
+			return false
+		}
+		err = qberrors.Upstream(err)
+	}
+	return false
+}
+
+// HandleError handles an error by logging it and exiting with the status
+// returned by ExitCode. We reserve Fatal errors for internal problems. The
+// last request and response are written to stderr if --debug-on-error was
+// passed. Errors returned for requests skipped by --dry-run exit with a zero
+// status.
 func HandleError(ctx context.Context, logger *cliutil.LeveledLogger, message string, err error) {
+	// The request that would have changed data was already written by
+	// LoggerPlugin.DryRun, so the dry run succeeded.
+	if errors.Is(err, qbclient.ErrDryRun) {
+		os.Exit(ExitOK)
+	}
+
 	if err != nil {
-		logger.Error(ctx, message, err)
-		os.Exit(1)
+		exitError(ctx, logger, message, err, err, ExitCode(err))
+	}
+}
+
+// _jsonErrors is set when --format json is passed without --quiet, and makes
+// exitError write an ErrorOutput to stdout, on a single line if _compactErrors
+// is set.
+var _jsonErrors, _compactErrors bool
+
+// ErrorOutput is written to stdout as the error property of an object when a
+// command fails with --format json, so scripts can parse the error. RequestID
+// is the ID Quickbase assigned to the failed request, which Quickbase Support
+// needs to investigate it.
+type ErrorOutput struct {
+	Message   string `json:"message"`
+	Detail    string `json:"detail,omitempty"`
+	Status    int    `json:"status"`
+	ExitCode  int    `json:"exitCode"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// exitError logs the error and exits with the code, for callers that log a
+// different error than the cause the status and request ID are read from.
+func exitError(ctx context.Context, logger *cliutil.LeveledLogger, message string, err, cause error, code int) {
+	requestID := qbclient.RequestID(cause)
+	if requestID != "" {
+		ctx = cliutil.ContextWithLogTag(ctx, "requestid", requestID)
+	}
+	logger.Error(ctx, message, err)
+
+	if _jsonErrors {
+		output := &ErrorOutput{
+			Message:   maskLog(message),
+			Detail:    maskLog(err.Error()),
+			Status:    qberrors.StatusCode(cause),
+			ExitCode:  code,
+			RequestID: requestID,
+		}
+		if s, jerr := formatJSON(map[string]*ErrorOutput{"error": output}, _compactErrors); jerr == nil {
+			fmt.Println(s)
+		}
+	}
+
+	if _debug != nil {
+		_debug.WriteTo(os.Stderr)
 	}
+	os.Exit(code)
 }
@@ -0,0 +1,93 @@
+package qbcli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// QueryEstimate is the estimated cost of reading the records of a query.
+type QueryEstimate struct {
+	Records  int    `json:"records"`
+	PageSize int    `json:"pageSize"`
+	APICalls int    `json:"apiCalls"`
+	Latency  string `json:"latency"`
+	Duration string `json:"estimatedDuration"`
+}
+
+// EstimateQuery estimates the number of records, pages, and API calls needed
+// to read the records of a query, as QueryAllRecords would with max, or in a
+// single call unless paginate is set. The probe reads the first page with the
+// query's select clause, so the page size and latency it measures are
+// representative of the remaining pages. The duration adds --batch-delay
+// between pages but not time spent waiting on rate limits or retries.
+func EstimateQuery(qb *qbclient.Client, input *qbclient.QueryRecordsInput, max int, paginate bool) (*QueryEstimate, error) {
+	skip, top := 0, 0
+	if input.Options != nil {
+		skip, top = input.Options.Skip, input.Options.Top
+	}
+	if top > 0 && (max == 0 || top < max) {
+		max = top
+	}
+
+	// The probe requests the same page size as QueryPages when paginating.
+	probe := *input
+	probe.Options = &qbclient.QueryRecordsInputOptions{Skip: skip, Top: max}
+	if size := batchSize(DefaultBatchSize); paginate && (max == 0 || max > size) {
+		probe.Options.Top = size
+	}
+	if input.Options != nil {
+		probe.Options.UseAppTime = input.Options.UseAppTime
+	}
+
+	start := time.Now()
+	qro, err := qb.QueryRecords(&probe)
+	if err != nil {
+		return nil, fmt.Errorf("error probing query: %w", err)
+	}
+	latency := time.Since(start)
+
+	records := 0
+	if qro.Metadata != nil && qro.Metadata.TotalRecords > skip {
+		records = qro.Metadata.TotalRecords - skip
+	}
+	if max > 0 && records > max {
+		records = max
+	}
+
+	size := len(qro.Data)
+	if !paginate && records > size {
+		records = size
+	}
+
+	calls := 1
+	if size > 0 && records > size {
+		calls = (records + size - 1) / size
+	}
+
+	duration := time.Duration(calls)*latency + time.Duration(calls-1)*_batchDelay
+	return &QueryEstimate{
+		Records:  records,
+		PageSize: size,
+		APICalls: calls,
+		Latency:  latency.Round(time.Millisecond).String(),
+		Duration: duration.Round(time.Millisecond).String(),
+	}, nil
+}
+
+// ConfirmEstimate prompts a user to run a query after reviewing its estimate.
+// The prompt is skipped if --force is passed, and an error is returned if
+// STDIN is not a terminal.
+func ConfirmEstimate(cfg GlobalConfig, est *QueryEstimate) (bool, error) {
+	if cfg.Force() {
+		return true, nil
+	}
+	if !isTerminal(os.Stdin) {
+		return false, NotConfirmedError("query estimate requires confirmation, pass --force to proceed or --estimate-only to only report it")
+	}
+
+	label := fmt.Sprintf("Read %v records in about %v API calls, taking about %s?", est.Records, est.APICalls, est.Duration)
+	return Confirm(label)
+}
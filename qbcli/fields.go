@@ -0,0 +1,635 @@
+package qbcli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// FieldsFile models a file containing the schema of a table's fields.
+type FieldsFile struct {
+	TableID string         `yaml:"table_id"`
+	Fields  []*FieldSchema `yaml:"fields"`
+}
+
+// FieldSchema models the schema of a field in a FieldsFile. Fields are
+// matched by label on import, so the ID is for reference only.
+type FieldSchema struct {
+	FieldID         int    `yaml:"id,omitempty"`
+	Label           string `yaml:"label"`
+	Type            string `yaml:"type"`
+	Mode            string `yaml:"mode,omitempty"`
+	Required        bool   `yaml:"required,omitempty"`
+	Unique          bool   `yaml:"unique,omitempty"`
+	Bold            bool   `yaml:"bold,omitempty"`
+	NoWrap          bool   `yaml:"no_wrap,omitempty"`
+	AutoFill        bool   `yaml:"auto_fill,omitempty"`
+	Searchable      bool   `yaml:"searchable"`
+	AddToReports    bool   `yaml:"add_to_reports"`
+	HelpText        string `yaml:"help_text,omitempty"`
+	TrackField      bool   `yaml:"track_field,omitempty"`
+	DefaultValue    string `yaml:"default,omitempty"`
+	AllowNewChoices bool   `yaml:"allow_new_choices,omitempty"`
+	SortAsGiven     bool   `yaml:"sort_as_given,omitempty"`
+	NumLines        int    `yaml:"num_lines,omitempty"`
+	MaxLength       int    `yaml:"max_length,omitempty"`
+	Width           int    `yaml:"width,omitempty"`
+	Formula         string `yaml:"formula,omitempty"`
+	Comments        string `yaml:"comments,omitempty"`
+
+	// Permissions are only exported with --include-permissions, and only the
+	// roles listed are changed on import.
+	Permissions []*FieldSchemaPermission `yaml:"permissions,omitempty"`
+}
+
+// FieldSchemaPermission models a role's access to a field, which is View,
+// Modify, or None. Roles are matched by name, since their IDs differ across
+// apps.
+type FieldSchemaPermission struct {
+	Role string `yaml:"role"`
+	Type string `yaml:"type"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler by defaulting searchable and
+// add_to_reports to true, which matches the defaults of field create.
+func (s *FieldSchema) UnmarshalYAML(value *yaml.Node) error {
+	type fieldSchema FieldSchema
+	v := fieldSchema{Searchable: true, AddToReports: true}
+	if err := value.Decode(&v); err != nil {
+		return err
+	}
+	*s = FieldSchema(v)
+	return nil
+}
+
+// newFieldSchema returns the schema of a field returned by the API.
+func newFieldSchema(f *qbclient.ListFieldsOutputField) *FieldSchema {
+	s := &FieldSchema{
+		FieldID:      f.FieldID,
+		Label:        f.Label,
+		Type:         f.Type,
+		Mode:         f.Mode,
+		Required:     f.Required,
+		Unique:       f.Unique,
+		Bold:         f.DisplayInBold,
+		NoWrap:       f.DisplayWithoutWrapping,
+		AutoFill:     f.AutoFill,
+		Searchable:   f.Searchable,
+		AddToReports: f.AddToNewReports,
+		HelpText:     f.FieldHelpText,
+		TrackField:   f.TrackField,
+	}
+
+	for _, perm := range f.Permissions {
+		s.Permissions = append(s.Permissions, &FieldSchemaPermission{Role: perm.Role, Type: perm.Type})
+	}
+
+	if p := f.Properties; p != nil {
+		s.DefaultValue = p.DefaultValue
+		s.AllowNewChoices = p.AllowNewChoices
+		s.SortAsGiven = p.SortChoicesAsGiven
+		s.NumLines = p.NumberOfLines
+		s.MaxLength = p.MaxCharacters
+		s.Width = p.WidthOfInputBox
+		s.Formula = p.Formula
+		s.Comments = p.Comments
+	}
+
+	return s
+}
+
+// field returns the field and its properties as sent to the API.
+func (s *FieldSchema) field() (qbclient.Field, qbclient.FieldProperties) {
+	f := qbclient.Field{
+		Label:                  s.Label,
+		Type:                   s.Type,
+		Required:               s.Required,
+		Unique:                 s.Unique,
+		DisplayInBold:          s.Bold,
+		DisplayWithoutWrapping: s.NoWrap,
+		AutoFill:               s.AutoFill,
+		Searchable:             s.Searchable,
+		AddToNewReports:        s.AddToReports,
+		FieldHelpText:          s.HelpText,
+		TrackField:             s.TrackField,
+	}
+
+	p := qbclient.FieldProperties{
+		DefaultValue:       s.DefaultValue,
+		AllowNewChoices:    s.AllowNewChoices,
+		SortChoicesAsGiven: s.SortAsGiven,
+		NumberOfLines:      s.NumLines,
+		MaxCharacters:      s.MaxLength,
+		WidthOfInputBox:    s.Width,
+		Formula:            s.Formula,
+		Comments:           s.Comments,
+	}
+
+	return f, p
+}
+
+// changes returns the attributes that differ from the current schema as
+// "attribute: old -> new" strings, ignoring the ID and mode. Permissions are
+// compared by permissionChanges, since they depend on the destination's roles.
+func (s *FieldSchema) changes(current *FieldSchema) []string {
+	changes := []string{}
+
+	sv, cv := reflect.ValueOf(*s), reflect.ValueOf(*current)
+	for idx := 0; idx < sv.NumField(); idx++ {
+		tag := strings.Split(sv.Type().Field(idx).Tag.Get("yaml"), ",")[0]
+		if tag == "id" || tag == "mode" || tag == "permissions" {
+			continue
+		}
+
+		want, have := sv.Field(idx).Interface(), cv.Field(idx).Interface()
+		if want != have {
+			changes = append(changes, fmt.Sprintf("%s: %#v -> %#v", tag, have, want))
+		}
+	}
+
+	return changes
+}
+
+// permissionChanges returns the permissions that differ from the current
+// ones as "permissions: role: old -> new" strings. Only the roles in perms are
+// compared.
+func permissionChanges(perms, current []*qbclient.FieldPermission) []string {
+	have := make(map[string]string, len(current))
+	for _, perm := range current {
+		have[perm.Role] = perm.Type
+	}
+
+	changes := []string{}
+	for _, perm := range perms {
+		if have[perm.Role] != perm.Type {
+			changes = append(changes, fmt.Sprintf("permissions: %s: %#v -> %#v", perm.Role, have[perm.Role], perm.Type))
+		}
+	}
+	return changes
+}
+
+// resolvePermissions returns the permissions of the schema with the IDs of
+// the roles, which are keyed by name, and the names of the roles that don't
+// exist.
+func (s *FieldSchema) resolvePermissions(roles map[string]int) ([]*qbclient.FieldPermission, []string) {
+	perms, missing := []*qbclient.FieldPermission{}, []string{}
+	for _, perm := range s.Permissions {
+		id, ok := roles[perm.Role]
+		if !ok {
+			missing = append(missing, perm.Role)
+			continue
+		}
+		perms = append(perms, &qbclient.FieldPermission{Role: perm.Role, Type: perm.Type, RoleID: id})
+	}
+	return perms, missing
+}
+
+// FieldsExportOptions are the options read through the command line.
+type FieldsExportOptions struct {
+	TableID     string `validate:"required" cliutil:"option=table-id"`
+	Permissions bool   `cliutil:"option=include-permissions usage='include the permissions of each role on each field'"`
+}
+
+// listFieldsWithPermissions returns the table's fields with their
+// permissions, which aren't in the cached schema.
+func listFieldsWithPermissions(qb *qbclient.Client, tableID string) (FieldMap, error) {
+	output, err := qb.ListFields(&qbclient.ListFieldsInput{TableID: tableID, IncludeFieldPermissions: true})
+	if err != nil {
+		return nil, fmt.Errorf("error getting field permissions: %w", err)
+	}
+
+	fields := make(FieldMap, len(output.Fields))
+	for _, f := range output.Fields {
+		fields[f.FieldID] = f
+	}
+	return fields, nil
+}
+
+// FieldsExport returns the schema of the table's fields, ordered by field ID.
+// Built-in fields are omitted, since they can't be changed. Permissions are
+// included if opts.Permissions is set.
+func FieldsExport(qb *qbclient.Client, opts *FieldsExportOptions) (*FieldsFile, error) {
+	var fields FieldMap
+	var err error
+	if opts.Permissions {
+		fields, err = listFieldsWithPermissions(qb, opts.TableID)
+	} else {
+		fields, err = GetTableSchema(qb, opts.TableID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting table metadata: %w", err)
+	}
+
+	file := &FieldsFile{TableID: opts.TableID, Fields: []*FieldSchema{}}
+	for _, f := range sortedFields(fields) {
+		if f.FieldID > 5 {
+			file.Fields = append(file.Fields, newFieldSchema(f))
+		}
+	}
+
+	return file, nil
+}
+
+// WriteFieldsFile writes the file to w as YAML.
+func WriteFieldsFile(w io.Writer, file *FieldsFile) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(file); err != nil {
+		return fmt.Errorf("error encoding fields file: %w", err)
+	}
+	return enc.Close()
+}
+
+// RenderFieldsFile renders the output of FieldsExport as YAML to stdout, or
+// to the --output file, regardless of --format since field import reads it.
+func RenderFieldsFile(
+	ctx context.Context,
+	logger *cliutil.LeveledLogger,
+	cmd *cobra.Command,
+	cfg GlobalConfig,
+	v interface{},
+	err error,
+) {
+	if err != nil {
+		Render(ctx, logger, cmd, cfg, nil, err)
+	}
+
+	w, oerr := OpenOutput(cfg)
+	HandleError(ctx, logger, "error opening output file", oerr)
+	defer w.Close()
+
+	werr := WriteFieldsFile(w, v.(*FieldsFile))
+	HandleError(ctx, logger, "error writing fields file", werr)
+}
+
+// hasPermissions returns whether any field in the file has permissions.
+func (file *FieldsFile) hasPermissions() bool {
+	for _, s := range file.Fields {
+		if len(s.Permissions) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadFieldsFile reads and parses a file written by WriteFieldsFile.
+func ReadFieldsFile(path string) (*FieldsFile, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "error reading fields file: %w", err)
+	}
+
+	file := &FieldsFile{}
+	dec := yaml.NewDecoder(bytes.NewBuffer(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(file); err != nil {
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidSyntax, "yaml not valid: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for idx, s := range file.Fields {
+		if s.Label == "" || s.Type == "" {
+			return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "field %v: label and type are required", idx+1)
+		}
+		for _, perm := range s.Permissions {
+			if perm.Role == "" || (perm.Type != "View" && perm.Type != "Modify" && perm.Type != "None") {
+				return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "%s: permissions require a role and a type of View, Modify, or None", s.Label)
+			}
+		}
+		if seen[s.Label] {
+			return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "%s: label used by more than one field", s.Label)
+		}
+		seen[s.Label] = true
+	}
+
+	return file, nil
+}
+
+// FieldsImportOptions are the options read through the command line.
+type FieldsImportOptions struct {
+	TableID     string `validate:"required" cliutil:"option=table-id"`
+	File        string `validate:"required" cliutil:"option=file usage='YAML file written by field export (required)'"`
+	AllowDelete bool   `cliutil:"option=allow-delete usage='delete the fields that are not in the file'"`
+}
+
+// FieldsImportOutput is the result of importing a table's fields.
+type FieldsImportOutput struct {
+	Created   []*FieldChange `json:"created"`
+	Updated   []*FieldChange `json:"updated"`
+	Deleted   []*FieldChange `json:"deleted"`
+	Unchanged int            `json:"unchanged"`
+	NotInFile []*FieldChange `json:"notInFile,omitempty"`
+	Skipped   []*FieldChange `json:"skipped,omitempty"`
+}
+
+// FieldChange models a change made to a field by FieldsImport.
+type FieldChange struct {
+	FieldID int      `json:"fieldId,omitempty"`
+	Label   string   `json:"label"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// FieldsImport creates and updates the fields in a table to match a file
+// written by FieldsExport. Fields are matched by label. Fields that aren't in
+// the file are reported, and are only deleted if opts.AllowDelete is set.
+//
+// Lookup and summary fields can't be created without a relationship, so new
+// ones are skipped. The type of a field can't be changed through the API, so
+// a type mismatch is an error. Errors are returned before any change is made.
+//
+// Permissions in the file are applied to the roles of the same name, and roles
+// that don't exist in the table's app are logged and skipped. The changes are
+// reported without being made when --dry-run is passed.
+func FieldsImport(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *FieldsImportOptions) (*FieldsImportOutput, error) {
+	output := &FieldsImportOutput{
+		Created: []*FieldChange{},
+		Updated: []*FieldChange{},
+		Deleted: []*FieldChange{},
+	}
+
+	file, err := ReadFieldsFile(opts.File)
+	if err != nil {
+		return output, err
+	}
+
+	fields, err := GetTableSchema(qb, opts.TableID)
+	if err != nil {
+		return output, fmt.Errorf("error getting table metadata: %w", err)
+	}
+
+	// Read the permissions if the file has any. The permissions of each field
+	// list every role in the app, so they double as the list of roles.
+	roles := map[string]int{}
+	if file.hasPermissions() {
+		if fields, err = listFieldsWithPermissions(qb, opts.TableID); err != nil {
+			return output, err
+		}
+		for _, f := range fields {
+			for _, perm := range f.Permissions {
+				roles[perm.Role] = perm.RoleID
+			}
+		}
+	}
+
+	perms := map[*FieldSchema][]*qbclient.FieldPermission{}
+	missing := map[string][]string{}
+	for _, s := range file.Fields {
+		resolved, unknown := s.resolvePermissions(roles)
+		perms[s] = resolved
+		for _, role := range unknown {
+			missing[role] = append(missing[role], s.Label)
+		}
+	}
+	names := make([]string, 0, len(missing))
+	for role := range missing {
+		names = append(names, role)
+	}
+	sort.Strings(names)
+	for _, role := range names {
+		rctx := cliutil.ContextWithLogTag(ctx, "role", role)
+		rctx = cliutil.ContextWithLogTag(rctx, "fields", strings.Join(missing[role], ", "))
+		logger.Notice(rctx, "role not in app, skipping its field permissions")
+	}
+
+	labels := newLabelIndex(fields)
+
+	// Plan the changes, so that nothing is changed if the file is invalid.
+	type update struct {
+		fid    int
+		schema *FieldSchema
+	}
+	creates, updates := []*FieldSchema{}, []*update{}
+	for _, s := range file.Fields {
+		fid, err := labels.lookup(s.Label)
+		if err != nil {
+			return output, err
+		}
+		f, ok := fields[fid]
+		if !ok {
+			if s.Mode == "lookup" || s.Mode == "summary" {
+				output.Skipped = append(output.Skipped, &FieldChange{Label: s.Label, Changes: []string{s.Mode + " fields must be created with a relationship"}})
+				continue
+			}
+			output.Created = append(output.Created, &FieldChange{Label: s.Label})
+			creates = append(creates, s)
+			continue
+		}
+
+		if f.FieldID <= 5 {
+			continue
+		}
+
+		current := newFieldSchema(f)
+		if s.Type != current.Type {
+			return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "%s: type can't be changed from %s to %s", s.Label, current.Type, s.Type)
+		}
+
+		changes := append(s.changes(current), permissionChanges(perms[s], f.Permissions)...)
+		if len(changes) == 0 {
+			output.Unchanged++
+			continue
+		}
+
+		output.Updated = append(output.Updated, &FieldChange{FieldID: f.FieldID, Label: s.Label, Changes: changes})
+		updates = append(updates, &update{fid: f.FieldID, schema: s})
+	}
+
+	inFile := make(map[string]bool, len(file.Fields))
+	for _, s := range file.Fields {
+		inFile[s.Label] = true
+	}
+
+	deletes := []int{}
+	for _, f := range sortedFields(fields) {
+		if f.FieldID <= 5 || inFile[f.Label] {
+			continue
+		}
+		change := &FieldChange{FieldID: f.FieldID, Label: f.Label}
+		if opts.AllowDelete {
+			output.Deleted = append(output.Deleted, change)
+			deletes = append(deletes, f.FieldID)
+		} else {
+			output.NotInFile = append(output.NotInFile, change)
+		}
+	}
+
+	if cfg.DryRun() {
+		return output, nil
+	}
+
+	for idx, s := range creates {
+		f, p := s.field()
+		f.Create = true
+		f.Permissions = perms[s]
+
+		cfo, err := qb.CreateField(&qbclient.CreateFieldInput{
+			Field:      f,
+			TableID:    opts.TableID,
+			Properties: &qbclient.CreateFieldInputProperties{FieldProperties: p},
+		})
+		if err != nil {
+			return output, fmt.Errorf("error creating field %q: %w", s.Label, err)
+		}
+		output.Created[idx].FieldID = cfo.FieldID
+	}
+
+	for _, u := range updates {
+		f, p := u.schema.field()
+		f.Type = ""
+		f.Permissions = perms[u.schema]
+
+		_, err := qb.UpdateField(&qbclient.UpdateFieldInput{
+			Field:      f,
+			TableID:    opts.TableID,
+			FieldID:    u.fid,
+			Properties: &qbclient.UpdateFieldInputProperties{FieldProperties: p},
+		})
+		if err != nil {
+			return output, fmt.Errorf("error updating field %v: %w", u.fid, err)
+		}
+	}
+
+	if len(deletes) > 0 {
+		dfo, err := qb.DeleteFields(&qbclient.DeleteFieldsInput{TableID: opts.TableID, FieldIDs: deletes})
+		if err != nil {
+			return output, fmt.Errorf("error deleting fields: %w", err)
+		}
+		if len(dfo.Errors) > 0 {
+			return output, fmt.Errorf("error deleting fields: %s", strings.Join(dfo.Errors, ", "))
+		}
+	}
+
+	return output, nil
+}
+
+// Results of FieldEnsure.
+const (
+	FieldCreated   = "created"
+	FieldUpdated   = "updated"
+	FieldUnchanged = "unchanged"
+)
+
+// FieldEnsureOptions are the options read through the command line. They are
+// the options of field create, plus whether to update an existing field.
+type FieldEnsureOptions struct {
+	qbclient.CreateFieldInput
+
+	UpdateIfExists bool `cliutil:"option=update-if-exists usage='update the field to match the options if it exists'"`
+}
+
+// FieldEnsureOutput is the result of ensuring that a field exists.
+type FieldEnsureOutput struct {
+	Result  string   `json:"result"`
+	FieldID int      `json:"fieldId"`
+	Label   string   `json:"label"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+// FieldEnsure creates a field if the table has no field with its label. If it
+// has one, the attributes that differ from the options are returned as
+// changes, which are only made if opts.UpdateIfExists is set. As with
+// FieldsImport, the type of an existing field can't be changed, so a type
+// mismatch is an error, and built-in fields are never updated.
+func FieldEnsure(qb *qbclient.Client, opts *FieldEnsureOptions) (*FieldEnsureOutput, error) {
+	f, p := opts.Field, opts.Properties.FieldProperties
+	output := &FieldEnsureOutput{Label: f.Label}
+
+	if f.Label == "" || f.Type == "" {
+		return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "options %q and %q are required", "label", "type")
+	}
+
+	// Set the formula from the contents for a file.
+	if p.FormulaFile != "" {
+		p.Formula = p.FormulaFile
+	}
+
+	fields, err := GetTableSchema(qb, opts.TableID)
+	if err != nil {
+		return output, fmt.Errorf("error getting table metadata: %w", err)
+	}
+
+	fid, err := newLabelIndex(fields).lookup(f.Label)
+	if err != nil {
+		return output, err
+	}
+
+	existing, ok := fields[fid]
+	if !ok {
+		cfo, err := qb.CreateField(&qbclient.CreateFieldInput{
+			Field:      f,
+			TableID:    opts.TableID,
+			Properties: &qbclient.CreateFieldInputProperties{FieldProperties: p},
+		})
+		if err != nil {
+			return output, fmt.Errorf("error creating field %q: %w", f.Label, err)
+		}
+		output.Result, output.FieldID = FieldCreated, cfo.FieldID
+		return output, nil
+	}
+
+	output.Result, output.FieldID = FieldUnchanged, existing.FieldID
+	if existing.FieldID <= 5 {
+		return output, nil
+	}
+
+	current := newFieldSchema(existing)
+	if f.Type != current.Type {
+		return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "%s: type can't be changed from %s to %s", f.Label, current.Type, f.Type)
+	}
+
+	output.Changes = fieldSchemaOf(f, p).changes(current)
+	if len(output.Changes) == 0 || !opts.UpdateIfExists {
+		return output, nil
+	}
+
+	f.Type = ""
+	_, err = qb.UpdateField(&qbclient.UpdateFieldInput{
+		Field:      f,
+		TableID:    opts.TableID,
+		FieldID:    existing.FieldID,
+		Properties: &qbclient.UpdateFieldInputProperties{FieldProperties: p},
+	})
+	if err != nil {
+		return output, fmt.Errorf("error updating field %v: %w", existing.FieldID, err)
+	}
+
+	output.Result = FieldUpdated
+	return output, nil
+}
+
+// fieldSchemaOf returns the schema of a field and its properties as sent to
+// the API, which is the inverse of FieldSchema.field.
+func fieldSchemaOf(f qbclient.Field, p qbclient.FieldProperties) *FieldSchema {
+	return &FieldSchema{
+		Label:           f.Label,
+		Type:            f.Type,
+		Required:        f.Required,
+		Unique:          f.Unique,
+		Bold:            f.DisplayInBold,
+		NoWrap:          f.DisplayWithoutWrapping,
+		AutoFill:        f.AutoFill,
+		Searchable:      f.Searchable,
+		AddToReports:    f.AddToNewReports,
+		HelpText:        f.FieldHelpText,
+		TrackField:      f.TrackField,
+		DefaultValue:    p.DefaultValue,
+		AllowNewChoices: p.AllowNewChoices,
+		SortAsGiven:     p.SortChoicesAsGiven,
+		NumLines:        p.NumberOfLines,
+		MaxLength:       p.MaxCharacters,
+		Width:           p.WidthOfInputBox,
+		Formula:         p.Formula,
+		Comments:        p.Comments,
+	}
+}
@@ -0,0 +1,94 @@
+package qbcli
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// FieldFindOptions are the options read through the command line.
+type FieldFindOptions struct {
+	TableConcurrencyOptions
+
+	AppID      string `validate:"required" cliutil:"option=app-id"`
+	LabelRegex string `validate:"required" cliutil:"option=label-regex usage='regular expression matched against field labels'"`
+}
+
+// FieldFindOutput lists the fields that matched the pattern.
+type FieldFindOutput struct {
+	Fields []*FieldFindMatch `json:"fields"`
+	Errors []*TableError     `json:"errors,omitempty"`
+}
+
+// FieldFindMatch models a field that matched the pattern.
+type FieldFindMatch struct {
+	TableID   string `json:"tableId"`
+	TableName string `json:"tableName"`
+	FieldID   int    `json:"fieldId"`
+	Label     string `json:"label"`
+	Type      string `json:"type"`
+}
+
+// TableHeader implements Tabular.TableHeader.
+func (o *FieldFindOutput) TableHeader() []string {
+	return []string{"Table ID", "Table", "Field ID", "Label", "Type"}
+}
+
+// TableRows implements Tabular.TableRows.
+func (o *FieldFindOutput) TableRows() [][]string {
+	rows := make([][]string, len(o.Fields))
+	for idx, f := range o.Fields {
+		rows[idx] = []string{f.TableID, f.TableName, strconv.Itoa(f.FieldID), f.Label, f.Type}
+	}
+	return rows
+}
+
+// FieldFind searches every table in an app for fields whose label matches a
+// regular expression.
+func FieldFind(qb *qbclient.Client, opts *FieldFindOptions) (*FieldFindOutput, error) {
+	output := &FieldFindOutput{Fields: []*FieldFindMatch{}}
+
+	re, err := regexp.Compile(opts.LabelRegex)
+	if err != nil {
+		return output, fmt.Errorf("label-regex option not valid: %w", err)
+	}
+
+	tables, err := qb.ListTablesByAppID(opts.AppID)
+	if err != nil {
+		return output, fmt.Errorf("error listing tables: %w", err)
+	}
+
+	// Tables are searched in parallel and the matches are aggregated in the
+	// order of the tables.
+	matches := make([][]*FieldFindMatch, len(tables.Tables))
+	output.Errors, err = forEachTable(tables.Tables, opts.TableConcurrencyOptions, func(idx int, table *qbclient.ListTablesOutputTable) error {
+		fields, err := GetTableSchema(qb, table.TableID)
+		if err != nil {
+			return fmt.Errorf("error getting table metadata: %w", err)
+		}
+
+		for _, field := range sortedFields(fields) {
+			if re.MatchString(field.Label) {
+				matches[idx] = append(matches[idx], &FieldFindMatch{
+					TableID:   table.TableID,
+					TableName: table.Name,
+					FieldID:   field.FieldID,
+					Label:     field.Label,
+					Type:      field.Type,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	for _, m := range matches {
+		output.Fields = append(output.Fields, m...)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,299 @@
+package qbcli
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// GenerateOptions are the options read through the command line.
+type GenerateOptions struct {
+	TableID string `validate:"required" cliutil:"option=table-id"`
+	Count   int    `validate:"min=1" cliutil:"option=count default=10 usage='number of records to generate'"`
+	Seed    int    `cliutil:"option=seed usage='seed of the random values, so the same records are generated for the same schema, random if 0'"`
+	Delay   int    `cliutil:"option=delay"`
+	Yes     bool   `cliutil:"option=yes usage='insert the records without prompting for confirmation'"`
+}
+
+// GenerateOutput is the result of generating records. The records are only
+// included when --dry-run is passed, so they can be rendered in any format.
+type GenerateOutput struct {
+	qbclient.Records
+
+	Seed       int64               `json:"seed"`
+	Generated  int                 `json:"generated"`
+	Created    int                 `json:"created"`
+	LineErrors map[string][]string `json:"lineErrors,omitempty"`
+}
+
+// Word lists the generated values are picked from.
+var (
+	generateFirstNames = []string{"Ada", "Ben", "Chloe", "Diego", "Elena", "Farah", "Grace", "Hiro", "Isla", "Jamal", "Kara", "Liam", "Maya", "Noah", "Olga", "Priya", "Quinn", "Ravi", "Sofia", "Theo"}
+	generateLastNames  = []string{"Anderson", "Brown", "Chen", "Diaz", "Evans", "Fischer", "Garcia", "Hughes", "Ito", "Johnson", "Khan", "Lopez", "Martin", "Nguyen", "Okafor", "Patel", "Rossi", "Smith", "Tanaka", "Walker"}
+	generateCompanies  = []string{"Acme", "Globex", "Initech", "Umbrella", "Hooli", "Stark Industries", "Wayne Enterprises", "Wonka", "Soylent", "Cyberdyne"}
+	generateCities     = []string{"Boston", "Chicago", "Denver", "Austin", "Seattle", "Portland", "Atlanta", "Phoenix", "Miami", "Detroit"}
+	generateWords      = []string{"alpha", "bravo", "delta", "echo", "harbor", "summit", "river", "maple", "cobalt", "orbit", "lumen", "quartz", "falcon", "meadow", "ember", "vertex"}
+)
+
+// Generate inserts records with random but plausible values for the writable
+// fields of a table. Values are generated from the field type, and text
+// fields whose label suggests a name, email, company, city, or phone number
+// get a value of that kind. Multiple-choice fields get one of their choices.
+// Built-in, formula, lookup, summary, user, and file attachment fields are
+// skipped. The same seed generates the same records for the same schema.
+func Generate(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *GenerateOptions) (*GenerateOutput, error) {
+	output := &GenerateOutput{Seed: int64(opts.Seed), LineErrors: map[string][]string{}}
+	if output.Seed == 0 {
+		output.Seed = time.Now().UnixNano()
+	}
+	logger.Info(cliutil.ContextWithLogTag(ctx, "seed", strconv.FormatInt(output.Seed, 10)), "generating records")
+
+	schema, err := GetTableSchema(qb, opts.TableID)
+	if err != nil {
+		return output, fmt.Errorf("error getting table metadata: %w", err)
+	}
+
+	fields := []*qbclient.ListFieldsOutputField{}
+	for _, f := range sortedFields(schema) {
+		if generatable(f) {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return output, fmt.Errorf("table %s has no fields that values can be generated for", opts.TableID)
+	}
+
+	rnd := rand.New(rand.NewSource(output.Seed))
+	data := make([]map[int]*qbclient.InsertRecordsInputData, opts.Count)
+	for idx := range data {
+		data[idx] = make(map[int]*qbclient.InsertRecordsInputData, len(fields))
+		for _, f := range fields {
+			data[idx][f.FieldID] = &qbclient.InsertRecordsInputData{Value: generateValue(rnd, f, idx+1)}
+		}
+	}
+	output.Generated = len(data)
+
+	if cfg.DryRun() {
+		for _, f := range fields {
+			output.Fields = append(output.Fields, &qbclient.RecordsField{FieldID: f.FieldID, Label: f.Label, Type: f.Type})
+		}
+		output.Data = make([]map[int]*qbclient.RecordsData, len(data))
+		for idx, record := range data {
+			output.Data[idx] = make(map[int]*qbclient.RecordsData, len(record))
+			for fid, d := range record {
+				output.Data[idx][fid] = &qbclient.RecordsData{Value: d.Value}
+			}
+		}
+		return output, nil
+	}
+
+	label := fmt.Sprintf("Insert %v generated records into table %s?", len(data), opts.TableID)
+	ok, err := ConfirmCount(cfg, label, len(data), opts.Yes)
+	if err != nil {
+		return output, err
+	}
+	if !ok {
+		logger.Notice(ctx, "no records inserted")
+		return output, nil
+	}
+
+	size := batchSize(DefaultInsertBatchSize)
+	for start := 0; start < len(data); start += size {
+		end := start + size
+		if end > len(data) {
+			end = len(data)
+		}
+
+		iro, err := qb.InsertRecords(&qbclient.InsertRecordsInput{To: opts.TableID, Data: data[start:end]})
+		if err != nil {
+			return output, fmt.Errorf("error inserting records: %w", err)
+		}
+
+		output.Created += len(iro.Metadata.CreatedRecordIDs)
+		for k, v := range iro.Metadata.LineErrors {
+			if pos, err := strconv.Atoi(k); err == nil {
+				k = strconv.Itoa(start + pos)
+			}
+			output.LineErrors[k] = v
+		}
+
+		// Delay before the next API call.
+		if end < len(data) {
+			pauseBatch(opts.Delay)
+		}
+	}
+
+	logger.Notice(cliutil.ContextWithLogTag(ctx, "created", strconv.Itoa(output.Created)), "records generated")
+
+	return output, nil
+}
+
+// generatable returns whether values can be generated for a field.
+func generatable(f *qbclient.ListFieldsOutputField) bool {
+	if f.FieldID <= 5 || f.Mode != "" {
+		return false
+	}
+
+	switch f.Type {
+	case qbclient.FieldText, qbclient.FieldTextMultiLine, qbclient.FieldTextMultipleChoice, qbclient.FieldRichText,
+		qbclient.FieldMultiSelectText, qbclient.FieldNumeric, qbclient.FieldNumericCurrency, qbclient.FieldNumericPercent,
+		qbclient.FieldNumericRating, qbclient.FieldDate, qbclient.FieldDateTime, qbclient.FieldTimeOfDay,
+		qbclient.FieldDuration, qbclient.FieldCheckbox, qbclient.FieldPhoneNumber, qbclient.FieldEmailAddress,
+		qbclient.FieldURL:
+		return true
+	default:
+		return false
+	}
+}
+
+// generateValue returns a random value for the field. n is the 1-based
+// position of the record, which is appended to the values of unique fields.
+func generateValue(rnd *rand.Rand, f *qbclient.ListFieldsOutputField, n int) *qbclient.Value {
+	first, last := pick(rnd, generateFirstNames), pick(rnd, generateLastNames)
+	suffix := ""
+	if f.Unique {
+		suffix = " " + strconv.Itoa(n)
+	}
+
+	var choices []string
+	if f.Properties != nil {
+		choices = f.Properties.Choices
+	}
+
+	switch f.Type {
+	case qbclient.FieldTextMultipleChoice:
+		if len(choices) > 0 {
+			return qbclient.NewTextMultipleChoiceValue(pick(rnd, choices))
+		}
+		return qbclient.NewTextMultipleChoiceValue(pick(rnd, generateWords))
+
+	case qbclient.FieldMultiSelectText:
+		if len(choices) == 0 {
+			choices = generateWords
+		}
+		picked := []string{pick(rnd, choices)}
+		if c := pick(rnd, choices); c != picked[0] && rnd.Intn(2) == 0 {
+			picked = append(picked, c)
+		}
+		return qbclient.NewMultiSelectTextValue(picked)
+
+	case qbclient.FieldTextMultiLine, qbclient.FieldRichText:
+		return qbclient.NewTextValue(truncate(f, sentence(rnd, 12), suffix))
+
+	case qbclient.FieldNumeric:
+		if f.Unique {
+			return qbclient.NewNumericValue(float64(n))
+		}
+		return qbclient.NewNumericValue(float64(rnd.Intn(1000)))
+
+	case qbclient.FieldNumericCurrency:
+		return qbclient.NewNumericCurrencyValue(float64(rnd.Intn(1000000)) / 100)
+
+	case qbclient.FieldNumericPercent:
+		return qbclient.NewNumericPercentValue(float64(rnd.Intn(101)) / 100)
+
+	case qbclient.FieldNumericRating:
+		return qbclient.NewNumericRatingValue(float64(1 + rnd.Intn(5)))
+
+	case qbclient.FieldDate:
+		return qbclient.NewDateValue(randomTime(rnd).Truncate(24 * time.Hour))
+
+	case qbclient.FieldDateTime:
+		return qbclient.NewDateTimeValue(randomTime(rnd).Truncate(time.Second))
+
+	case qbclient.FieldTimeOfDay:
+		return qbclient.NewTimeOfDayValue(time.Date(0, 1, 1, 8+rnd.Intn(10), rnd.Intn(4)*15, 0, 0, time.UTC))
+
+	case qbclient.FieldDuration:
+		return qbclient.NewDurationValue(time.Duration(15*(1+rnd.Intn(32))) * time.Minute)
+
+	case qbclient.FieldCheckbox:
+		return qbclient.NewCheckboxValue(rnd.Intn(2) == 0)
+
+	case qbclient.FieldEmailAddress:
+		return qbclient.NewEmailAddressValue(email(first, last, f.Unique, n))
+
+	case qbclient.FieldPhoneNumber:
+		return qbclient.NewPhoneNumberValue(fmt.Sprintf("(555) 01%02d", rnd.Intn(100)))
+
+	case qbclient.FieldURL:
+		v, _ := qbclient.NewURLValueFromString("https://example.com/" + pick(rnd, generateWords))
+		return v
+	}
+
+	// Text fields get a value that matches their label.
+	label := strings.ToLower(f.Label)
+	var s string
+	switch {
+	case strings.Contains(label, "email"):
+		return qbclient.NewTextValue(truncate(f, email(first, last, f.Unique, n), ""))
+	case strings.Contains(label, "first name"):
+		s = first
+	case strings.Contains(label, "last name"):
+		s = last
+	case strings.Contains(label, "name") && !strings.Contains(label, "company"):
+		s = first + " " + last
+	case strings.Contains(label, "company"), strings.Contains(label, "organization"):
+		s = pick(rnd, generateCompanies)
+	case strings.Contains(label, "city"):
+		s = pick(rnd, generateCities)
+	case strings.Contains(label, "phone"):
+		s = fmt.Sprintf("(555) 01%02d", rnd.Intn(100))
+	default:
+		s = sentence(rnd, 3)
+	}
+	return qbclient.NewTextValue(truncate(f, s, suffix))
+}
+
+// pick returns a random item of the list.
+func pick(rnd *rand.Rand, list []string) string {
+	return list[rnd.Intn(len(list))]
+}
+
+// sentence returns up to max random words, starting with a capital letter.
+func sentence(rnd *rand.Rand, max int) string {
+	words := make([]string, 1+rnd.Intn(max))
+	for idx := range words {
+		words[idx] = pick(rnd, generateWords)
+	}
+	s := strings.Join(words, " ")
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// email returns an address at example.com, which is reserved for examples.
+func email(first, last string, unique bool, n int) string {
+	local := strings.ToLower(first + "." + last)
+	if unique {
+		local += strconv.Itoa(n)
+	}
+	return local + "@example.com"
+}
+
+// generateEpoch is the start of the year that generated dates fall in. It is
+// fixed rather than relative to now so that a seed always generates the same
+// dates.
+var generateEpoch = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// randomTime returns a time in the year after generateEpoch.
+func randomTime(rnd *rand.Rand) time.Time {
+	return generateEpoch.Add(time.Duration(rnd.Int63n(int64(365 * 24 * time.Hour))))
+}
+
+// truncate truncates s so that s and the suffix fit in the field's maximum
+// number of characters. The suffix is kept so the values of unique fields
+// stay unique.
+func truncate(f *qbclient.ListFieldsOutputField, s, suffix string) string {
+	if f.Properties != nil && f.Properties.MaxCharacters > 0 && len(s)+len(suffix) > f.Properties.MaxCharacters {
+		if max := f.Properties.MaxCharacters - len(suffix); max > 0 {
+			return strings.TrimSpace(s[:max]) + suffix
+		}
+		return suffix[len(suffix)-f.Properties.MaxCharacters:]
+	}
+	return s + suffix
+}
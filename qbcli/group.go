@@ -0,0 +1,209 @@
+package qbcli
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+	"github.com/cpliakas/cliutil"
+)
+
+// RunReportOptions are the options read through the command line.
+type RunReportOptions struct {
+	qbclient.RunReportInput
+
+	FlattenGroups bool `cliutil:"option=flatten-groups usage='return the records of grouped reports as flat rows, which skips the request for the report definition that grouping needs'"`
+}
+
+// GroupedReportOutput models the results of a grouped report, with the
+// records nested in their groups.
+type GroupedReportOutput struct {
+	Fields   []*qbclient.RecordsField  `json:"fields"`
+	Groups   []*ReportGroup            `json:"groups"`
+	Metadata *qbclient.RecordsMetadata `json:"metadata,omitempty"`
+}
+
+// ReportGroup models a group of records that have the same value for the
+// grouped field. Subtotals are keyed by the ID of the numeric field that is
+// summed.
+type ReportGroup struct {
+	FieldID   int                             `json:"fieldId"`
+	Label     string                          `json:"label"`
+	Value     string                          `json:"value"`
+	Count     int                             `json:"count"`
+	Subtotals map[int]float64                 `json:"subtotals,omitempty"`
+	Groups    []*ReportGroup                  `json:"groups,omitempty"`
+	Data      []map[int]*qbclient.RecordsData `json:"data,omitempty"`
+}
+
+// RunReport runs a report and, unless FlattenGroups is set, nests the records
+// of grouped reports in their groups. Users are decoded if configured. The
+// grouping fields are read from the report's definition, which takes a second
+// request unless no records are returned. The groups are built in the order
+// the records are returned, which is sorted by the grouping fields.
+func RunReport(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *RunReportOptions) (interface{}, error) {
+	output, err := qb.RunReport(&opts.RunReportInput)
+	if err == nil && cfg.DecodeUsers() {
+		DecodeUsers(ctx, logger, qb, cfg.DefaultAppID(), output.Records)
+	}
+	if err != nil || opts.FlattenGroups || len(output.Data) == 0 {
+		return output, err
+	}
+
+	report, err := qb.GetReport(&qbclient.GetReportInput{TableID: opts.TableID, ReportID: opts.ReportID})
+	if err != nil {
+		return output, err
+	}
+	if report.Query == nil || len(report.Query.GroupBy) == 0 {
+		return output, nil
+	}
+
+	fids := make([]int, len(report.Query.GroupBy))
+	for idx, gb := range report.Query.GroupBy {
+		fids[idx] = gb.FieldID
+	}
+
+	return GroupRecords(output.Records, fids), nil
+}
+
+// ReportQuery returns the query of a report, i.e., its filter, fields, and
+// sort order, so that it can be reused as the starting point of a query.
+// Report formula fields, which have negative IDs, can't be queried and are
+// removed from the fields and sort order.
+func ReportQuery(qb *qbclient.Client, tableID, reportID string) (*qbclient.ReportQuery, error) {
+	report, err := qb.GetReport(&qbclient.GetReportInput{TableID: tableID, ReportID: reportID})
+	if err != nil {
+		return nil, err
+	}
+	if report.Query == nil {
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "report %s has no query", reportID)
+	}
+
+	query := *report.Query
+	query.Fields, query.SortBy = []int{}, []*qbclient.ReportQuerySortBy{}
+	for _, fid := range report.Query.Fields {
+		if fid > 0 {
+			query.Fields = append(query.Fields, fid)
+		}
+	}
+	for _, sb := range report.Query.SortBy {
+		if sb.FieldID > 0 {
+			query.SortBy = append(query.SortBy, sb)
+		}
+	}
+
+	return &query, nil
+}
+
+// GroupRecords nests records in groups by the values of the passed fields.
+func GroupRecords(records qbclient.Records, fids []int) *GroupedReportOutput {
+	fields := make(map[int]*qbclient.RecordsField, len(records.Fields))
+	for _, f := range records.Fields {
+		fields[f.FieldID] = f
+	}
+
+	return &GroupedReportOutput{
+		Fields:   records.Fields,
+		Groups:   groupRecords(records.Data, fields, fids),
+		Metadata: records.Metadata,
+	}
+}
+
+func groupRecords(data []map[int]*qbclient.RecordsData, fields map[int]*qbclient.RecordsField, fids []int) []*ReportGroup {
+	groups := []*ReportGroup{}
+	byValue := map[string]*ReportGroup{}
+
+	for _, record := range data {
+		value := recordString(record, fids[0])
+		group, ok := byValue[value]
+		if !ok {
+			group = &ReportGroup{FieldID: fids[0], Value: value, Subtotals: map[int]float64{}}
+			if f, ok := fields[fids[0]]; ok {
+				group.Label = f.Label
+			}
+			byValue[value] = group
+			groups = append(groups, group)
+		}
+
+		group.Count++
+		group.Data = append(group.Data, record)
+		for fid, rd := range record {
+			if f, ok := fields[fid]; ok && rd.Value != nil && summable(f.Type) {
+				group.Subtotals[fid] += rd.Value.Float64
+			}
+		}
+	}
+
+	// Nest the records in the subgroups.
+	if len(fids) > 1 {
+		for _, group := range groups {
+			group.Groups = groupRecords(group.Data, fields, fids[1:])
+			group.Data = nil
+		}
+	}
+
+	return groups
+}
+
+// summable returns whether subtotals are calculated for the field type.
+func summable(ftype string) bool {
+	return ftype == qbclient.FieldNumeric || ftype == qbclient.FieldNumericCurrency
+}
+
+// TableHeader implements Tabular.TableHeader.
+func (o *GroupedReportOutput) TableHeader() []string {
+	labels := make([]string, len(o.Fields))
+	for idx, f := range o.Fields {
+		labels[idx] = f.Label
+	}
+	return labels
+}
+
+// TableRows implements Tabular.TableRows. Each group is rendered as a header
+// row, followed by its records or subgroups and a row of subtotals.
+func (o *GroupedReportOutput) TableRows() [][]string {
+	rows := [][]string{}
+	if len(o.Fields) == 0 {
+		return rows
+	}
+	for _, group := range o.Groups {
+		rows = o.appendGroup(rows, group, 0)
+	}
+	return rows
+}
+
+func (o *GroupedReportOutput) appendGroup(rows [][]string, group *ReportGroup, depth int) [][]string {
+	indent := strings.Repeat("  ", depth)
+
+	header := make([]string, len(o.Fields))
+	header[0] = indent + group.Label + ": " + group.Value + " (" + strconv.Itoa(group.Count) + ")"
+	rows = append(rows, header)
+
+	for _, sub := range group.Groups {
+		rows = o.appendGroup(rows, sub, depth+1)
+	}
+
+	for _, record := range group.Data {
+		row := make([]string, len(o.Fields))
+		for idx, f := range o.Fields {
+			row[idx] = recordString(record, f.FieldID)
+		}
+		rows = append(rows, row)
+	}
+
+	if len(group.Subtotals) == 0 {
+		return rows
+	}
+
+	// The label is prefixed to the first column, which can have a subtotal.
+	subtotals := make([]string, len(o.Fields))
+	subtotals[0] = indent + "Subtotal: "
+	for idx, f := range o.Fields {
+		if total, ok := group.Subtotals[f.FieldID]; ok {
+			subtotals[idx] += strconv.FormatFloat(total, 'f', -1, 64)
+		}
+	}
+	return append(rows, subtotals)
+}
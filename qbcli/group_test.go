@@ -0,0 +1,67 @@
+package qbcli_test
+
+import (
+	"testing"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+func TestGroupRecords(t *testing.T) {
+	record := func(region, rep string, amount float64) map[int]*qbclient.RecordsData {
+		return map[int]*qbclient.RecordsData{
+			6: {Value: qbclient.NewTextValue(region)},
+			7: {Value: qbclient.NewTextValue(rep)},
+			8: {Value: qbclient.NewNumericValue(amount)},
+		}
+	}
+
+	records := qbclient.Records{
+		Fields: []*qbclient.RecordsField{
+			{FieldID: 6, Label: "Region", Type: qbclient.FieldText},
+			{FieldID: 7, Label: "Rep", Type: qbclient.FieldText},
+			{FieldID: 8, Label: "Amount", Type: qbclient.FieldNumeric},
+		},
+		Data: []map[int]*qbclient.RecordsData{
+			record("East", "Ann", 1),
+			record("East", "Ann", 2),
+			record("East", "Bob", 4),
+			record("West", "Cy", 8),
+		},
+	}
+
+	output := qbcli.GroupRecords(records, []int{6, 7})
+	if len(output.Groups) != 2 {
+		t.Fatalf("got %v groups, expected 2", len(output.Groups))
+	}
+
+	east := output.Groups[0]
+	if east.Label != "Region" || east.Value != "East" || east.Count != 3 {
+		t.Errorf("got group %q %q with %v records, expected Region East with 3", east.Label, east.Value, east.Count)
+	}
+	if east.Subtotals[8] != 7 {
+		t.Errorf("got subtotal %v, expected 7", east.Subtotals[8])
+	}
+	if len(east.Data) != 0 {
+		t.Errorf("got %v records in group with subgroups, expected 0", len(east.Data))
+	}
+
+	if len(east.Groups) != 2 {
+		t.Fatalf("got %v subgroups, expected 2", len(east.Groups))
+	}
+	ann := east.Groups[0]
+	if ann.Value != "Ann" || ann.Count != 2 || len(ann.Data) != 2 {
+		t.Errorf("got subgroup %q with %v records, expected Ann with 2", ann.Value, ann.Count)
+	}
+	if ann.Subtotals[8] != 3 {
+		t.Errorf("got subtotal %v, expected 3", ann.Subtotals[8])
+	}
+	if _, ok := ann.Subtotals[7]; ok {
+		t.Error("got subtotal for text field, expected none")
+	}
+
+	west := output.Groups[1]
+	if west.Value != "West" || west.Subtotals[8] != 8 || len(west.Groups) != 1 {
+		t.Errorf("got group %q with subtotal %v and %v subgroups, expected West with 8 and 1", west.Value, west.Subtotals[8], len(west.Groups))
+	}
+}
@@ -0,0 +1,95 @@
+package qbcli
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// HashAlgorithm is the algorithm that result sets are hashed with.
+const HashAlgorithm = "sha256"
+
+// HashOptions are the options read through the command line.
+type HashOptions struct {
+	TableID    string `validate:"required" cliutil:"option=table-id"`
+	Where      string `cliutil:"option=where func=query"`
+	Select     []int  `cliutil:"option=select"`
+	SelectFile string `cliutil:"option=select-file usage='file listing the field IDs or labels to select, added to --select'"`
+	Delay      int    `cliutil:"option=delay"`
+}
+
+// HashOutput is the hash of a result set.
+type HashOutput struct {
+	Hash      string `json:"hash"`
+	Algorithm string `json:"algorithm"`
+	Records   int    `json:"records"`
+}
+
+// Hash queries records and returns a hash of the result set that doesn't
+// depend on the order of the records or the selected fields.
+//
+// Each record is normalized to its selected fields sorted by field ID, written
+// as "fid=value" pairs separated by the unit separator (0x1F), and hashed with
+// SHA-256. The record digests are then sorted and hashed together, so the same
+// records produce the same hash regardless of the order they are returned in.
+func Hash(qb *qbclient.Client, opts *HashOptions) (*HashOutput, error) {
+	output := &HashOutput{Algorithm: HashAlgorithm}
+
+	sel, err := withSelectFile(qb, opts.TableID, opts.Select, opts.SelectFile)
+	if err != nil {
+		return output, err
+	}
+
+	// Normalize the field order, ignoring fields selected more than once.
+	fids := []int{}
+	seen := map[int]bool{}
+	for _, fid := range sel {
+		if !seen[fid] {
+			seen[fid] = true
+			fids = append(fids, fid)
+		}
+	}
+	sort.Ints(fids)
+
+	input := &qbclient.QueryRecordsInput{Select: fids, From: opts.TableID, Where: opts.Where}
+
+	digests := [][]byte{}
+	err = QueryRecordsPaged(qb, input, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
+		for _, record := range qro.Data {
+			digests = append(digests, hashRecord(record, fids))
+		}
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	sort.Slice(digests, func(i, j int) bool { return bytes.Compare(digests[i], digests[j]) < 0 })
+
+	h := sha256.New()
+	for _, d := range digests {
+		h.Write(d)
+	}
+
+	output.Hash = hex.EncodeToString(h.Sum(nil))
+	output.Records = len(digests)
+
+	return output, nil
+}
+
+// hashRecord returns the SHA-256 digest of the normalized record. The fids
+// must be sorted.
+func hashRecord(record map[int]*qbclient.RecordsData, fids []int) []byte {
+	pairs := make([]string, len(fids))
+	for idx, fid := range fids {
+		pairs[idx] = strconv.Itoa(fid) + "=" + recordString(record, fid)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(pairs, "\x1f")))
+	return sum[:]
+}
@@ -0,0 +1,108 @@
+package qbcli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"gopkg.in/yaml.v3"
+)
+
+// FieldSyncHelpOptions are the options read through the command line.
+type FieldSyncHelpOptions struct {
+	TableID string `validate:"required" cliutil:"option=table-id"`
+	File    string `validate:"required" cliutil:"option=file usage='YAML file of help text keyed by field label (required)'"`
+}
+
+// FieldSyncHelpOutput is the result of syncing field help text.
+type FieldSyncHelpOutput struct {
+	Updated   []*FieldHelp `json:"updated"`
+	Unchanged int          `json:"unchanged"`
+	NotFound  []string     `json:"notFound,omitempty"`
+}
+
+// FieldHelp models the help text of a field.
+type FieldHelp struct {
+	FieldID  int    `json:"fieldId"`
+	Label    string `json:"label"`
+	HelpText string `json:"helpText"`
+}
+
+// ReadHelpFile reads and parses a file of help text keyed by field label.
+func ReadHelpFile(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading help file: %w", err)
+	}
+
+	help := map[string]string{}
+	if err := yaml.Unmarshal(b, &help); err != nil {
+		return nil, fmt.Errorf("error parsing help file: %w", err)
+	}
+
+	return help, nil
+}
+
+// FieldSyncHelp updates the help text of the fields in a table from a file
+// keyed by field label. Fields whose help text already matches the file are
+// not updated, and labels that aren't in the table are reported. The changes
+// are reported without updating the fields when --dry-run is passed.
+func FieldSyncHelp(qb *qbclient.Client, cfg GlobalConfig, opts *FieldSyncHelpOptions) (*FieldSyncHelpOutput, error) {
+	output := &FieldSyncHelpOutput{Updated: []*FieldHelp{}}
+
+	help, err := ReadHelpFile(opts.File)
+	if err != nil {
+		return output, err
+	}
+
+	fields, err := GetTableSchema(qb, opts.TableID)
+	if err != nil {
+		return output, fmt.Errorf("error getting table metadata: %w", err)
+	}
+
+	labels := newLabelIndex(fields)
+	for label := range help {
+		fid, err := labels.lookup(label)
+		if err != nil {
+			return output, err
+		}
+		if fid == 0 {
+			output.NotFound = append(output.NotFound, label)
+		}
+	}
+	sort.Strings(output.NotFound)
+
+	for _, f := range sortedFields(fields) {
+		text, ok := help[f.Label]
+		if !ok {
+			continue
+		}
+		if text == f.FieldHelpText {
+			output.Unchanged++
+			continue
+		}
+
+		output.Updated = append(output.Updated, &FieldHelp{FieldID: f.FieldID, Label: f.Label, HelpText: text})
+		if cfg.DryRun() {
+			continue
+		}
+
+		// Searchable and AddToNewReports are always sent, so they are
+		// copied from the field to leave them as-is.
+		_, err := qb.UpdateField(&qbclient.UpdateFieldInput{
+			Field: qbclient.Field{
+				FieldHelpText:   text,
+				Searchable:      f.Searchable,
+				AddToNewReports: f.AddToNewReports,
+			},
+			TableID: opts.TableID,
+			FieldID: f.FieldID,
+		})
+		if err != nil {
+			return output, fmt.Errorf("error updating field %v: %w", f.FieldID, err)
+		}
+	}
+
+	return output, nil
+}
@@ -0,0 +1,68 @@
+package qbcli
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OnUnmapped* constants contain the actions taken on IDs not in the ID map.
+const (
+	OnUnmappedError = "error"
+	OnUnmappedNull  = "null"
+)
+
+// IDMap models the file that translates IDs in a source environment to IDs in
+// a destination environment, e.g., when promoting data from dev to prod.
+//
+// Records are keyed by parent table ID, then by source record ID. Parent
+// tables can be keyed by their source ID if it is mapped to the destination ID
+// in Tables.
+type IDMap struct {
+	Tables  map[string]string            `yaml:"tables,omitempty"`
+	Records map[string]map[string]string `yaml:"records,omitempty"`
+}
+
+// ReadIDMap reads and parses an ID map file.
+func ReadIDMap(path string) (*IDMap, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ID map: %w", err)
+	}
+
+	m := &IDMap{}
+	if err := yaml.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("error parsing ID map: %w", err)
+	}
+
+	return m, nil
+}
+
+// RecordID returns the destination record ID for the source record ID in the
+// passed parent table, which is the table's destination ID.
+func (m *IDMap) RecordID(tableID, id string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+
+	if records, ok := m.Records[tableID]; ok {
+		if dest, ok := records[id]; ok {
+			return dest, true
+		}
+	}
+
+	// Try the source IDs that map to the destination table.
+	for src, dest := range m.Tables {
+		if dest != tableID {
+			continue
+		}
+		if records, ok := m.Records[src]; ok {
+			if dest, ok := records[id]; ok {
+				return dest, true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,513 @@
+package qbcli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// importReader reads rows from CSV data and converts them to records that can
+// be inserted into a Quickbase table.
+type importReader struct {
+	reader *csv.Reader
+	fields FieldMap
+	header []string
+	fids   []int
+	line   int
+	opts   *ImportOptions
+
+	// idmap translates the values of reference fields, and refs maps the
+	// reference fields to their parent table.
+	idmap *IDMap
+	refs  map[int]string
+}
+
+// prepareImport reads the table's schema and returns an *importReader with the
+// header mapped, the merge field resolved, and the ID map loaded.
+func prepareImport(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, file io.Reader, opts *ImportOptions) (*importReader, error) {
+
+	// Get the table's fields.
+	fields, err := GetTableSchema(qb, opts.TableID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting table metadata: %w", err)
+	}
+
+	reader, err := newImportReader(file, fields, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolveMergeField(ctx, logger, qb, opts, reader); err != nil {
+		return nil, err
+	}
+
+	if opts.IDMap != "" {
+		if reader.idmap, err = ReadIDMap(opts.IDMap); err != nil {
+			return nil, err
+		}
+
+		// Reference fields are the foreign keys of the table's relationships.
+		rels, err := qb.ListRelationshipsByTableID(opts.TableID)
+		if err != nil {
+			return nil, fmt.Errorf("error listing relationships: %w", err)
+		}
+		reader.refs = make(map[int]string, len(rels.Relationships))
+		for _, rel := range rels.Relationships {
+			if rel.ForeignKeyField != nil {
+				reader.refs[rel.ForeignKeyField.FieldID] = rel.ParentTableID
+			}
+		}
+	}
+
+	return reader, nil
+}
+
+// openImportFile opens the file configured in opts, falling back to stdin.
+func openImportFile(opts *ImportOptions) (io.ReadCloser, error) {
+	if opts.Filepath != "" {
+		f, err := os.Open(opts.Filepath)
+		if err != nil {
+			return nil, fmt.Errorf("error opening file: %w", err)
+		}
+		return f, nil
+	}
+
+	if err := waitStdin(opts.StdinTimeout); err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(os.Stdin), nil
+}
+
+// countImportRows returns the number of rows in a CSV file, excluding the
+// header.
+func countImportRows(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.ReuseRecord = true
+
+	rows := 0
+	for {
+		if _, err := reader.Read(); err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, fmt.Errorf("error counting rows: %w", err)
+		}
+		rows++
+	}
+
+	if rows > 0 {
+		rows--
+	}
+	return rows, nil
+}
+
+// newImportReader returns an *importReader that reads from file and maps the
+// header to the passed fields.
+func newImportReader(file io.Reader, fields FieldMap, opts *ImportOptions) (*importReader, error) {
+	r := &importReader{
+		reader: csv.NewReader(file),
+		fields: fields,
+		opts:   opts,
+	}
+
+	header, err := r.reader.Read()
+	if err == io.EOF {
+		return r, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading line 0: %w", err)
+	}
+	r.header = header
+
+	// Build an index of field labels to fids.
+	labels := newLabelIndex(fields)
+
+	// Labels passed through --map take precedence over the map file.
+	if opts.MapFile != "" {
+		m, err := ReadMapFile(opts.MapFile)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range opts.Map {
+			m[k] = v
+		}
+		opts.Map = m
+	}
+
+	unmatched := []int{}
+	for idx, label := range header {
+
+		// Check the field label map first. Columns mapped to an empty label
+		// are skipped, which is represented by a fid of 0.
+		if destLabel, ok := opts.Map[label]; ok {
+			if destLabel == "" {
+				r.fids = append(r.fids, 0)
+				continue
+			}
+			label = destLabel
+		}
+
+		// Now get the field ID. Labels are matched first, then field IDs,
+		// so a column can name a field either way.
+		fid, err := labels.lookup(label)
+		if err != nil {
+			return nil, fmt.Errorf("column %q: %w", header[idx], err)
+		}
+		if fid == 0 {
+			if id, err := strconv.Atoi(label); err == nil && fields[id] != nil {
+				fid = id
+			}
+		}
+		if fid == 0 {
+			unmatched = append(unmatched, idx)
+		}
+
+		// Append the fid from the field map.
+		r.fids = append(r.fids, fid)
+	}
+
+	if len(unmatched) == 0 {
+		return r, nil
+	}
+
+	// Prompt for the columns that don't match in a terminal, and error
+	// otherwise.
+	if !canPromptMapping(opts) {
+		labels := make([]string, len(unmatched))
+		for idx, col := range unmatched {
+			labels[idx] = header[col]
+		}
+		return nil, fmt.Errorf("%s: fields not in destination table, map them with --map or --map-file", strings.Join(labels, ", "))
+	}
+
+	fids, err := promptMapping(r, unmatched)
+	if err != nil {
+		return nil, err
+	}
+	for idx, fid := range fids {
+		r.fids[idx] = fid
+	}
+
+	return r, nil
+}
+
+// Read reads the next row, returning the line number of the row. The header
+// is line 0. It returns io.EOF at the end of the data.
+func (r *importReader) Read() (int, []string, error) {
+	if r.header == nil {
+		return r.line, nil, io.EOF
+	}
+
+	r.line++
+	row, err := r.reader.Read()
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("error reading line %v: %w", r.line, err)
+	}
+
+	return r.line, row, err
+}
+
+// Convert converts a row to a record, returning an error for each value that
+// could not be converted to the destination field's type.
+func (r *importReader) Convert(row []string) (map[int]*qbclient.InsertRecordsInputData, []error) {
+	record := make(map[int]*qbclient.InsertRecordsInputData)
+	errs := []error{}
+
+	for idx, data := range row {
+		if idx >= len(r.fids) {
+			errs = append(errs, fmt.Errorf("column %v not in header", idx+1))
+			break
+		}
+		fid := r.fids[idx]
+
+		// We cannot insert record metadata.
+		if fid != 3 && fid <= 5 {
+			continue
+		}
+
+		// Skip the record ID if it isn't the merge field.
+		if fid == 3 && r.opts.MergeFieldID != 3 {
+			continue
+		}
+
+		// Translate the record IDs in reference fields.
+		if parent, ok := r.refs[fid]; ok && data != "" {
+			id, ok := r.idmap.RecordID(parent, data)
+			switch {
+			case ok:
+				data = id
+			case r.opts.OnUnmapped == OnUnmappedNull:
+				data = ""
+			default:
+				errs = append(errs, fmt.Errorf("record ID %s in field %v not in ID map", data, fid))
+				continue
+			}
+		}
+
+		// Create a *qbclient.Value from the string value and field type.
+		val, err := qbclient.NewValueFromString(data, r.fields[fid].Type)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("value invalid for field %v: %w", fid, err))
+			continue
+		}
+
+		// Add the value to the record.
+		record[fid] = &qbclient.InsertRecordsInputData{Value: val}
+	}
+
+	return record, errs
+}
+
+// ValidateImportOutput is the result of validating import data.
+type ValidateImportOutput struct {
+	TotalRows   int                 `json:"totalRows"`
+	ValidRows   int                 `json:"validRows"`
+	InvalidRows int                 `json:"invalidRows"`
+	LineErrors  map[string][]string `json:"lineErrors"`
+}
+
+// ValidateImport runs the import's pre-flight validation without writing any
+// records. Rows are parsed, converted to the destination field types, and
+// checked against the required and unique constraints of the fields. Rows that
+// fail are written to the error file if one is configured.
+func ValidateImport(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, opts *ImportOptions) (*ValidateImportOutput, error) {
+	output := &ValidateImportOutput{LineErrors: map[string][]string{}}
+
+	file, err := openImportFile(opts)
+	if err != nil {
+		return output, err
+	}
+	defer file.Close()
+
+	reader, err := prepareImport(ctx, logger, qb, file, opts)
+	if err != nil {
+		return output, err
+	}
+
+	ef, err := newErrorFile(opts.ErrorFile, reader.header)
+	if err != nil {
+		return output, err
+	}
+	defer ef.Close()
+
+	// Values seen for each unique field.
+	seen := map[int]map[string]int{}
+
+	for {
+		line, row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		output.TotalRows++
+		lerrs := []string{}
+
+		// Rows with the wrong number of columns are reported, other parse
+		// errors leave the reader in an unknown state.
+		if err != nil {
+			if !errors.Is(err, csv.ErrFieldCount) {
+				return output, err
+			}
+			lerrs = append(lerrs, err.Error())
+		} else {
+			_, errs := reader.Convert(row)
+			for _, cerr := range errs {
+				lerrs = append(lerrs, cerr.Error())
+			}
+
+			// Check the field constraints.
+			for idx, data := range row {
+				if idx >= len(reader.fids) {
+					break
+				}
+				fid := reader.fids[idx]
+				if fid == 0 {
+					continue
+				}
+				field := reader.fields[fid]
+
+				if field.Required && strings.TrimSpace(data) == "" {
+					lerrs = append(lerrs, fmt.Sprintf("value required for field %v", fid))
+				}
+
+				if field.Unique && data != "" {
+					if _, ok := seen[fid]; !ok {
+						seen[fid] = map[string]int{}
+					}
+					if prev, ok := seen[fid][data]; ok {
+						lerrs = append(lerrs, fmt.Sprintf("value for field %v duplicates line %v", fid, prev))
+					} else {
+						seen[fid][data] = line
+					}
+				}
+			}
+		}
+
+		if len(lerrs) == 0 {
+			output.ValidRows++
+			continue
+		}
+
+		output.InvalidRows++
+		output.LineErrors[strconv.Itoa(line)] = lerrs
+		if err := ef.Write(line, lerrs, row); err != nil {
+			return output, err
+		}
+	}
+
+	if err := writeErrorSummary(opts.ErrorSummary, output.LineErrors); err != nil {
+		return output, err
+	}
+
+	ctx = cliutil.ContextWithLogTag(ctx, "valid", strconv.Itoa(output.ValidRows))
+	ctx = cliutil.ContextWithLogTag(ctx, "invalid", strconv.Itoa(output.InvalidRows))
+	logger.Info(ctx, "import data validated")
+
+	return output, nil
+}
+
+// errorFile writes rows that failed to a CSV file. The first two columns are
+// the line number and the errors, followed by the row as it was read.
+type errorFile struct {
+	file   *os.File
+	writer *csv.Writer
+}
+
+// newErrorFile creates the error file at path. A nil *errorFile is returned if
+// path is empty, and writing to it is a no-op.
+func newErrorFile(path string, header []string) (*errorFile, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening error file: %w", err)
+	}
+
+	ef := &errorFile{file: f, writer: csv.NewWriter(f)}
+	ef.writer.UseCRLF = _crlf
+
+	if err := ef.writer.Write(append([]string{"Line", "Error"}, header...)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error writing error file: %w", err)
+	}
+
+	return ef, nil
+}
+
+// Write writes a failed row to the error file.
+func (ef *errorFile) Write(line int, errs []string, row []string) error {
+	if ef == nil {
+		return nil
+	}
+
+	rec := append([]string{strconv.Itoa(line), strings.Join(errs, "; ")}, row...)
+	if err := ef.writer.Write(rec); err != nil {
+		return fmt.Errorf("error writing error file: %w", err)
+	}
+
+	return nil
+}
+
+// Close flushes the buffer and closes the error file.
+func (ef *errorFile) Close() error {
+	if ef == nil {
+		return nil
+	}
+
+	ef.writer.Flush()
+	if err := ef.writer.Error(); err != nil {
+		ef.file.Close()
+		return fmt.Errorf("error writing error file: %w", err)
+	}
+
+	return ef.file.Close()
+}
+
+// errorSummaryExamples is the maximum number of example lines for each error.
+const errorSummaryExamples = 5
+
+// ErrorSummary groups line errors by message.
+type ErrorSummary struct {
+	TotalLines int                 `json:"totalLines"`
+	Errors     []*ErrorSummaryItem `json:"errors"`
+}
+
+// ErrorSummaryItem models an error message and the lines it occurred on.
+type ErrorSummaryItem struct {
+	Message      string `json:"message"`
+	Count        int    `json:"count"`
+	ExampleLines []int  `json:"exampleLines"`
+}
+
+// SummarizeLineErrors groups line errors by message, sorted by the number of
+// times each message occurred.
+func SummarizeLineErrors(lineErrors map[string][]string) *ErrorSummary {
+	summary := &ErrorSummary{TotalLines: len(lineErrors), Errors: []*ErrorSummaryItem{}}
+
+	// Sort the lines so the examples are the first lines with the error.
+	lines := make([]int, 0, len(lineErrors))
+	for k := range lineErrors {
+		if line, err := strconv.Atoi(k); err == nil {
+			lines = append(lines, line)
+		}
+	}
+	sort.Ints(lines)
+
+	items := map[string]*ErrorSummaryItem{}
+	for _, line := range lines {
+		for _, msg := range lineErrors[strconv.Itoa(line)] {
+			item, ok := items[msg]
+			if !ok {
+				item = &ErrorSummaryItem{Message: msg, ExampleLines: []int{}}
+				items[msg] = item
+				summary.Errors = append(summary.Errors, item)
+			}
+			item.Count++
+			if len(item.ExampleLines) < errorSummaryExamples {
+				item.ExampleLines = append(item.ExampleLines, line)
+			}
+		}
+	}
+
+	sort.SliceStable(summary.Errors, func(i, j int) bool {
+		return summary.Errors[i].Count > summary.Errors[j].Count
+	})
+
+	return summary
+}
+
+// writeErrorSummary writes the summary of the line errors to path as JSON. It
+// is a no-op if path is empty.
+func writeErrorSummary(path string, lineErrors map[string][]string) error {
+	if path == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(SummarizeLineErrors(lineErrors), "", "    ")
+	if err != nil {
+		return fmt.Errorf("error encoding error summary: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing error summary: %w", err)
+	}
+
+	return nil
+}
@@ -2,11 +2,15 @@ package qbcli
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
 )
 
 // Prompt prompts a user for input and returns what they typed.
@@ -36,3 +40,99 @@ func Prompt(label string, validate qbclient.ValidateStringFn) (s string, err err
 
 	return
 }
+
+// Confirm prompts a user to confirm an action, returning true if they typed
+// "y" or "yes".
+func Confirm(label string) (bool, error) {
+	s, err := Prompt(label+" [y/N]: ", qbclient.NoValidation)
+	if err != nil {
+		return false, err
+	}
+
+	s = strings.ToLower(s)
+	return s == "y" || s == "yes", nil
+}
+
+// ConfirmCount prompts a user to confirm a mutation that affects count
+// records. Mutations affecting more records than the configured threshold
+// always require an interactive confirmation unless --force is passed, even if
+// yes is true. Otherwise the prompt is skipped if yes is true. Nothing is
+// changed in dry-run mode, so the prompt is always skipped.
+func ConfirmCount(cfg GlobalConfig, label string, count int, yes bool) (bool, error) {
+	if cfg.DryRun() {
+		return true, nil
+	}
+
+	if threshold := cfg.ConfirmCountThreshold(); threshold > 0 && count > threshold {
+		if cfg.Force() {
+			return true, nil
+		}
+		if !isTerminal(os.Stdin) {
+			return false, NotConfirmedError("%v records exceeds the confirm-count-threshold of %v, pass --force to proceed", count, threshold)
+		}
+		return Confirm(fmt.Sprintf("%s This affects %v records, which exceeds the threshold of %v.", label, count, threshold))
+	}
+
+	if yes {
+		return true, nil
+	}
+	return Confirm(label)
+}
+
+// isTerminal returns true if f is a terminal.
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// isTerminalWriter returns true if w is stdout or stderr, including stdout
+// returned by OpenOutput, and is a terminal.
+func isTerminalWriter(w io.Writer) bool {
+	if nc, ok := w.(nopCloser); ok {
+		w = nc.Writer
+	}
+	f, ok := w.(*os.File)
+	return ok && (f == os.Stdout || f == os.Stderr) && isTerminal(f)
+}
+
+// ConfirmDeleteRecords counts the records matched by a delete, logs the
+// count, and prompts for confirmation unless yes or --force is passed.
+// Deletes affecting more records than the configured threshold still
+// require confirmation as described by ConfirmCount. The prompt can't be
+// answered in --quiet mode or when stdin isn't a terminal, so the delete
+// fails unless yes or --force is passed. False is returned without an error
+// if no records match.
+func ConfirmDeleteRecords(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, input *qbclient.DeleteRecordsInput, yes bool) (bool, error) {
+	matched, err := countRecords(qb, input.From, input.Where)
+	if err != nil {
+		return false, err
+	}
+
+	logger.Notice(cliutil.ContextWithLogTag(ctx, "matched", strconv.Itoa(matched)), "records matched")
+	if matched == 0 {
+		return false, nil
+	}
+
+	yes = yes || cfg.Force()
+	if !yes && !cfg.DryRun() && (cfg.Quiet() || !isTerminal(os.Stdin)) {
+		return false, NotConfirmedError("deleting %v records requires confirmation, pass --yes to proceed", matched)
+	}
+
+	label := fmt.Sprintf("Delete %v records from table %s?", matched, input.From)
+	return ConfirmCount(cfg, label, matched, yes)
+}
+
+// countRecords returns the number of records in a table that match the query,
+// which is read from the metadata of a single-record page.
+func countRecords(qb *qbclient.Client, tableID, where string) (int, error) {
+	qro, err := qb.QueryRecords(&qbclient.QueryRecordsInput{
+		Select:  []int{3},
+		From:    tableID,
+		Where:   where,
+		Options: &qbclient.QueryRecordsInputOptions{Top: 1},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error counting records: %w", err)
+	}
+	return qro.Metadata.TotalRecords, nil
+}
@@ -0,0 +1,36 @@
+package qbcli
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// InsertCSVOptions are the options of records insert that read the records
+// from a CSV file instead of --data.
+type InsertCSVOptions struct {
+	CSVFile string            `cliutil:"option=csv-file usage='CSV file the records are read from, with a header row of field labels or IDs'"`
+	Mapping map[string]string `cliutil:"option=mapping usage='maps CSV column names to field labels or IDs'"`
+}
+
+// InsertCSV inserts the records in opts.CSVFile into a table, merging them on
+// mergeFieldID if it isn't 0. It uses the same machinery as Import, so the
+// rows are streamed in batches, and rows that fail are reported under their
+// row number, where the header is row 0.
+func InsertCSV(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, tableID string, mergeFieldID int, opts *InsertCSVOptions) (*ImportOutput, error) {
+	mergeField := ""
+	if mergeFieldID != 0 {
+		mergeField = strconv.Itoa(mergeFieldID)
+	}
+
+	return Import(ctx, logger, qb, cfg, &ImportOptions{
+		TableID:    tableID,
+		Filepath:   opts.CSVFile,
+		BatchSize:  DefaultInsertBatchSize,
+		Map:        opts.Mapping,
+		MergeField: mergeField,
+		OnUnmapped: "error",
+	})
+}
@@ -0,0 +1,49 @@
+package qbcli
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qberrors"
+)
+
+// _strictFIDs is set through --strict-fids and makes labels shared by more
+// than one field an error instead of resolving to the lowest field ID.
+var _strictFIDs bool
+
+// labelIndex maps field labels to the IDs of the fields that have them, in
+// order of field ID.
+type labelIndex map[string][]int
+
+func newLabelIndex(fields FieldMap) labelIndex {
+	idx := labelIndex{}
+	for _, f := range sortedFields(fields) {
+		idx[f.Label] = append(idx[f.Label], f.FieldID)
+	}
+	return idx
+}
+
+// lookup returns the ID of the field with the label, or 0 if no field has it.
+// A label shared by more than one field resolves to the lowest field ID, or
+// returns an error if --strict-fids is set.
+func (idx labelIndex) lookup(label string) (int, error) {
+	fids := idx[label]
+	switch {
+	case len(fids) == 0:
+		return 0, nil
+	case len(fids) > 1 && _strictFIDs:
+		return 0, ambiguousLabelError(label, fids)
+	default:
+		return fids[0], nil
+	}
+}
+
+// ambiguousLabelError returns the error for a label that matches more than
+// one field in strict mode.
+func ambiguousLabelError(label string, fids []int) error {
+	ids := make([]string, len(fids))
+	for i, fid := range fids {
+		ids[i] = strconv.Itoa(fid)
+	}
+	return qberrors.Client(nil).Safef(qberrors.InvalidInput, "label %q matches fields %s, pass a field ID instead", label, strings.Join(ids, ", "))
+}
@@ -0,0 +1,147 @@
+package qbcli
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Date layouts used by locales, keyed by the region they are used in. Regions
+// that aren't listed use DateLayoutDayMonthYear.
+const (
+	DateLayoutMonthDayYear = "01/02/2006"
+	DateLayoutDayMonthYear = "02/01/2006"
+	DateLayoutDotted       = "02.01.2006"
+	DateLayoutDashed       = "02-01-2006"
+	DateLayoutISO          = "2006-01-02"
+)
+
+var _regionDateLayouts = map[string]string{
+	"US": DateLayoutMonthDayYear,
+	"PH": DateLayoutMonthDayYear,
+	"AT": DateLayoutDotted,
+	"CH": DateLayoutDotted,
+	"CZ": DateLayoutDotted,
+	"DE": DateLayoutDotted,
+	"DK": DateLayoutDotted,
+	"FI": DateLayoutDotted,
+	"NO": DateLayoutDotted,
+	"PL": DateLayoutDotted,
+	"RU": DateLayoutDotted,
+	"SK": DateLayoutDotted,
+	"TR": DateLayoutDotted,
+	"UA": DateLayoutDotted,
+	"NL": DateLayoutDashed,
+	"CA": DateLayoutISO,
+	"CN": DateLayoutISO,
+	"JP": DateLayoutISO,
+	"KR": DateLayoutISO,
+	"LT": DateLayoutISO,
+	"SE": DateLayoutISO,
+	"TW": DateLayoutISO,
+}
+
+// Locale formats numbers and dates in table, csv, and xlsx output according
+// to a BCP 47 language tag.
+type Locale struct {
+	tag     language.Tag
+	printer *message.Printer
+
+	// DateLayout is the time.Format layout of dates.
+	DateLayout string
+}
+
+// ParseLocale parses a BCP 47 language tag, e.g., de-DE. The region is
+// inferred from the language if it isn't passed, e.g., "de" uses the date
+// layout of Germany.
+func ParseLocale(s string) (*Locale, error) {
+	tag, err := language.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("value %q for option %q: %w", s, OptionLocale, err)
+	}
+
+	layout := DateLayoutDayMonthYear
+	if region, _ := tag.Region(); region.String() != "ZZ" {
+		if l, ok := _regionDateLayouts[region.String()]; ok {
+			layout = l
+		}
+	}
+
+	return &Locale{tag: tag, printer: message.NewPrinter(tag), DateLayout: layout}, nil
+}
+
+// ParsedLocale returns the Locale that output is formatted for, or nil if no
+// locale was passed. The tag is validated by Validate.
+func (c GlobalConfig) ParsedLocale() *Locale {
+	if s := c.Locale(); s != "" {
+		if l, err := ParseLocale(s); err == nil {
+			return l
+		}
+	}
+	return nil
+}
+
+// String returns the language tag.
+func (l *Locale) String() string { return l.tag.String() }
+
+// Number formats f with the locale's grouping and decimal separators.
+func (l *Locale) Number(f float64, decimals int) string {
+	if decimals < 0 {
+		return l.printer.Sprint(number.Decimal(f, number.MaxFractionDigits(15)))
+	}
+	return l.printer.Sprint(number.Decimal(f, number.MinFractionDigits(decimals), number.MaxFractionDigits(decimals)))
+}
+
+// FormatValue renders a value like formatValue, except that numbers are
+// grouped with the locale's separators and dates use the locale's layout.
+// Currency values keep the $ symbol, since the field's currency isn't known.
+func (l *Locale) FormatValue(v *qbclient.Value, ftype string) string {
+	switch ftype {
+	case qbclient.FieldNumeric, qbclient.FieldNumericRating:
+		return l.Number(v.Float64, -1)
+
+	case qbclient.FieldNumericCurrency:
+		s := "$" + l.Number(math.Abs(v.Float64), 2)
+		if v.Float64 < 0 {
+			s = "-" + s
+		}
+		return s
+
+	case qbclient.FieldNumericPercent:
+		pct := math.Round(v.Float64*100*100) / 100
+		return l.Number(pct, -1) + "%"
+
+	case qbclient.FieldDate:
+		return v.Time.UTC().Format(l.DateLayout)
+
+	case qbclient.FieldDateTime:
+		return v.Time.UTC().Format(l.DateLayout + " 15:04")
+
+	case qbclient.FieldDuration:
+		return v.Duration.Round(time.Second).String()
+
+	default:
+		return formatValue(v, ftype)
+	}
+}
+
+// xlsxDateFormat returns the Excel format code of the locale's date layout.
+func (l *Locale) xlsxDateFormat() string {
+	switch l.DateLayout {
+	case DateLayoutMonthDayYear:
+		return "mm/dd/yyyy"
+	case DateLayoutDotted:
+		return "dd.mm.yyyy"
+	case DateLayoutDashed:
+		return "dd-mm-yyyy"
+	case DateLayoutISO:
+		return "yyyy-mm-dd"
+	default:
+		return "dd/mm/yyyy"
+	}
+}
@@ -0,0 +1,112 @@
+package qbcli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// LogFormat* constants contain the formats of the messages written to the log
+// file.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// textMessageWriter is a cliutil.MessageWriter that writes the messages with
+// cliutil.DefaultMessageWriter, masking tokens in the message, the error, and
+// the log tags. The level is colored when writing to a terminal.
+func textMessageWriter(ctx context.Context, logger *log.Logger, level, message string, err error) {
+	if tags, ok := ctx.Value(cliutil.CtxLogTags).(string); ok {
+		ctx = context.WithValue(ctx, cliutil.CtxLogTags, maskLog(tags))
+	}
+	if err != nil {
+		err = errors.New(maskLog(err.Error()))
+	}
+	cliutil.DefaultMessageWriter(ctx, logger, colorLevel(logger.Writer(), level), maskLog(message), err)
+}
+
+// jsonMessageWriter is a cliutil.MessageWriter that writes each message as a
+// JSON object on its own line. The log tags are written as properties next to
+// the time, level, message, and error, which take precedence over tags of the
+// same name. Tokens are masked as by textMessageWriter. The logger's flags
+// must be 0, since the time is written in the object.
+func jsonMessageWriter(ctx context.Context, logger *log.Logger, level, message string, err error) {
+	entry := map[string]string{}
+	if tags, ok := ctx.Value(cliutil.CtxLogTags).(string); ok {
+		for _, tag := range parseLogTags(tags) {
+			entry[tag[0]] = maskLog(tag[1])
+		}
+	}
+
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level
+	entry["message"] = maskLog(message)
+	if err != nil {
+		entry["error"] = maskLog(err.Error())
+	}
+
+	b, _ := json.Marshal(entry)
+	logger.Print(string(b))
+}
+
+// maskLog masks the user tokens and temporary tokens in s.
+func maskLog(s string) string {
+	return string(qbclient.MaskUserToken([]byte(s)))
+}
+
+// parseLogTags parses the tags added by cliutil.ContextWithLogTag into key and
+// value pairs. Tags are separated by spaces, and values containing spaces are
+// quoted. A value that starts with a quote but isn't followed by a space or
+// the end once unquoted, e.g., "7".value, was written as-is.
+func parseLogTags(s string) (tags [][2]string) {
+	for s != "" {
+		idx := strings.IndexByte(s, '=')
+		if idx < 0 {
+			return
+		}
+		key := s[:idx]
+		s = s[idx+1:]
+
+		var value string
+		if end := quotedEnd(s); end > 0 && (end == len(s) || s[end] == ' ') {
+			value, _ = strconv.Unquote(s[:end])
+			s = s[end:]
+		} else if end := strings.IndexByte(s, ' '); end >= 0 {
+			value, s = s[:end], s[end:]
+		} else {
+			value, s = s, ""
+		}
+
+		tags = append(tags, [2]string{key, value})
+		s = strings.TrimLeft(s, " ")
+	}
+	return
+}
+
+// quotedEnd returns the index after the closing quote of the quoted string s
+// starts with, or 0 if s doesn't start with a valid quoted string.
+func quotedEnd(s string) int {
+	if !strings.HasPrefix(s, `"`) {
+		return 0
+	}
+	for idx := 1; idx < len(s); idx++ {
+		switch s[idx] {
+		case '\\':
+			idx++
+		case '"':
+			if _, err := strconv.Unquote(s[:idx+1]); err != nil {
+				return 0
+			}
+			return idx + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+package qbcli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"gopkg.in/yaml.v3"
+)
+
+// ReadMapFile reads and parses a YAML file that maps CSV header labels to
+// destination field labels. An empty destination label skips the column.
+func ReadMapFile(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading map file: %w", err)
+	}
+
+	m := map[string]string{}
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("error parsing map file: %w", err)
+	}
+
+	return m, nil
+}
+
+// WriteMapFile writes the mapping of CSV header labels to destination field
+// labels to a YAML file that can be passed to --map-file.
+func WriteMapFile(path string, m map[string]string) error {
+	b, err := yaml.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("error encoding map file: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return fmt.Errorf("error writing map file: %w", err)
+	}
+
+	return nil
+}
+
+// canPromptMapping returns whether unmatched columns can be mapped
+// interactively, which requires a terminal and data that isn't read from
+// stdin.
+func canPromptMapping(opts *ImportOptions) bool {
+	return opts.Filepath != "" && isTerminal(os.Stdin) && isTerminal(os.Stdout)
+}
+
+// promptMapping prompts the user to map each unmatched column to a field by
+// label or ID, or to skip it. The choices are added to opts.Map, and the
+// mapping is optionally saved to a file for reuse with --map-file. The field
+// IDs of the columns are returned, where 0 skips the column.
+func promptMapping(r *importReader, unmatched []int) (map[int]int, error) {
+	fmt.Printf("%v columns do not match a field in table %s. Fields:\n\n", len(unmatched), r.opts.TableID)
+	for _, f := range sortedFields(r.fields) {
+		fmt.Printf("  %5v  %s\n", f.FieldID, f.Label)
+	}
+	fmt.Println()
+
+	if r.opts.Map == nil {
+		r.opts.Map = map[string]string{}
+	}
+
+	labels := newLabelIndex(r.fields)
+	fids := make(map[int]int, len(unmatched))
+	for _, idx := range unmatched {
+		label := r.header[idx]
+
+		var fid int
+		validate := func(s string) error {
+			fid = 0
+			if s == "" {
+				return nil
+			}
+			if id, err := strconv.Atoi(s); err == nil {
+				if _, ok := r.fields[id]; ok {
+					fid = id
+					return nil
+				}
+			}
+			matches := []int{}
+			for _, f := range sortedFields(r.fields) {
+				if strings.EqualFold(f.Label, s) {
+					matches = append(matches, f.FieldID)
+				}
+			}
+			switch {
+			case len(matches) == 0:
+				return fmt.Errorf("%s: field not in table", s)
+			case len(matches) > 1 && _strictFIDs:
+				return ambiguousLabelError(s, matches)
+			}
+			fid = matches[0]
+			return nil
+		}
+
+		prompt := fmt.Sprintf("Map column %q to field (label or ID, empty to skip): ", label)
+		if _, err := Prompt(prompt, validate); err != nil {
+			return nil, err
+		}
+
+		// Fields that share their label with another field are saved by ID,
+		// so the mapping resolves the same way when it's reused.
+		fids[idx] = fid
+		switch {
+		case fid == 0:
+			r.opts.Map[label] = ""
+		case len(labels[r.fields[fid].Label]) > 1:
+			r.opts.Map[label] = strconv.Itoa(fid)
+		default:
+			r.opts.Map[label] = r.fields[fid].Label
+		}
+	}
+
+	path, err := Prompt("Save the mapping to a file for --map-file (empty to skip): ", qbclient.NoValidation)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if err := WriteMapFile(path, r.opts.Map); err != nil {
+			return nil, err
+		}
+	}
+
+	return fids, nil
+}
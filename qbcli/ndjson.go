@@ -0,0 +1,247 @@
+package qbcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+	"github.com/jmespath/go-jmespath"
+)
+
+// _streamed is set once records have been streamed to the output by an
+// NDJSONWriter, so Render doesn't write them again.
+var _streamed bool
+
+// NDJSONWriter writes records as newline-delimited JSON, one object per line,
+// as they are read. The JMESPath filters are compiled once and apply to the
+// array of records, i.e., data in the JSON output. Filters that project over
+// the array, e.g., [?"7".value=='Open'] or [].{id: "3".value}, are evaluated
+// against each record as it is written, so memory stays bounded. Any other
+// filter, e.g., one that sorts the records, needs the whole array, so the
+// records are buffered and the filter is evaluated on Close.
+type NDJSONWriter struct {
+	w       io.Writer
+	c       io.Closer
+	filters []string
+	exprs   []*jmespath.JMESPath
+	unwrap  bool
+	stream  bool
+	eol     []byte
+
+	buffered []interface{}
+}
+
+// NewNDJSONWriter returns an NDJSONWriter that writes to w with the filters,
+// value unwrapping, and line endings configured in cfg. Filters that don't
+// compile are reported before anything is written.
+func NewNDJSONWriter(w io.Writer, cfg GlobalConfig) (*NDJSONWriter, error) {
+	filters := cfg.JMESPathFilters()
+	nw := &NDJSONWriter{w: w, filters: filters, unwrap: cfg.UnwrapValues(), stream: true, eol: []byte(withLineEnding("\n", cfg))}
+
+	nw.exprs = make([]*jmespath.JMESPath, len(filters))
+	for idx, filter := range filters {
+		expr, err := jmespath.Compile(filter)
+		if err != nil {
+			return nil, qberrors.Client(err).Safef(qberrors.InvalidSyntax, "%s does not compile", filterName(filters, idx))
+		}
+		nw.exprs[idx] = expr
+		nw.stream = nw.stream && streamableFilter(filter)
+	}
+
+	return nw, nil
+}
+
+// OpenNDJSON returns an NDJSONWriter that writes to the output opened by
+// OpenOutput, or discards the records if stdout is suppressed by --quiet. The
+// records are marked as streamed, so Render only logs the command's result.
+// The output is closed by Close.
+func OpenNDJSON(cfg GlobalConfig) (*NDJSONWriter, error) {
+	var w io.WriteCloser = nopCloser{ioutil.Discard}
+	if !cfg.Quiet() || cfg.OutputFile() != "" {
+		var err error
+		if w, err = OpenOutput(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	nw, err := NewNDJSONWriter(w, cfg)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	nw.c = w
+
+	_streamed = true
+	return nw, nil
+}
+
+// WriteRecords writes the records, or buffers them if a filter needs the
+// whole array.
+func (nw *NDJSONWriter) WriteRecords(data []map[int]*qbclient.RecordsData) error {
+	for _, record := range data {
+
+		// Records are written as-is unless they have to be transformed.
+		if len(nw.exprs) == 0 && !nw.unwrap {
+			if err := nw.writeLine(record); err != nil {
+				return err
+			}
+			continue
+		}
+
+		v, err := nw.decode(record)
+		if err != nil {
+			return err
+		}
+
+		if !nw.stream {
+			nw.buffered = append(nw.buffered, v)
+			continue
+		}
+		if err := nw.writeFiltered([]interface{}{v}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close evaluates the filters against the buffered records and writes the
+// result, then closes the output opened by OpenNDJSON. Nothing is buffered
+// when the filters are evaluated per record.
+func (nw *NDJSONWriter) Close() (err error) {
+	if !nw.stream && len(nw.exprs) > 0 {
+		v := nw.buffered
+		nw.buffered = nil
+		if v == nil {
+			v = []interface{}{}
+		}
+		err = nw.writeFiltered(v)
+	}
+
+	if nw.c != nil {
+		if cerr := nw.c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return
+}
+
+// decode converts a record to the value its JSON encoding decodes to, so the
+// filters see the JSON property names, and unwraps its values if configured.
+func (nw *NDJSONWriter) decode(record interface{}) (interface{}, error) {
+	if nw.unwrap {
+		return UnwrapValues(record)
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
+
+// writeFiltered applies the filters to v and writes the result, one line per
+// element if it is an array.
+func (nw *NDJSONWriter) writeFiltered(v interface{}) error {
+	for idx, expr := range nw.exprs {
+		var err error
+		if v, err = expr.Search(v); err != nil {
+			return qberrors.Client(err).Safef(qberrors.InvalidSyntax, "%s failed to evaluate", filterName(nw.filters, idx))
+		}
+	}
+	return nw.writeValue(v)
+}
+
+// writeValue writes each element of v on its own line if v is an array or a
+// slice, or v on a single line otherwise.
+func (nw *NDJSONWriter) writeValue(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nw.writeLine(v)
+	}
+	for idx := 0; idx < rv.Len(); idx++ {
+		if err := nw.writeLine(rv.Index(idx).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLine writes v as compact JSON followed by the line terminator.
+func (nw *NDJSONWriter) writeLine(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error rendering ndjson: %w", err)
+	}
+	_, err = nw.w.Write(append(b, nw.eol...))
+	return err
+}
+
+// writeNDJSON writes v as newline-delimited JSON. The records of outputs
+// that embed them are written one per line, with the filters applied as by
+// NDJSONWriter. Any other output is filtered like JSON output and written one
+// line per element if the result is an array, or on a single line otherwise.
+func writeNDJSON(w io.Writer, v, jv interface{}, cfg GlobalConfig) error {
+	nw, err := NewNDJSONWriter(w, cfg)
+	if err != nil {
+		return err
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct {
+		if r, ok := embeddedRecords(v); ok {
+			if err := nw.WriteRecords(r.Data); err != nil {
+				return err
+			}
+			return nw.Close()
+		}
+	}
+
+	fv, err := filterOutput(jv, nw.filters)
+	if err != nil {
+		return err
+	}
+	return nw.writeValue(fv)
+}
+
+// streamableFilter returns whether the filter projects over the array it is
+// evaluated against, i.e., it starts with [?...], [*], or [], and nothing at
+// its top level ends the projection, such as a pipe, a comparison, or a
+// logical operator. Evaluating such a filter against each element and
+// concatenating the results is the same as evaluating it against the array.
+func streamableFilter(filter string) bool {
+	filter = strings.TrimSpace(filter)
+	if !strings.HasPrefix(filter, "[?") && !strings.HasPrefix(filter, "[*]") && !strings.HasPrefix(filter, "[]") {
+		return false
+	}
+
+	depth := 0
+	var quote rune
+	escaped := false
+	for _, r := range filter {
+		switch {
+		case quote != 0:
+			if escaped {
+				escaped = false
+			} else if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '[' || r == '(' || r == '{':
+			depth++
+		case r == ']' || r == ')' || r == '}':
+			depth--
+		case depth == 0 && strings.ContainsRune("|&<>=!", r):
+			return false
+		}
+	}
+	return quote == 0
+}
@@ -33,7 +33,10 @@ func (opt *QueryOption) Set(f *cliutil.Flagger) error {
 
 // Read implements cliutil.OptionType.Read.
 func (opt *QueryOption) Read(cfg *viper.Viper, field reflect.Value) error {
-	s := ParseQuery(cfg.GetString(opt.tag["option"]))
+	s, err := ParseQuery(cfg.GetString(opt.tag["option"]))
+	if err != nil {
+		return err
+	}
 	field.SetString(s)
 	return nil
 }
@@ -156,11 +159,32 @@ func (opt *RecordOption) Read(cfg *viper.Viper, field reflect.Value) error {
 	return nil
 }
 
-// GetOptions gets options based on the input and validates them.
+// _noValidate is set through --no-validate and makes GetOptions log
+// validation errors instead of exiting.
+var _noValidate bool
+
+// GetOptions gets options based on the input and validates them. Validation
+// errors are logged and ignored if --no-validate was passed.
 func GetOptions(ctx context.Context, logger *cliutil.LeveledLogger, input interface{}, cfg *viper.Viper) {
-	err := cliutil.ReadOptions(input, cfg)
-	logger.FatalIfError(ctx, "error getting options", err)
+	if err := cliutil.ReadOptions(input, cfg); err != nil {
+		exitError(ctx, logger, "error getting options", err, err, ExitValidation)
+	}
+
+	err := ValidateOptions(input)
+	if err != nil {
+		if _noValidate {
+			logger.Notice(cliutil.ContextWithLogTag(ctx, "errors", err.Error()), "input not valid, sending the request anyway")
+			return
+		}
+		exitError(ctx, logger, "input not valid", err, err, ExitValidation)
+	}
+}
 
+// ValidateOptions validates the input and returns an error that joins the
+// validation errors, or nil if the input is valid. The errors of the required,
+// required_if, and min validators are translated to reference the options
+// instead of the struct fields.
+func ValidateOptions(input interface{}) error {
 	validate := validator.New()
 	english := en.New()
 	uni := ut.New(english, english)
@@ -171,30 +195,116 @@ func GetOptions(ctx context.Context, logger *cliutil.LeveledLogger, input interf
 	validate.RegisterTranslation("required", trans, func(ut ut.Translator) error {
 		return ut.Add("required", "{0} option is required", true)
 	}, func(ut ut.Translator, fe validator.FieldError) string {
-		// TODO We should be defensive, even if the error conditions shouldn't happen.
-		field, _ := reflect.ValueOf(input).Elem().Type().FieldByName(fe.Field())
-		tag := cliutil.ParseKeyValue(field.Tag.Get("cliutil"))
-		t, _ := ut.T("required", tag["option"])
+		t, _ := ut.T("required", optionName(input, fe.StructNamespace()))
 		return t
 	})
 
-	// Other validators we need to translate:
-	//
-	// - required_if (See Field.Label)
-	// - min (See DeleteFieldsInput.FieldID)
+	// Custom translation for the "required_if" validator, e.g., Field.Label,
+	// which names the option that triggers the requirement. Fields that
+	// aren't set through an option, e.g., Field.Create, are set by the
+	// command, so the error reads like the "required" one.
+	validate.RegisterTranslation("required_if", trans, func(ut ut.Translator) error {
+		return ut.Add("required_if", "{0} option is required when the {1} option is {2}", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		option := optionName(input, fe.StructNamespace())
+		params := strings.Fields(fe.Param())
+		if len(params) >= 2 {
+			parent := strings.TrimSuffix(fe.StructNamespace(), fe.StructField())
+			if trigger, ok := optionTag(input, parent+params[0]); ok {
+				t, _ := ut.T("required_if", option, trigger, params[1])
+				return t
+			}
+		}
+		t, _ := ut.T("required", option)
+		return t
+	})
 
-	msgs := []string{}
-	verr := validate.Struct(input)
-	if verr != nil {
-		verrs := verr.(validator.ValidationErrors)
-		for _, ve := range verrs {
-			msgs = append(msgs, ve.Translate(trans))
+	// Custom translation for the "min" validator, e.g., for the number of
+	// IDs in DeleteFieldsInput.FieldIDs or the value of a batch size.
+	validate.RegisterTranslation("min", trans, func(ut ut.Translator) error {
+		if err := ut.Add("min-items", "{0} option requires at least {1} values", true); err != nil {
+			return err
 		}
+		if err := ut.Add("min-item", "{0} option requires at least {1} value", true); err != nil {
+			return err
+		}
+		if err := ut.Add("min-string", "{0} option must be at least {1} characters long", true); err != nil {
+			return err
+		}
+		return ut.Add("min-number", "{0} option must be at least {1}", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		key := "min-number"
+		switch fe.Kind() {
+		case reflect.Slice, reflect.Array, reflect.Map:
+			key = "min-items"
+			if fe.Param() == "1" {
+				key = "min-item"
+			}
+		case reflect.String:
+			key = "min-string"
+		}
+		t, _ := ut.T(key, optionName(input, fe.StructNamespace()), fe.Param())
+		return t
+	})
+
+	verr := validate.Struct(input)
+	if verr == nil {
+		return nil
+	}
+
+	verrs, ok := verr.(validator.ValidationErrors)
+	if !ok {
+		return verr
+	}
+
+	msgs := make([]string, len(verrs))
+	for idx, ve := range verrs {
+		msgs[idx] = ve.Translate(trans)
+	}
+	return errors.New(strings.Join(msgs, ", "))
+}
+
+// optionName returns the option of the struct field at the namespace, or the
+// field's name if it isn't set through an option.
+func optionName(input interface{}, namespace string) string {
+	if option, ok := optionTag(input, namespace); ok {
+		return option
 	}
+	return namespace[strings.LastIndexByte(namespace, '.')+1:]
+}
+
+// optionTag returns the option in the cliutil tag of the struct field at the
+// namespace, e.g., CreateFieldInput.Field.Label, which starts with the name of
+// the input's type. Fields of embedded structs, pointers, and slices are
+// followed, and indexes such as Data[0] are ignored.
+func optionTag(input interface{}, namespace string) (string, bool) {
+	t := reflect.TypeOf(input)
+	names := strings.Split(namespace, ".")
+	if len(names) < 2 {
+		return "", false
+	}
+
+	var field reflect.StructField
+	for _, name := range names[1:] {
+		if idx := strings.IndexByte(name, '['); idx >= 0 {
+			name = name[:idx]
+		}
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return "", false
+		}
 
-	if len(msgs) > 0 {
-		HandleError(ctx, logger, "input not valid", errors.New(strings.Join(msgs, ", ")))
+		var ok bool
+		if field, ok = t.FieldByName(name); !ok {
+			return "", false
+		}
+		t = field.Type
 	}
+
+	option := cliutil.ParseKeyValue(field.Tag.Get("cliutil"))["option"]
+	return option, option != ""
 }
 
 func init() {
@@ -204,7 +314,6 @@ func init() {
 	cliutil.RegisterOptionTypeFunc("group", NewGroupOption)
 
 	cliutil.SetOptionMetadata("app-id", map[string]string{"usage": "the app's unique identifier, e.g., bqgruir3g"})
-	cliutil.SetOptionMetadata("batch-size", map[string]string{"usage": "the number of rows processed in each batch"})
 	cliutil.SetOptionMetadata("child-table-id", map[string]string{"usage": "the child table's unique identifier, e.g., bqgruir7z"})
 	cliutil.SetOptionMetadata("data", map[string]string{"usage": "the record data in key=value format, e.g., '6=\"Another Record\" 7=3'"})
 	cliutil.SetOptionMetadata("delay", map[string]string{"usage": "delay between batches in milliseconds"})
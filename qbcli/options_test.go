@@ -0,0 +1,77 @@
+package qbcli_test
+
+import (
+	"testing"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// requiredIfInput has a required_if validator triggered by an option.
+type requiredIfInput struct {
+	Format   string `cliutil:"option=format"`
+	Template string `validate:"required_if=Format template" cliutil:"option=template"`
+}
+
+func TestValidateOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		input interface{}
+		want  string
+	}{
+		{
+			"required",
+			&qbclient.DeleteFieldsInput{FieldIDs: []int{6}},
+			"table-id option is required",
+		},
+		{
+			"min items",
+			&qbclient.DeleteFieldsInput{TableID: "bqgruir7z", FieldIDs: []int{}},
+			"field-id option requires at least 1 value",
+		},
+		{
+			"min number",
+			&qbcli.GenerateOptions{TableID: "bqgruir7z"},
+			"count option must be at least 1",
+		},
+		{
+			"required_if triggered by an option",
+			&requiredIfInput{Format: "template"},
+			"template option is required when the format option is template",
+		},
+		{
+			"required_if triggered by the command",
+			&qbclient.CreateFieldInput{Field: qbclient.Field{Create: true, Type: qbclient.FieldText}, TableID: "bqgruir7z"},
+			"label option is required",
+		},
+		{
+			"multiple errors",
+			&qbclient.CreateFieldInput{Field: qbclient.Field{Create: true}, TableID: "bqgruir7z"},
+			"label option is required, type option is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := qbcli.ValidateOptions(tt.input)
+			if err == nil {
+				t.Fatal("got nil, expected error")
+			}
+			if err.Error() != tt.want {
+				t.Errorf("got %q, expected %q", err.Error(), tt.want)
+			}
+		})
+	}
+
+	// Valid input, including a required_if validator that isn't triggered.
+	valid := []interface{}{
+		&qbclient.DeleteFieldsInput{TableID: "bqgruir7z", FieldIDs: []int{6}},
+		&requiredIfInput{Format: "table"},
+		&qbclient.CreateFieldInput{TableID: "bqgruir7z"},
+	}
+	for _, input := range valid {
+		if err := qbcli.ValidateOptions(input); err != nil {
+			t.Errorf("got %q, expected nil", err)
+		}
+	}
+}
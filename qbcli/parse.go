@@ -3,23 +3,38 @@ package qbcli
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+	"github.com/araddon/dateparse"
 	"github.com/cpliakas/cliutil"
 )
 
 var reSortBy, reGroupBy *regexp.Regexp
 
-// ParseQuery parses queries. It also detcts and transforms simple queries into
-// Quick Base query syntax.
-func ParseQuery(q string) string {
+// ParseQuery parses queries. It also detcts and transforms simple queries and
+// structured queries into Quick Base query syntax. See parseWhere for the
+// structured syntax.
+func ParseQuery(q string) (string, error) {
 
 	// Returns as-is if using Quick Base query syntax.
 	if strings.HasPrefix(q, "{") || strings.HasPrefix(q, "(") {
-		return q
+		return q, nil
+	}
+
+	// Structured queries start with a field ID followed by whitespace, which
+	// the key/value pairs of simple queries never do.
+	if words := strings.Fields(q); len(words) > 1 && !strings.Contains(words[0], "=") {
+		tokens, err := tokenizeWhere(q)
+		if err != nil {
+			return "", err
+		}
+		return parseWhere(tokens)
 	}
 
 	// Parse the key/value pairs.
@@ -38,7 +53,224 @@ func ParseQuery(q string) string {
 	}
 
 	// Join all clauses by AND.
-	return strings.Join(clauses, " AND ")
+	return strings.Join(clauses, " AND "), nil
+}
+
+// whereOperators maps the operators of structured queries to Quick Base query
+// operators. The Quick Base operators themselves are also accepted.
+var whereOperators = map[string]string{
+	"=":            "EX",
+	"==":           "EX",
+	"!=":           "XEX",
+	"<":            "LT",
+	"<=":           "LTE",
+	">":            "GT",
+	">=":           "GTE",
+	"contains":     "CT",
+	"!contains":    "XCT",
+	"has":          "HAS",
+	"!has":         "XHAS",
+	"starts-with":  "SW",
+	"!starts-with": "XSW",
+	"before":       "BF",
+	"on-or-before": "OBF",
+	"after":        "AF",
+	"on-or-after":  "OAF",
+	"in-range":     "IR",
+	"!in-range":    "XIR",
+	"true":         "TV",
+	"ex":           "EX",
+	"xex":          "XEX",
+	"lt":           "LT",
+	"lte":          "LTE",
+	"gt":           "GT",
+	"gte":          "GTE",
+	"ct":           "CT",
+	"xct":          "XCT",
+	"xhas":         "XHAS",
+	"sw":           "SW",
+	"xsw":          "XSW",
+	"bf":           "BF",
+	"obf":          "OBF",
+	"af":           "AF",
+	"oaf":          "OAF",
+	"ir":           "IR",
+	"xir":          "XIR",
+	"tv":           "TV",
+}
+
+// whereToken is a word of a structured query. Quoted words have their quotes
+// removed and are never treated as AND or OR.
+type whereToken struct {
+	s      string
+	quoted bool
+}
+
+// tokenizeWhere splits a query into words by whitespace that isn't in single
+// or double quotes.
+func tokenizeWhere(q string) ([]whereToken, error) {
+	tokens := []whereToken{}
+	var b strings.Builder
+	var quote rune
+	inToken, quoted := false, false
+
+	for _, c := range q {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			b.WriteRune(c)
+		case c == '\'' || c == '"':
+			quote, inToken = c, true
+			quoted = quoted || b.Len() == 0
+		case c == ' ' || c == '\t' || c == '\n':
+			if inToken {
+				tokens = append(tokens, whereToken{b.String(), quoted})
+				b.Reset()
+				inToken, quoted = false, false
+			}
+		default:
+			b.WriteRune(c)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "query %q has an unterminated quote", q)
+	}
+	if inToken {
+		tokens = append(tokens, whereToken{b.String(), quoted})
+	}
+	return tokens, nil
+}
+
+// parseWhere converts a structured query into Quick Base query syntax. A
+// structured query is a list of clauses combined by AND or OR, where each
+// clause is a field ID, an operator, and a value, e.g.,
+// "6 contains 'foo bar' AND 7 >= 3". AND takes precedence over OR, so groups
+// of clauses combined by AND are wrapped in parentheses when OR is used.
+func parseWhere(tokens []whereToken) (string, error) {
+	groups := [][]string{{}}
+
+	for idx := 0; idx < len(tokens); {
+
+		// Collect the words of the clause up to the next AND or OR.
+		start := idx
+		for idx < len(tokens) && !isWhereConnective(tokens[idx]) {
+			idx++
+		}
+		clause, err := parseWhereClause(tokens[start:idx])
+		if err != nil {
+			return "", err
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], clause)
+
+		if idx == len(tokens) {
+			break
+		}
+		if idx == len(tokens)-1 {
+			return "", qberrors.Client(nil).Safef(qberrors.InvalidInput, "query ends with %q", tokens[idx].s)
+		}
+		if strings.EqualFold(tokens[idx].s, "OR") {
+			groups = append(groups, []string{})
+		}
+		idx++
+	}
+
+	parts := make([]string, len(groups))
+	for idx, group := range groups {
+		parts[idx] = strings.Join(group, " AND ")
+		if len(groups) > 1 && len(group) > 1 {
+			parts[idx] = "(" + parts[idx] + ")"
+		}
+	}
+	return strings.Join(parts, " OR "), nil
+}
+
+// parseWhereClause converts a clause of a structured query into Quick Base
+// query syntax.
+func parseWhereClause(tokens []whereToken) (string, error) {
+	words := make([]string, len(tokens))
+	for idx, t := range tokens {
+		words[idx] = t.s
+	}
+	text := strings.Join(words, " ")
+
+	if len(tokens) == 0 {
+		return "", qberrors.Client(nil).Safef(qberrors.InvalidInput, "query has an empty clause")
+	}
+	if len(tokens) < 3 {
+		return "", qberrors.Client(nil).Safef(qberrors.InvalidInput, "clause %q must be a field ID, an operator, and a value", text)
+	}
+
+	fid, err := strconv.Atoi(tokens[0].s)
+	if err != nil || fid < 1 {
+		return "", qberrors.Client(nil).Safef(qberrors.InvalidInput, "field %q in clause %q is not a field ID", tokens[0].s, text)
+	}
+
+	op, ok := whereOperators[strings.ToLower(tokens[1].s)]
+	if !ok || tokens[1].quoted {
+		return "", qberrors.Client(nil).Safef(qberrors.InvalidInput, "operator %q in clause %q not valid", tokens[1].s, text)
+	}
+
+	value, err := quoteQueryValue(strings.Join(words[2:], " "))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("{%v.%s.%s}", fid, op, value), nil
+}
+
+// isWhereConnective returns whether the token is AND or OR.
+func isWhereConnective(t whereToken) bool {
+	return !t.quoted && (strings.EqualFold(t.s, "AND") || strings.EqualFold(t.s, "OR"))
+}
+
+// quoteQueryValue quotes a value in Quick Base query syntax, which has no
+// escape sequences, so values containing single quotes are double quoted.
+// Values containing both kinds of quotes can't be quoted and are an error.
+func quoteQueryValue(v string) (string, error) {
+	if !strings.Contains(v, "'") {
+		return "'" + v + "'", nil
+	}
+	if strings.Contains(v, `"`) {
+		return "", qberrors.Client(nil).Safef(qberrors.InvalidInput, "value %q can't contain both single and double quotes", v)
+	}
+	return `"` + v + `"`, nil
+}
+
+// ParseModifiedSince returns the query that filters the records modified
+// since the date passed through the option. The date is parsed and formatted
+// again so that the value can't change the query. A date without a time
+// matches the records modified on or after that day, and a time is converted
+// to milliseconds since the epoch and matches the records modified after it.
+func ParseModifiedSince(option, since string) (string, error) {
+	layout, err := dateparse.ParseFormat(since)
+	if err != nil {
+		return "", invalidTimeError(option, since)
+	}
+	t, err := dateparse.ParseAny(since)
+	if err != nil {
+		return "", invalidTimeError(option, since)
+	}
+
+	if isDateLayout(since, layout) {
+		return "{2.OAF.'" + t.Format("2006-01-02") + "'}", nil
+	}
+	return "{2.AF.'" + strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10) + "'}", nil
+}
+
+// isDateLayout returns true if the layout that dateparse.ParseFormat returns
+// for since has no time of day. Timestamps are returned as-is.
+func isDateLayout(since, layout string) bool {
+	if layout == since && strings.Trim(since, "0123456789") == "" {
+		return false
+	}
+	for _, clock := range []string{"15", "3", "04"} {
+		if strings.Contains(layout, clock) {
+			return false
+		}
+	}
+	return true
 }
 
 // ParseSortBy parses the sortBy clause.
@@ -107,6 +339,81 @@ func ParseGroupBy(s string) (groupBy []*qbclient.QueryRecordsInputGroupBy, err e
 	return
 }
 
+// ReadSelectFile reads the fields to select from a file, which lists field IDs,
+// ranges such as 10:15, or field labels one per line or separated by commas.
+// Empty lines and lines starting with # are skipped. Labels are resolved
+// against the table's schema.
+func ReadSelectFile(qb *qbclient.Client, tableID, path string) ([]int, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "error reading select file: %w", err)
+	}
+
+	fids := []int{}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		ids, err := ParseSelect(qb, tableID, line)
+		if err != nil {
+			return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "select file not valid: %w", err)
+		}
+		fids = append(fids, ids...)
+	}
+
+	if len(fids) == 0 {
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "%s: select file contains no fields", path)
+	}
+
+	return fids, nil
+}
+
+// ParseSelect parses a comma-separated list of field IDs, ranges such as
+// 10:15, or field labels into the field IDs to select. Labels are resolved
+// against the table's schema, which is only read if there are labels, and an
+// error is returned for labels that don't match a field.
+func ParseSelect(qb *qbclient.Client, tableID, s string) ([]int, error) {
+	fids := []int{}
+	for _, elem := range strings.Split(s, ",") {
+		elem = strings.TrimSpace(elem)
+		if elem == "" {
+			continue
+		}
+
+		if ids, err := cliutil.ParseIntSlice(elem); err == nil {
+			fids = append(fids, ids...)
+			continue
+		}
+
+		fid, err := PluckFieldID(qb, tableID, elem)
+		if err != nil {
+			return nil, err
+		}
+		fids = append(fids, fid)
+	}
+	return fids, nil
+}
+
+// withSelectFile returns the selected fields followed by the fields in the
+// select file, if one is passed. An error is returned if no field is selected.
+func withSelectFile(qb *qbclient.Client, tableID string, sel []int, path string) ([]int, error) {
+	if path != "" {
+		fids, err := ReadSelectFile(qb, tableID, path)
+		if err != nil {
+			return nil, err
+		}
+		sel = append(append([]int{}, sel...), fids...)
+	}
+
+	if len(sel) == 0 {
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "select or select-file option is required")
+	}
+
+	return sel, nil
+}
+
 func init() {
 	reSortBy = regexp.MustCompile(`^\s*(\d+)(?:\s+(ASC|DESC)?\s*)?$`)
 	reGroupBy = regexp.MustCompile(`^\s*(\d+)(?:\s+(ASC|DESC|equal-values)?\s*)?$`)
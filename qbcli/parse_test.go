@@ -0,0 +1,169 @@
+package qbcli_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/QuickBase/quickbase-cli/qbcli"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			"raw query",
+			"{6.EX.'foo'}",
+			"{6.EX.'foo'}",
+		},
+		{
+			"raw grouped query",
+			"({6.EX.'foo'}OR{7.EX.'bar'})AND{8.GT.'3'}",
+			"({6.EX.'foo'}OR{7.EX.'bar'})AND{8.GT.'3'}",
+		},
+		{
+			"single clause",
+			"6 contains foo",
+			"{6.CT.'foo'}",
+		},
+		{
+			"quoted value",
+			"6 contains 'foo bar'",
+			"{6.CT.'foo bar'}",
+		},
+		{
+			"value with a single quote",
+			`6 = "it's"`,
+			`{6.EX."it's"}`,
+		},
+		{
+			"quoted connective",
+			"6 = 'AND'",
+			"{6.EX.'AND'}",
+		},
+		{
+			"and",
+			"6 = foo and 7 >= 3",
+			"{6.EX.'foo'} AND {7.GTE.'3'}",
+		},
+		{
+			"and binds tighter than or",
+			"6 = foo AND 7 > 3 OR 8 != bar",
+			"({6.EX.'foo'} AND {7.GT.'3'}) OR {8.XEX.'bar'}",
+		},
+		{
+			"quick base operator",
+			"6 XCT foo OR 7 ir 'last 7 days'",
+			"{6.XCT.'foo'} OR {7.IR.'last 7 days'}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := qbcli.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("got %q, expected nil", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, expected %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQueryError(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			"unknown operator",
+			"6 like foo",
+			`operator "like" in clause "6 like foo" not valid`,
+		},
+		{
+			"quoted operator",
+			"6 '=' foo",
+			`operator "=" in clause "6 = foo" not valid`,
+		},
+		{
+			"trailing connective",
+			"6 = foo AND",
+			`query ends with "AND"`,
+		},
+		{
+			"empty clause",
+			"6 = foo AND OR 7 = bar",
+			"query has an empty clause",
+		},
+		{
+			"field not an ID",
+			"name = foo",
+			`field "name" in clause "name = foo" is not a field ID`,
+		},
+		{
+			"missing value",
+			"6 = foo AND 7 =",
+			`clause "7 =" must be a field ID, an operator, and a value`,
+		},
+		{
+			"unterminated quote",
+			"6 = 'foo",
+			`query "6 = 'foo" has an unterminated quote`,
+		},
+		{
+			"value with both quotes",
+			`6 = "it's" 'a "b"'`,
+			`value "it's a \"b\"" can't contain both single and double quotes`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := qbcli.ParseQuery(tt.query)
+			if !errors.Is(err, qberrors.InvalidInput) {
+				t.Fatalf("got %v, expected %q", err, qberrors.InvalidInput)
+			}
+			if detail := qberrors.SafeDetail(err); detail != tt.want {
+				t.Errorf("got %q, expected %q", detail, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseModifiedSince(t *testing.T) {
+	tests := []struct {
+		name  string
+		since string
+		want  string
+	}{
+		{"date", "2021-06-01", "{2.OAF.'2021-06-01'}"},
+		{"us date", "06/01/2021", "{2.OAF.'2021-06-01'}"},
+		{"time", "2021-06-01T09:00:00Z", "{2.AF.'1622538000000'}"},
+		{"midnight", "2021-06-01T00:00:00Z", "{2.AF.'1622505600000'}"},
+		{"timestamp", "1622538000", "{2.AF.'1622538000000'}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := qbcli.ParseModifiedSince("since", tt.since)
+			if err != nil {
+				t.Fatalf("got %q, expected nil", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, expected %q", got, tt.want)
+			}
+		})
+	}
+
+	// Values that aren't dates are rejected instead of changing the query.
+	for _, since := range []string{"yesterday", "2021-06-01'}OR{3.GT.'0"} {
+		if _, err := qbcli.ParseModifiedSince("since", since); !errors.Is(err, qberrors.InvalidInput) {
+			t.Errorf("got %v, expected %q", err, qberrors.InvalidInput)
+		}
+	}
+}
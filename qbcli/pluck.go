@@ -0,0 +1,83 @@
+package qbcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// PluckOutput contains the values of a single field across records.
+type PluckOutput struct {
+	Label  string
+	Values []*qbclient.Value
+}
+
+// MarshalJSON implements json.MarshalJSON by marshaling the values as an
+// array. Records without a value for the field are null.
+func (o *PluckOutput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Values)
+}
+
+// TableHeader implements Tabular.TableHeader.
+func (o *PluckOutput) TableHeader() []string {
+	return []string{o.Label}
+}
+
+// TableRows implements Tabular.TableRows.
+func (o *PluckOutput) TableRows() [][]string {
+	rows := make([][]string, len(o.Values))
+	for idx, v := range o.Values {
+		rows[idx] = []string{""}
+		if v != nil {
+			rows[idx][0] = v.String()
+		}
+	}
+	return rows
+}
+
+// PluckFieldID resolves the field to pluck, which is either a field ID or a
+// field label in the table.
+func PluckFieldID(qb *qbclient.Client, tableID, field string) (int, error) {
+	if fid, err := strconv.Atoi(field); err == nil {
+		return fid, nil
+	}
+
+	fields, err := GetTableSchema(qb, tableID)
+	if err != nil {
+		return 0, fmt.Errorf("error getting table metadata: %w", err)
+	}
+	fid, err := newLabelIndex(fields).lookup(field)
+	if err != nil {
+		return 0, err
+	} else if fid != 0 {
+		return fid, nil
+	}
+
+	return 0, fmt.Errorf("field %q not in table %s", field, tableID)
+}
+
+// Pluck returns the values of a field across the records, returning an error
+// if the field isn't in the records.
+func Pluck(records qbclient.Records, fid int) (*PluckOutput, error) {
+	var f *qbclient.RecordsField
+	for _, rf := range records.Fields {
+		if rf.FieldID == fid {
+			f = rf
+			break
+		}
+	}
+	if f == nil {
+		return nil, fmt.Errorf("field %v not in the result", fid)
+	}
+
+	output := &PluckOutput{Label: f.Label, Values: make([]*qbclient.Value, len(records.Data))}
+	for idx, record := range records.Data {
+		if data, ok := record[f.FieldID]; ok {
+			output.Values[idx] = data.Value
+		}
+	}
+
+	return output, nil
+}
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/QuickBase/quickbase-cli/qbclient"
@@ -42,23 +45,64 @@ func (p LoggerPlugin) PostResponse(resp *http.Response) {
 		ctx = cliutil.ContextWithLogTag(ctx, "method", resp.Request.Method)
 		ctx = cliutil.ContextWithLogTag(ctx, "url", resp.Request.URL.String())
 		ctx = cliutil.ContextWithLogTag(ctx, "status", resp.Status)
+		if id := qbclient.ResponseRequestID(resp); id != "" {
+			ctx = cliutil.ContextWithLogTag(ctx, "requestid", id)
+		}
 		p.logger.Info(ctx, "api response returned")
 	}
 }
 
+// Retry implements qbclient.RetryPlugin.Retry.
+func (p LoggerPlugin) Retry(req *http.Request, attempt int) {
+	ctx := p.ctx
+	ctx = cliutil.ContextWithLogTag(ctx, "method", req.Method)
+	ctx = cliutil.ContextWithLogTag(ctx, "url", req.URL.String())
+	ctx = cliutil.ContextWithLogTag(ctx, "attempt", strconv.Itoa(attempt))
+	p.logger.Debug(ctx, "retrying api request")
+}
+
+// Compress implements qbclient.CompressPlugin.Compress.
+func (p LoggerPlugin) Compress(req *http.Request, before, after int) {
+	ctx := p.ctx
+	ctx = cliutil.ContextWithLogTag(ctx, "method", req.Method)
+	ctx = cliutil.ContextWithLogTag(ctx, "url", req.URL.String())
+	ctx = cliutil.ContextWithLogTag(ctx, "bytes", strconv.Itoa(before))
+	ctx = cliutil.ContextWithLogTag(ctx, "compressed", strconv.Itoa(after))
+	ctx = cliutil.ContextWithLogTag(ctx, "reduction", fmt.Sprintf("%.1f%%", 100*float64(before-after)/float64(before)))
+	p.logger.Debug(ctx, "compressed request body")
+}
+
+// DryRun implements qbclient.DryRunPlugin.DryRun by writing the request that
+// wasn't sent to stderr, with the credentials redacted.
+func (p LoggerPlugin) DryRun(req *http.Request) {
+	ctx := p.ctx
+	ctx = cliutil.ContextWithLogTag(ctx, "method", req.Method)
+	ctx = cliutil.ContextWithLogTag(ctx, "url", req.URL.String())
+
+	dump, err := dumpRequest(req, false)
+	if err != nil {
+		p.logger.Error(ctx, "error dumping request", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", dump)
+	p.logger.Notice(ctx, "dry run, request not sent")
+}
+
 // DumpPlugin implements qbclient.Plugin and dumps requests and responses to
-// files in a directory.
+// files in a directory. Credentials are redacted before the files are written
+// unless unredacted is true.
 type DumpPlugin struct {
-	ctx       context.Context
-	directory string
-	logger    *cliutil.LeveledLogger
-	transid   string
+	ctx        context.Context
+	directory  string
+	logger     *cliutil.LeveledLogger
+	transid    string
+	unredacted bool
 }
 
 // NewDumpPlugin returns a DumpPlugin, which implements qbclient.Plugin.
-func NewDumpPlugin(ctx context.Context, logger *cliutil.LeveledLogger, transid string, directory string) qbclient.Plugin {
+func NewDumpPlugin(ctx context.Context, logger *cliutil.LeveledLogger, transid string, directory string, unredacted bool) qbclient.Plugin {
 	dir := strings.TrimRight(directory, string(os.PathSeparator))
-	return DumpPlugin{ctx: ctx, logger: logger, transid: transid, directory: dir}
+	return DumpPlugin{ctx: ctx, logger: logger, transid: transid, directory: dir, unredacted: unredacted}
 }
 
 // PreRequest implements qbclient.Plugin.PreRequest.
@@ -69,29 +113,12 @@ func (p DumpPlugin) PreRequest(req *http.Request) {
 	}
 	defer file.Close()
 
-	// Dump the request headers.
-	headers, err := httputil.DumpRequestOut(req, false)
-	if err != nil {
-		p.logger.Error(ctx, "error dumping request headers", err)
-		return
-	}
-
-	// Read the request body.
-	defer req.Body.Close()
-	body, err := ioutil.ReadAll(req.Body)
+	dump, err := dumpRequest(req, p.unredacted)
 	if err != nil {
-		p.logger.Error(ctx, "error reading request body", err)
+		p.logger.Error(ctx, "error dumping request", err)
 		return
 	}
 
-	// Build the request sent over the wire.
-	buf := bytes.NewBuffer([]byte(``))
-	buf.Write(headers)
-	buf.Write(body)
-
-	// Mask any user tokens.
-	dump := qbclient.MaskUserToken(buf.Bytes())
-
 	// Write the request to the dump file, and log the result.
 	n, err := file.Write(dump)
 	ctx = cliutil.ContextWithLogTag(ctx, "bytes", strconv.Itoa(n))
@@ -100,9 +127,6 @@ func (p DumpPlugin) PreRequest(req *http.Request) {
 	} else {
 		p.logger.Error(ctx, "error writing request to dump file", err)
 	}
-
-	// Put the request body back so we can read it again.
-	req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
 }
 
 // PostResponse implements qbclient.Plugin.PostResponse.
@@ -113,29 +137,12 @@ func (p DumpPlugin) PostResponse(resp *http.Response) {
 	}
 	defer file.Close()
 
-	// Dump the response headers.
-	headers, err := httputil.DumpResponse(resp, false)
+	dump, err := dumpResponse(resp, p.unredacted)
 	if err != nil {
-		p.logger.Error(ctx, "error dumping response headers", err)
+		p.logger.Error(ctx, "error dumping response", err)
 		return
 	}
 
-	// Read the response body.
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		p.logger.Error(ctx, "error reading response body", err)
-		return
-	}
-
-	// Build the response returned over the wire.
-	buf := bytes.NewBuffer([]byte(``))
-	buf.Write(headers)
-	buf.Write(body)
-
-	// Mask any user tokens.
-	dump := qbclient.MaskUserToken(buf.Bytes())
-
 	// Write the response to the dump file, and log the result.
 	n, err := file.Write(dump)
 	ctx = cliutil.ContextWithLogTag(ctx, "bytes", strconv.Itoa(n))
@@ -144,9 +151,6 @@ func (p DumpPlugin) PostResponse(resp *http.Response) {
 	} else {
 		p.logger.Error(ctx, "error writing response to dump file", err)
 	}
-
-	// Put the response body back so we can read it again.
-	resp.Body = ioutil.NopCloser(bytes.NewBuffer(body))
 }
 
 func (p DumpPlugin) openDumpFile(op string) (ctx context.Context, file *os.File, err error) {
@@ -165,3 +169,194 @@ func (p DumpPlugin) openDumpFile(op string) (ctx context.Context, file *os.File,
 
 	return
 }
+
+// DebugPlugin implements qbclient.Plugin and keeps the last request and
+// response in memory so they can be written to stderr when a command fails.
+type DebugPlugin struct {
+	mu       sync.Mutex
+	request  []byte
+	response []byte
+}
+
+// _debug is the DebugPlugin whose dumps are written by HandleError, or nil if
+// --debug-on-error wasn't passed.
+var _debug *DebugPlugin
+
+// PreRequest implements qbclient.Plugin.PreRequest.
+func (p *DebugPlugin) PreRequest(req *http.Request) {
+	dump, err := dumpRequest(req, false)
+	if err != nil {
+		dump = []byte(fmt.Sprintf("error dumping request: %s\n", err))
+	}
+
+	p.mu.Lock()
+	p.request, p.response = dump, nil
+	p.mu.Unlock()
+}
+
+// PostResponse implements qbclient.Plugin.PostResponse.
+func (p *DebugPlugin) PostResponse(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	dump, err := dumpResponse(resp, false)
+	if err != nil {
+		dump = []byte(fmt.Sprintf("error dumping response: %s\n", err))
+	}
+
+	p.mu.Lock()
+	p.response = dump
+	p.mu.Unlock()
+}
+
+// WriteTo writes the last request and response to w.
+func (p *DebugPlugin) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.request == nil {
+		return 0, nil
+	}
+
+	buf := bytes.NewBufferString("--- last request ---\n")
+	buf.Write(p.request)
+	buf.WriteString("\n--- last response ---\n")
+	if p.response != nil {
+		buf.Write(p.response)
+	} else {
+		buf.WriteString("no response received")
+	}
+	buf.WriteString("\n")
+
+	return buf.WriteTo(w)
+}
+
+// TracePlugin implements qbclient.Plugin and logs each request and response,
+// including their bodies, at the debug level to stderr. It has its own logger,
+// so traces are written whatever the --log-level and --log-file options are.
+type TracePlugin struct {
+	ctx    context.Context
+	logger *cliutil.LeveledLogger
+}
+
+// NewTracePlugin returns a TracePlugin, which implements qbclient.Plugin. The
+// log tags in ctx, e.g., the transaction ID, are added to every trace.
+func NewTracePlugin(ctx context.Context) qbclient.Plugin {
+	logger := cliutil.NewLogger(cliutil.LogDebug)
+	logger.SetOutput(os.Stderr)
+	return TracePlugin{ctx: ctx, logger: logger}
+}
+
+// PreRequest implements qbclient.Plugin.PreRequest.
+func (p TracePlugin) PreRequest(req *http.Request) {
+	ctx := p.ctx
+	ctx = cliutil.ContextWithLogTag(ctx, "method", req.Method)
+	ctx = cliutil.ContextWithLogTag(ctx, "url", qbclient.MaskUserTokenString(req.URL.String()))
+	ctx = cliutil.ContextWithLogTag(ctx, "headers", traceHeaders(req.Header))
+
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+		if err != nil {
+			p.logger.Error(ctx, "error reading request body", err)
+			return
+		}
+		ctx = cliutil.ContextWithLogTag(ctx, "body", string(qbclient.RedactCredentials(body)))
+	}
+
+	p.logger.Debug(ctx, "api request")
+}
+
+// PostResponse implements qbclient.Plugin.PostResponse.
+func (p TracePlugin) PostResponse(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+
+	ctx := p.ctx
+	ctx = cliutil.ContextWithLogTag(ctx, "method", resp.Request.Method)
+	ctx = cliutil.ContextWithLogTag(ctx, "url", qbclient.MaskUserTokenString(resp.Request.URL.String()))
+	ctx = cliutil.ContextWithLogTag(ctx, "status", resp.Status)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	if err != nil {
+		p.logger.Error(ctx, "error reading response body", err)
+		return
+	}
+	ctx = cliutil.ContextWithLogTag(ctx, "body", string(qbclient.RedactCredentials(body)))
+
+	p.logger.Debug(ctx, "api response")
+}
+
+// traceHeaders returns the headers formatted as "Name: value" pairs sorted by
+// name, with the credentials in the Authorization header redacted.
+func traceHeaders(h http.Header) string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for idx, name := range names {
+		value := strings.Join(h[name], ", ")
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			value = "[redacted]"
+		}
+		pairs[idx] = name + ": " + value
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// dumpRequest returns the request sent over the wire with the credentials
+// redacted unless raw is true. The body is put back so it can be read again.
+func dumpRequest(req *http.Request, raw bool) ([]byte, error) {
+	headers, err := httputil.DumpRequestOut(req, false)
+	if err != nil {
+		return nil, fmt.Errorf("error dumping request headers: %w", err)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		defer req.Body.Close()
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("error reading request body: %w", err)
+		}
+		req.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	}
+
+	buf := bytes.NewBuffer(headers)
+	buf.Write(body)
+	if raw {
+		return buf.Bytes(), nil
+	}
+	return qbclient.RedactCredentials(buf.Bytes()), nil
+}
+
+// dumpResponse returns the response returned over the wire with the
+// credentials redacted unless raw is true. The body is put back so it can be
+// read again.
+func dumpResponse(resp *http.Response, raw bool) ([]byte, error) {
+	headers, err := httputil.DumpResponse(resp, false)
+	if err != nil {
+		return nil, fmt.Errorf("error dumping response headers: %w", err)
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+
+	buf := bytes.NewBuffer(headers)
+	buf.Write(body)
+	if raw {
+		return buf.Bytes(), nil
+	}
+	return qbclient.RedactCredentials(buf.Bytes()), nil
+}
@@ -0,0 +1,240 @@
+package qbcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+	"github.com/spf13/cobra"
+)
+
+// Auth* constants are the authentication methods a profile can configure.
+const (
+	AuthUserToken      = "user_token"
+	AuthTemporaryToken = "temp_token"
+	AuthTokenHelper    = "token_helper"
+	AuthNone           = "none"
+)
+
+// ProfileOutput describes a profile in the config file. Tokens are masked, so
+// the output is safe to share.
+type ProfileOutput struct {
+	Profile       string `json:"profile"`
+	Active        bool   `json:"active"`
+	RealmHostname string `json:"realm_hostname,omitempty"`
+	AuthMethod    string `json:"auth_method"`
+	Credential    string `json:"credential,omitempty"`
+	AppID         string `json:"app_id,omitempty"`
+	TableID       string `json:"table_id,omitempty"`
+}
+
+// newProfileOutput returns a *ProfileOutput for a profile. A user token takes
+// precedence over a temporary token, and the token helper is only run when
+// neither is set, so that is the order the auth method is detected in. The
+// token helper's command isn't a secret, so it is shown as-is.
+func newProfileOutput(name string, p *qbclient.ConfigFileProfile, active bool) *ProfileOutput {
+	output := &ProfileOutput{
+		Profile:       name,
+		Active:        active,
+		RealmHostname: p.RealmHostname,
+		AuthMethod:    AuthNone,
+		AppID:         p.AppID,
+		TableID:       p.TableID,
+	}
+
+	switch {
+	case p.UserToken != "":
+		output.AuthMethod = AuthUserToken
+		output.Credential = qbclient.MaskToken(p.UserToken)
+	case p.TemporaryToken != "":
+		output.AuthMethod = AuthTemporaryToken
+		output.Credential = qbclient.MaskToken(p.TemporaryToken)
+	case p.TokenHelper != "":
+		output.AuthMethod = AuthTokenHelper
+		output.Credential = p.TokenHelper
+	}
+
+	return output
+}
+
+// ProfileListOutput contains the profiles in the config file.
+type ProfileListOutput struct {
+	Profiles []*ProfileOutput
+}
+
+// MarshalJSON implements json.MarshalJSON by marshaling the profiles as an
+// array.
+func (o *ProfileListOutput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Profiles)
+}
+
+// TableHeader implements Tabular.TableHeader.
+func (o *ProfileListOutput) TableHeader() []string {
+	return []string{"Profile", "Active", "Realm Hostname", "Auth Method"}
+}
+
+// TableRows implements Tabular.TableRows.
+func (o *ProfileListOutput) TableRows() [][]string {
+	rows := make([][]string, len(o.Profiles))
+	for idx, p := range o.Profiles {
+		active := ""
+		if p.Active {
+			active = "*"
+		}
+		rows[idx] = []string{p.Profile, active, p.RealmHostname, p.AuthMethod}
+	}
+	return rows
+}
+
+// ListProfiles returns the profiles in the config file, sorted by name. The
+// active profile is flagged.
+func ListProfiles(configFile, active string) (*ProfileListOutput, error) {
+	output := &ProfileListOutput{Profiles: []*ProfileOutput{}}
+
+	cfg, err := qbclient.ReadConfigFileAt(configFile)
+	if err != nil {
+		return output, err
+	}
+
+	names := make([]string, 0, len(cfg))
+	for name := range cfg {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		output.Profiles = append(output.Profiles, newProfileOutput(name, cfg[name], name == active))
+	}
+
+	return output, nil
+}
+
+// ProfileShowOptions are the options read through the command line.
+type ProfileShowOptions struct {
+	Name string `cliutil:"option=name usage='name of the profile, defaulting to the active profile'"`
+}
+
+// ShowProfile returns a profile in the config file, which is the active profile
+// unless opts.Name is set.
+func ShowProfile(configFile string, opts *ProfileShowOptions, active string) (*ProfileOutput, error) {
+	cfg, err := qbclient.ReadConfigFileAt(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = active
+	}
+
+	p, ok := cfg[name]
+	if !ok {
+		return nil, qberrors.Client(nil).Safef(qberrors.NotFound, "profile %q not in config file", name)
+	}
+	return newProfileOutput(name, p, name == active), nil
+}
+
+// ProfileSetOptions are the options read through the command line. The realm
+// hostname and tokens are read from the global options by ReadFlags.
+type ProfileSetOptions struct {
+	Name    string `validate:"required" cliutil:"option=name usage='name of the profile'"`
+	AppID   string `cliutil:"option=app-id usage='default app ID of the profile'"`
+	TableID string `cliutil:"option=table-id usage='default table ID of the profile'"`
+
+	RealmHostname string
+	UserToken     string
+	UserTokenFile string
+	TokenHelper   string
+}
+
+// ReadFlags implements FlagReader. Only the global options passed on the
+// command line are read, since they otherwise default to the active profile.
+func (o *ProfileSetOptions) ReadFlags(cmd *cobra.Command) {
+	passed := func(name string) string {
+		if f := cmd.Flag(name); f != nil && f.Changed {
+			return f.Value.String()
+		}
+		return ""
+	}
+
+	o.RealmHostname = passed(qbclient.OptionRealmHostname)
+	o.UserToken = passed(qbclient.OptionUserToken)
+	o.UserTokenFile = passed(qbclient.OptionUserTokenFile)
+	o.TokenHelper = passed(qbclient.OptionTokenHelper)
+}
+
+// SetProfile writes the profile in opts to the config file, creating its
+// directory and the file if they don't exist. An existing profile is only changed
+// if --force is passed, in which case the values set in opts replace its
+// values and the rest are kept. New profiles require a realm hostname, which
+// can be a realm alias.
+func SetProfile(gcfg GlobalConfig, opts *ProfileSetOptions) (*ProfileOutput, error) {
+	configFile, active, force := gcfg.ConfigFile(), gcfg.Profile(), gcfg.Force()
+
+	cfg, err := qbclient.ReadConfigFileAt(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	aliases, err := qbclient.ReadRealmAliasesAt(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// A token passed through a file or stdin is written as read.
+	userToken := opts.UserToken
+	if userToken != "" || opts.UserTokenFile != "" {
+		userToken = gcfg.UserToken()
+	}
+
+	name := opts.Name
+	p := &qbclient.ConfigFileProfile{
+		RealmHostname: opts.RealmHostname,
+		UserToken:     userToken,
+		TokenHelper:   opts.TokenHelper,
+		AppID:         opts.AppID,
+		TableID:       opts.TableID,
+	}
+
+	existing, ok := cfg[name]
+	switch {
+	case name == qbclient.ConfigFileRealmsKey:
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "profile name %q is reserved for realm aliases", name)
+	case ok && !force:
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "profile %q already exists, pass --force to update it", name)
+	case !ok && p.RealmHostname == "":
+		return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "option %q is required for new profiles", qbclient.OptionRealmHostname)
+	case !ok:
+		existing = &qbclient.ConfigFileProfile{}
+		cfg[name] = existing
+	}
+
+	if p.RealmHostname != "" {
+		// Aliases are expanded when the profile is read.
+		if _, isAlias := aliases[p.RealmHostname]; !isAlias {
+			if err := qbclient.ValidateHostname(p.RealmHostname); err != nil {
+				return nil, qberrors.Client(nil).Safef(qberrors.InvalidInput, "%s", err)
+			}
+		}
+		existing.RealmHostname = p.RealmHostname
+	}
+	if p.UserToken != "" {
+		existing.UserToken = p.UserToken
+	}
+	if p.TokenHelper != "" {
+		existing.TokenHelper = p.TokenHelper
+	}
+	if p.AppID != "" {
+		existing.AppID = p.AppID
+	}
+	if p.TableID != "" {
+		existing.TableID = p.TableID
+	}
+
+	if err := qbclient.WriteConfigFileAt(configFile, cfg); err != nil {
+		return nil, fmt.Errorf("error writing config file: %w", err)
+	}
+	return newProfileOutput(name, existing, name == active), nil
+}
@@ -0,0 +1,251 @@
+package qbcli
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+	"github.com/spf13/viper"
+)
+
+// QueryRecordsOptions are the options read through the command line. They
+// extend the query input with options that select and filter fields through
+// the table's schema, which PrepareQueryRecords applies to the input's
+// options before they are read.
+type QueryRecordsOptions struct {
+	Input *qbclient.QueryRecordsInput
+
+	FromReport         string `cliutil:"option=from-report usage='start from the filter, fields, and sort order of the report with this ID, which the other options extend'"`
+	SelectFile         string `cliutil:"option=select-file usage='file listing the field IDs or labels to select, one per line or comma-separated, added to --select'"`
+	SelectRelated      bool   `cliutil:"option=select-related usage='include the lookup fields of the table in the select clause'"`
+	SelectChangedSince string `cliutil:"option=select-changed-since usage='select records modified on or after the date, e.g., 2021-06-01, or after the time, including the record ID and Date Modified fields'"`
+	Pluck              string `cliutil:"option=pluck usage='output only the values of this field, either its ID or label'"`
+	Distinct           string `cliutil:"option=distinct usage='output the sorted unique values of this field across all pages, either its ID or label'"`
+	WithCounts         bool   `cliutil:"option=with-counts usage='include the number of records with each distinct value'"`
+	NoPaginate         bool   `cliutil:"option=no-paginate usage='return only the first page of records instead of reading every page'"`
+	MaxRecords         int    `cliutil:"option=max-records usage='stop reading pages once this many records are returned, 0 for unlimited'"`
+	Estimate           bool   `cliutil:"option=estimate usage='estimate the number of records, API calls, and time the query takes, and confirm before running it'"`
+	EstimateOnly       bool   `cliutil:"option=estimate-only usage='report the estimate of --estimate without running the query'"`
+}
+
+// NewQueryRecordsOptions returns a *QueryRecordsOptions with an empty input.
+func NewQueryRecordsOptions() *QueryRecordsOptions {
+	return &QueryRecordsOptions{
+		Input: &qbclient.QueryRecordsInput{Options: &qbclient.QueryRecordsInputOptions{}},
+	}
+}
+
+// PrepareQueryRecords implements PrepareFunc. The query is read from the table
+// passed as the table-id option unless --from is passed. The field labels in
+// the select clause are resolved to field IDs, and the fields and filters of
+// the other options are added to the select and where clauses, so that
+// records query sends a single query.
+func PrepareQueryRecords(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg *viper.Viper) {
+	cfg.SetDefault("from", cfg.GetString(qbclient.OptionTableID))
+	tableID := cfg.GetString("from")
+
+	// Convert a simple or structured --where into Quick Base query syntax
+	// so it can be combined with the other criteria.
+	if w := cfg.GetString("where"); w != "" {
+		where, err := ParseQuery(w)
+		HandleError(ctx, logger, "where option not valid", err)
+		cfg.Set("where", where)
+	}
+
+	// Parse the date of --select-changed-since before any request is sent.
+	changedSince := ""
+	if since := cfg.GetString("select-changed-since"); since != "" {
+		var err error
+		changedSince, err = ParseModifiedSince("select-changed-since", since)
+		HandleError(ctx, logger, "select-changed-since option not valid", err)
+	}
+
+	// Resolve the field labels in the select clause to field IDs.
+	if sel := cfg.GetString("select"); sel != "" {
+		fids, err := ParseSelect(qb, tableID, sel)
+		HandleError(ctx, logger, "select option not valid", err)
+		cfg.Set("select", "")
+		addSelect(cfg, fids)
+	}
+
+	// Start from the report's query, which the other options extend.
+	if reportID := cfg.GetString("from-report"); reportID != "" {
+		query, err := ReportQuery(qb, tableID, reportID)
+		HandleError(ctx, logger, "from-report option not valid", err)
+		applyReportQuery(cfg, query)
+	}
+
+	// Add the fields in the select file to the select clause.
+	if path := cfg.GetString("select-file"); path != "" {
+		fids, err := ReadSelectFile(qb, tableID, path)
+		HandleError(ctx, logger, "select-file option not valid", err)
+		addSelect(cfg, fids)
+	}
+
+	// Add the table's lookup fields to the select clause.
+	if cfg.GetBool("select-related") {
+		fids, err := LookupFieldIDs(qb, tableID)
+		HandleError(ctx, logger, "error getting lookup fields", err)
+
+		if len(fids) == 0 {
+			logger.Notice(cliutil.ContextWithLogTag(ctx, "table", tableID), "no lookup fields defined on table")
+		}
+
+		addSelect(cfg, fids)
+	}
+
+	// Select the record ID and Date Modified fields, which are needed to
+	// track state, and filter records modified since the date.
+	if changedSince != "" {
+		addSelect(cfg, []int{2, 3})
+
+		where := changedSince
+		if w := cfg.GetString("where"); w != "" {
+			where = "(" + w + ")AND" + where
+		}
+		cfg.Set("where", where)
+	}
+
+	// Select the field to pluck and the field whose unique values are
+	// returned, which are resolved to field IDs.
+	for _, option := range []string{"pluck", "distinct"} {
+		if field := cfg.GetString(option); field != "" {
+			fid, err := PluckFieldID(qb, tableID, field)
+			HandleError(ctx, logger, option+" option not valid", err)
+			cfg.Set(option, strconv.Itoa(fid))
+			addSelect(cfg, []int{fid})
+		}
+	}
+}
+
+// QueryRecords runs the query prepared by PrepareQueryRecords and returns the
+// records, the values plucked through --pluck, the unique values of the
+// --distinct field, or the estimate of --estimate-only. The estimate is
+// returned instead of the records if the query isn't confirmed.
+func QueryRecords(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *QueryRecordsOptions) (interface{}, error) {
+	input, top := opts.Input, opts.Input.Options.Top
+	pluck, _ := strconv.Atoi(opts.Pluck)
+
+	// Output the unique values of a field across all pages.
+	if distinct, _ := strconv.Atoi(opts.Distinct); distinct > 0 {
+		return Distinct(qb, input, distinct, opts.WithCounts)
+	}
+
+	// Estimate the cost of the query, which is either reported or confirmed
+	// before the query is run.
+	if opts.EstimateOnly || opts.Estimate {
+		est, err := EstimateQuery(qb, input, opts.MaxRecords, !opts.NoPaginate)
+		if opts.EstimateOnly || err != nil {
+			return est, err
+		}
+
+		ok, err := ConfirmEstimate(cfg, est)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			logger.Notice(ctx, "query not run")
+			return est, nil
+		}
+	}
+
+	// Read every page unless pagination is disabled, and notice when records
+	// are left out so they aren't mistaken for missing data. NDJSON is written
+	// as each page is read, unless the whole set is needed to pluck or decode
+	// users.
+	var output *qbclient.QueryRecordsOutput
+	var err error
+	if opts.NoPaginate {
+		output, err = qb.QueryRecords(input)
+	} else if cfg.Format() == FormatNDJSON && pluck == 0 && !cfg.DecodeUsers() {
+		nw, nerr := OpenNDJSON(cfg)
+		if nerr != nil {
+			return nil, nerr
+		}
+		output, err = QueryPages(qb, input, opts.MaxRecords, func(page *qbclient.QueryRecordsOutput) error {
+			werr := nw.WriteRecords(page.Data)
+			page.Data = nil
+			return werr
+		})
+		if cerr := nw.Close(); err == nil {
+			err = cerr
+		}
+	} else {
+		output, err = QueryAllRecords(qb, input, opts.MaxRecords)
+	}
+	if err != nil {
+		return output, err
+	}
+
+	if md := output.Metadata; md != nil && top == 0 && md.Skip+md.NumRecords < md.TotalRecords {
+		mctx := cliutil.ContextWithLogTag(ctx, "returned", strconv.Itoa(md.NumRecords))
+		mctx = cliutil.ContextWithLogTag(mctx, "total", strconv.Itoa(md.TotalRecords))
+		logger.Notice(mctx, "more records match the query than were returned")
+	}
+
+	if cfg.DecodeUsers() {
+		DecodeUsers(ctx, logger, qb, cfg.DefaultAppID(), output.Records)
+	}
+
+	// Output a single field's values.
+	if pluck > 0 {
+		return Pluck(output.Records, pluck)
+	}
+
+	return output, nil
+}
+
+// addSelect adds fields to the select clause in cfg, skipping the fields that
+// are already selected. Invalid select clauses are reported by GetOptions.
+func addSelect(cfg *viper.Viper, fids []int) {
+	sel := cfg.GetString("select")
+	selected := map[int]bool{}
+	existing, _ := cliutil.ParseIntSlice(sel)
+	for _, fid := range existing {
+		selected[fid] = true
+	}
+
+	for _, fid := range fids {
+		if selected[fid] {
+			continue
+		}
+		if sel != "" {
+			sel += ","
+		}
+		sel += strconv.Itoa(fid)
+	}
+	cfg.Set("select", sel)
+}
+
+// applyReportQuery sets the select, where, and sort-by clauses in cfg from a
+// report's query. The report's fields are selected first, followed by the
+// fields passed through --select, and the report's filter is combined with
+// --where by AND. The report's sort order is used unless --sort-by is passed.
+func applyReportQuery(cfg *viper.Viper, query *qbclient.ReportQuery) {
+	sel := cfg.GetString("select")
+	cfg.Set("select", "")
+	addSelect(cfg, query.Fields)
+	if fids, err := cliutil.ParseIntSlice(sel); err == nil {
+		addSelect(cfg, fids)
+	} else {
+		cfg.Set("select", cfg.GetString("select")+","+sel)
+	}
+
+	if query.Filter != "" {
+		where := query.Filter
+		if w := cfg.GetString("where"); w != "" {
+			where = "(" + query.Filter + ")AND(" + w + ")"
+		}
+		cfg.Set("where", where)
+	}
+
+	if cfg.GetString("sort-by") == "" && len(query.SortBy) > 0 {
+		clauses := make([]string, len(query.SortBy))
+		for idx, sb := range query.SortBy {
+			clauses[idx] = strconv.Itoa(sb.FieldID) + " " + sb.Order
+		}
+		cfg.Set("sort-by", strings.Join(clauses, ","))
+	}
+}
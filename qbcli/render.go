@@ -2,15 +2,27 @@ package qbcli
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/QuickBase/quickbase-cli/qbclient"
 	"github.com/QuickBase/quickbase-cli/qberrors"
 	"github.com/cpliakas/cliutil"
 	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/jmespath/go-jmespath"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 // Render renders the output in JSON, or writes an error log.
@@ -23,31 +35,387 @@ func Render(
 	err error,
 ) {
 
+	// Summarize the number of API calls, which is logged at the notice level
+	// when there is a budget so it's visible by default.
+	if len(_clients) > 0 {
+		ctx = cliutil.ContextWithLogTag(ctx, "calls", strconv.Itoa(APICalls()))
+		if cfg.MaxAPICalls() > 0 {
+			logger.Notice(ctx, "api calls made")
+		} else {
+			logger.Info(ctx, "api calls made")
+		}
+	}
+
+	// Log when the retry budget was spent, since later failures weren't
+	// retried.
+	if budget := cfg.RetryBudget(); budget > 0 && RetryWait() >= budget {
+		rctx := cliutil.ContextWithLogTag(ctx, "budget", budget.String())
+		logger.Notice(rctx, "retry budget exhausted")
+	}
+
+	// Nothing is rendered when a dry run skipped a request, since there is
+	// no response.
+	if errors.Is(err, qbclient.ErrDryRun) {
+		return
+	}
+
 	// Render the error.
 	if err != nil {
 		ctx = cliutil.ContextWithLogTag(ctx, "code", fmt.Sprintf("%v", qberrors.StatusCode(err)))
-		HandleError(ctx, logger, qberrors.SafeMessage(err), errors.New(qberrors.SafeDetail(err)))
+		if errors.Is(err, qbclient.ErrTimeout) {
+			hint := fmt.Sprintf("pass --%s with a longer duration, or 0 to disable it", OptionTimeout)
+			ctx = cliutil.ContextWithLogTag(ctx, "hint", hint)
+		}
+		exitError(ctx, logger, qberrors.SafeMessage(err), errors.New(qberrors.SafeDetail(err)), err, ExitCode(err))
 	}
 
-	// Do not return output.
-	if cfg.Quiet() {
-		return
+	// Replace the value objects in JSON output with their values.
+	jv := v
+	if cfg.UnwrapValues() {
+		var uerr error
+		jv, uerr = UnwrapValues(v)
+		HandleError(ctx, logger, "error unwrapping values", uerr)
 	}
 
-	// Try to render a table.
-	if cfg.Format() == "table" || cfg.Format() == "csv" || cfg.Format() == "markdown" {
-		rerr := renderTable(v, cfg.Format())
-		HandleError(ctx, logger, "error rendering table", rerr)
-		return
+	// Binary formats are written to the output file, not stdout.
+	if cfg.Format() == FormatXLSX {
+		rerr := writeXLSX(cfg.OutputFile(), v, cfg)
+		HandleError(ctx, logger, "error writing xlsx file", rerr)
+	} else if !cfg.Quiet() || cfg.OutputFile() != "" {
+
+		// Render the output unless it is suppressed. --quiet only suppresses
+		// stdout, so output written to a file is always rendered.
+		w, oerr := OpenOutput(cfg)
+		HandleError(ctx, logger, "error opening output file", oerr)
+		defer w.Close()
+
+		if cfg.Format() == FormatTable || cfg.Format() == FormatCSV || cfg.Format() == FormatMarkdown {
+			rerr := renderTable(w, v, cfg)
+			HandleError(ctx, logger, "error rendering table", rerr)
+		} else if cfg.Format() == FormatYAML {
+			rerr := printYAMLWithFilter(w, jv, cfg.JMESPathFilters())
+			HandleError(ctx, logger, "error rendering yaml", rerr)
+		} else if cfg.Format() == FormatSQL {
+			rerr := writeSQL(w, v, cfg)
+			HandleError(ctx, logger, "error rendering sql", rerr)
+		} else if cfg.Format() == FormatNDJSON {
+			if !_streamed {
+				rerr := writeNDJSON(w, v, jv, cfg)
+				HandleError(ctx, logger, "error rendering ndjson", rerr)
+			}
+		} else if cfg.Format() == FormatTemplate {
+			rerr := renderTemplate(w, jv, cfg)
+			HandleError(ctx, logger, "error rendering template", rerr)
+		} else {
+			fv, rerr := filterOutput(jv, cfg.JMESPathFilters())
+			HandleError(ctx, logger, "JMESPath filter not valid", rerr)
+			s, rerr := formatJSON(fv, cfg.CompactJSON(w))
+			HandleError(ctx, logger, "error rendering json", rerr)
+			_, rerr = fmt.Fprintln(w, s)
+			HandleError(ctx, logger, "error writing output", rerr)
+		}
 	}
 
-	// Default to rendering JSON.
-	rerr := cliutil.PrintJSONWithFilter(v, cfg.JMESPathFilter())
-	HandleError(ctx, logger, "JMESPath filter not valid", rerr)
+	// Evaluate the post-condition against the response.
+	if expr := cfg.Assert(); expr != "" {
+		actual, aerr := Assert(jv, expr)
+		ctx = cliutil.ContextWithLogTag(ctx, "expression", expr)
+		if b, jerr := json.Marshal(actual); jerr == nil {
+			ctx = cliutil.ContextWithLogTag(ctx, "actual", string(b))
+		}
+		HandleError(ctx, logger, "assertion failed", aerr)
+	}
+
+	// Bulk commands continue past failures in best-effort mode, so the exit
+	// status is set after the output is written.
+	if fc, ok := v.(FailureCounter); ok && fc.Failures() > 0 {
+		HandleError(ctx, logger, "completed with errors", PartialFailureError("%v records or batches failed", fc.Failures()))
+	}
+}
+
+// OpenOutput returns the writer that output is written to, which is the file
+// passed through --output or stdout. Missing directories are created, and an
+// existing file is truncated unless --append was passed.
+func OpenOutput(cfg GlobalConfig) (io.WriteCloser, error) {
+	path := cfg.OutputFile()
+	if path == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if cfg.Append() {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+	return os.OpenFile(path, flag, 0644)
+}
+
+// nopCloser wraps stdout so that closing the output doesn't close it.
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// filterOutput applies the JMESPath filters to v in order, each to the result
+// of the previous one. All filters are compiled before any is evaluated, and
+// errors identify the position of the failing filter when there are several.
+func filterOutput(v interface{}, filters []string) (interface{}, error) {
+	exprs := make([]*jmespath.JMESPath, len(filters))
+	for idx, filter := range filters {
+		expr, err := jmespath.Compile(filter)
+		if err != nil {
+			return nil, qberrors.Client(err).Safef(qberrors.InvalidSyntax, "%s does not compile", filterName(filters, idx))
+		}
+		exprs[idx] = expr
+	}
+
+	for idx, expr := range exprs {
+		var err error
+		if v, err = expr.Search(v); err != nil {
+			return nil, qberrors.Client(err).Safef(qberrors.InvalidSyntax, "%s failed to evaluate", filterName(filters, idx))
+		}
+	}
+
+	return v, nil
+}
+
+// filterName returns the filter at idx as it is named in error messages.
+func filterName(filters []string, idx int) string {
+	if len(filters) == 1 {
+		return fmt.Sprintf("JMESPath filter %q", filters[idx])
+	}
+	return fmt.Sprintf("JMESPath filter %v of %v %q", idx+1, len(filters), filters[idx])
 }
 
-func renderTable(a interface{}, format string) error {
+// formatJSON returns v as indented JSON, or as JSON on a single line if
+// compact is true.
+func formatJSON(v interface{}, compact bool) (string, error) {
+	if !compact {
+		return cliutil.FormatJSON(v)
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// printYAMLWithFilter applies the JMESPath filters and writes v to w as
+// YAML. The structure is the same as the JSON output: v is marshaled to JSON
+// and the document is re-encoded as block-style YAML, which keeps the key order
+// of the JSON encoding, i.e., struct fields in declaration order and map keys
+// sorted, so the output is stable across runs.
+func printYAMLWithFilter(w io.Writer, v interface{}, filters []string) (err error) {
+	if v, err = filterOutput(v, filters); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// JSON is valid YAML, so it is decoded as a node tree to keep the order of
+	// the keys, then its flow style is cleared so it's encoded as block style.
+	var node yaml.Node
+	if err = yaml.Unmarshal(b, &node); err != nil {
+		return err
+	}
+	clearYAMLStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err = enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// clearYAMLStyle resets the style of the node and its children. Strings keep
+// the quoting yaml.v3 would give them when marshaled, so values such as "yes"
+// and "123" aren't read back as booleans or numbers.
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!str" {
+		if b, err := yaml.Marshal(node.Value); err == nil && (b[0] == '"' || b[0] == '\'') {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+	}
+	for _, n := range node.Content {
+		clearYAMLStyle(n)
+	}
+}
+
+// Assert evaluates a JMESPath expression against v, returning the result of
+// the expression and an error if it did not evaluate to boolean true.
+func Assert(v interface{}, expr string) (actual interface{}, err error) {
+	actual, err = jmespath.Search(expr, v)
+	if err != nil {
+		return nil, qberrors.Client(err).Safef(qberrors.InvalidSyntax, "assertion %q", expr)
+	}
+
+	if b, ok := actual.(bool); !ok || !b {
+		err = AssertionFailedError("%s", expr)
+	}
+
+	return
+}
+
+// Format* constants contain the output formats. JSON is rendered by default,
+// and passing FormatJSON explicitly also writes errors as JSON.
+const (
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+	FormatNDJSON   = "ndjson"
+	FormatSQL      = "sql"
+	FormatTable    = "table"
+	FormatTemplate = "template"
+	FormatXLSX     = "xlsx"
+	FormatYAML     = "yaml"
+)
+
+// UnwrapValues converts v to its JSON representation and replaces each
+// {"value": x} object, i.e., the value of a field in a record, with x.
+// JMESPath expressions evaluated against the result use the JSON property
+// names and don't need the trailing .value.
+func UnwrapValues(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+
+	return unwrapValues(data), nil
+}
+
+func unwrapValues(data interface{}) interface{} {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		if val, ok := d["value"]; ok && len(d) == 1 {
+			return unwrapValues(val)
+		}
+		for k, val := range d {
+			d[k] = unwrapValues(val)
+		}
+	case []interface{}:
+		for idx, val := range d {
+			d[idx] = unwrapValues(val)
+		}
+	}
+	return data
+}
+
+// FieldsOrder* constants contain the valid column orders for table output.
+const (
+	FieldsOrderResponse = "response"
+	FieldsOrderSchema   = "schema"
+)
+
+// Tabular is implemented by output that isn't a set of records but can be
+// rendered as a table.
+type Tabular interface {
+
+	// TableHeader returns the column labels of the table.
+	TableHeader() []string
+
+	// TableRows returns the rows of the table.
+	TableRows() [][]string
+}
+
+func renderTable(w io.Writer, a interface{}, cfg GlobalConfig) error {
 	tw := table.NewWriter()
+	formatNumbers := !cfg.NoFormatNumbers()
+	decodeUsers := cfg.DecodeUsers()
+	locale := cfg.ParsedLocale()
+
+	// CSV is read by spreadsheets and ETL tools, so structured values are
+	// flattened to the scalar that identifies them.
+	scalars := cfg.Format() == FormatCSV
+
+	if t, ok := a.(Tabular); ok {
+		columns := appendTabular(tw, t)
+		return writeTable(w, tw, cfg, columns)
+	}
+
+	columns := 0
+	if r, ok := embeddedRecords(a); ok {
+		fields := orderFields(r.Fields, cfg.OutputFieldsOrder())
+		columns = len(fields)
+
+		// map of field ids to index position in the table, and map of
+		// field ids to the field types reported in the metadata.
+		fmap := make(map[int]int, len(fields))
+		tmap := make(map[int]string, len(fields))
+
+		// Add the header.
+		header := make(table.Row, len(fields))
+		for idx, f := range fields {
+			header[idx] = f.Label
+			fmap[f.FieldID] = idx
+			tmap[f.FieldID] = f.Type
+		}
+		tw.AppendHeader(header)
+
+		// Add the table data. Fields missing from a row are empty cells.
+		data := make([]table.Row, len(r.Data))
+		for idx, row := range r.Data {
+			data[idx] = make(table.Row, len(fields))
+			for i := range data[idx] {
+				data[idx][i] = ""
+			}
+			for fid, record := range row {
+				if _, ok := fmap[fid]; !ok {
+					continue
+				}
+				if users, ok := userValueString(record.Value, cfg.ListSeparator()); ok && (decodeUsers || scalars) {
+					data[idx][fmap[fid]] = users
+				} else if name, ok := fileName(record.Value); ok && scalars {
+					data[idx][fmap[fid]] = name
+				} else if formatNumbers && locale != nil {
+					data[idx][fmap[fid]] = locale.FormatValue(record.Value, tmap[fid])
+				} else if formatNumbers {
+					data[idx][fmap[fid]] = formatValue(record.Value, tmap[fid])
+				} else {
+					data[idx][fmap[fid]] = record.Value.String()
+				}
+			}
+		}
+		tw.AppendRows(data)
+	}
+
+	return writeTable(w, tw, cfg, columns)
+}
+
+// fileName returns the name of the latest version of a file attachment,
+// falling back to its URL if the response has no versions. ok is false if the
+// value isn't a file attachment.
+func fileName(v *qbclient.Value) (name string, ok bool) {
+	if v.QuickBaseType != qbclient.FieldFileAttachment {
+		return "", false
+	}
+	if v.File == nil {
+		return "", true
+	}
+
+	latest := 0
+	for _, fv := range v.File.Version {
+		if fv != nil && fv.Version >= latest && fv.FileName != "" {
+			latest, name = fv.Version, fv.FileName
+		}
+	}
+	if name == "" {
+		name = v.File.URL
+	}
+	return name, true
+}
+
+// embeddedRecords returns the Records embedded in a, which must be a pointer
+// to a struct.
+func embeddedRecords(a interface{}) (qbclient.Records, bool) {
 
 	// Only pointers!
 	// This will panic otherwise. This is an internal function, but we whould
@@ -61,44 +429,148 @@ func renderTable(a interface{}, format string) error {
 			continue
 		}
 
-		// Look for the embedded Report field.
-		i := rvf.Interface()
-		switch r := i.(type) {
-		case qbclient.Records:
+		// Look for the embedded Records field.
+		if r, ok := rvf.Interface().(qbclient.Records); ok {
+			return r, true
+		}
+	}
 
-			// map of field ids to index position in the table.
-			fmap := make(map[int]int, len(r.Fields))
+	return qbclient.Records{}, false
+}
 
-			// Add the header.
-			header := make(table.Row, len(r.Fields))
-			for idx, f := range r.Fields {
-				header[idx] = f.Label
-				fmap[f.FieldID] = idx
-			}
-			tw.AppendHeader(header)
+// orderFields orders the columns by field ID when fieldsOrder is schema, which
+// is the order of the fields in the table's schema, so the columns are stable.
+func orderFields(fields []*qbclient.RecordsField, fieldsOrder string) []*qbclient.RecordsField {
+	if fieldsOrder != FieldsOrderSchema {
+		return fields
+	}
 
-			// Add the table data.
-			data := make([]table.Row, len(r.Data))
-			for idx, row := range r.Data {
-				data[idx] = make(table.Row, len(row))
-				for fid, record := range row {
-					data[idx][fmap[fid]] = record.Value.String()
-				}
+	ordered := make([]*qbclient.RecordsField, len(fields))
+	copy(ordered, fields)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].FieldID < ordered[j].FieldID })
+	return ordered
+}
+
+// appendTabular appends the tabular data to the table, returning the number of
+// columns.
+func appendTabular(tw table.Writer, t Tabular) int {
+	labels := t.TableHeader()
+	header := make(table.Row, len(labels))
+	for idx, label := range labels {
+		header[idx] = label
+	}
+	tw.AppendHeader(header)
+
+	for _, r := range t.TableRows() {
+		row := make(table.Row, len(r))
+		for idx, v := range r {
+			row[idx] = v
+		}
+		tw.AppendRow(row)
+	}
+
+	return len(labels)
+}
+
+func writeTable(w io.Writer, tw table.Writer, cfg GlobalConfig, columns int) (err error) {
+	switch format := cfg.Format(); format {
+	case FormatTable:
+		limitColumnWidth(tw, columns, cfg.MaxColWidth(), cfg.Wrap())
+		if colorEnabled(w) {
+			tw.Style().Color = tableColors
+		}
+		_, err = fmt.Fprintln(w, tw.Render())
+	case FormatCSV:
+		_, err = fmt.Fprint(w, withLineEnding(tw.RenderCSV()+"\n", cfg))
+	case FormatMarkdown:
+		_, err = fmt.Fprintln(w, tw.RenderMarkdown())
+	default:
+		err = fmt.Errorf("%s: format not valid", format)
+	}
+
+	return
+}
+
+// withLineEnding returns s with its lines terminated by the --line-endings
+// terminator.
+func withLineEnding(s string, cfg GlobalConfig) string {
+	if cfg.LineEndings() == LineEndingCRLF {
+		return strings.ReplaceAll(s, "\n", "\r\n")
+	}
+	return s
+}
+
+// tableColors are the colors of table output written to a terminal: bold
+// headers, and shaded even-numbered rows so wide tables are easier to scan.
+var tableColors = table.ColorOptions{
+	Header:       text.Colors{text.Bold, text.FgHiCyan},
+	RowAlternate: text.Colors{text.BgHiBlack},
+}
+
+// limitColumnWidth truncates cells longer than width characters with an
+// ellipsis, or wraps them within the column if wrap is true. The width isn't
+// limited if it is 0.
+func limitColumnWidth(tw table.Writer, columns, width int, wrap bool) {
+	if width <= 0 {
+		return
+	}
+
+	configs := make([]table.ColumnConfig, columns)
+	for idx := range configs {
+		configs[idx] = table.ColumnConfig{Number: idx + 1, WidthMax: width}
+		if wrap {
+			configs[idx].WidthMaxEnforcer = text.WrapSoft
+		} else {
+			configs[idx].Transformer = func(val interface{}) string {
+				return text.Snip(fmt.Sprint(val), width, "…")
 			}
-			tw.AppendRows(data)
 		}
 	}
+	tw.SetColumnConfigs(configs)
+}
+
+// formatValue renders a value according to the numeric subtype in the field
+// metadata, e.g., $1,234.56 for currency and 45% for percent fields. All other
+// types are rendered with Value.String.
+func formatValue(v *qbclient.Value, ftype string) string {
+	switch ftype {
+	case qbclient.FieldNumericCurrency:
+		s := "$" + groupThousands(math.Abs(v.Float64), 2)
+		if v.Float64 < 0 {
+			s = "-" + s
+		}
+		return s
+
+	case qbclient.FieldNumericPercent:
+		// Quickbase stores percents as fractions, e.g., 0.45 is 45%.
+		pct := math.Round(v.Float64*100*100) / 100
+		return strconv.FormatFloat(pct, 'f', -1, 64) + "%"
+
+	case qbclient.FieldDuration:
+		return v.Duration.Round(time.Second).String()
 
-	switch format {
-	case "table":
-		fmt.Println(tw.Render())
-	case "csv":
-		fmt.Println(tw.RenderCSV())
-	case "markdown":
-		fmt.Println(tw.RenderMarkdown())
 	default:
-		return fmt.Errorf("%s: format not valid", format)
+		return v.String()
+	}
+}
+
+// groupThousands formats f with the passed number of decimals and groups the
+// integer part with commas.
+func groupThousands(f float64, decimals int) string {
+	s := strconv.FormatFloat(f, 'f', decimals, 64)
+
+	integer, fraction := s, ""
+	if idx := strings.IndexByte(s, '.'); idx >= 0 {
+		integer, fraction = s[:idx], s[idx:]
+	}
+
+	var b strings.Builder
+	for idx, r := range integer {
+		if idx > 0 && (len(integer)-idx)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
 	}
 
-	return nil
+	return b.String() + fraction
 }
@@ -0,0 +1,86 @@
+package qbcli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// SchemaCheckOptions are the options shared by long-running commands that
+// check whether the schema of their tables changed while they ran.
+type SchemaCheckOptions struct {
+	CheckSchema        bool `cliutil:"option=check-schema usage='warn if fields are added, removed, or retyped while the command runs'"`
+	FailOnSchemaChange bool `cliutil:"option=fail-on-schema-change usage='fail if fields are added, removed, or retyped while the command runs'"`
+}
+
+// checkSchema compares the schema of the tables cached when the command
+// started to their current schema. Changes are logged, and an error is
+// returned if FailOnSchemaChange is set. Tables whose schema wasn't cached are
+// skipped.
+func (o SchemaCheckOptions) checkSchema(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, tableIDs ...string) error {
+	if !o.CheckSchema && !o.FailOnSchemaChange {
+		return nil
+	}
+
+	all := []string{}
+	for _, tableID := range tableIDs {
+		before, err := GetCachedTableSchema(tableID)
+		if err != nil {
+			continue
+		}
+
+		current, err := qb.ListFieldsByTableID(tableID)
+		if err != nil {
+			return fmt.Errorf("error checking table schema: %w", err)
+		}
+		after := make(FieldMap, len(current.Fields))
+		for _, f := range current.Fields {
+			after[f.FieldID] = f
+		}
+
+		changes := schemaChanges(before, after)
+		if len(changes) == 0 {
+			continue
+		}
+
+		tctx := cliutil.ContextWithLogTag(ctx, "table", tableID)
+		tctx = cliutil.ContextWithLogTag(tctx, "changes", strings.Join(changes, ", "))
+		logger.Notice(tctx, "schema changed while the command ran")
+
+		for _, c := range changes {
+			all = append(all, tableID+" "+c)
+		}
+	}
+
+	if len(all) > 0 && o.FailOnSchemaChange {
+		return SchemaChangedError("%s", strings.Join(all, ", "))
+	}
+	return nil
+}
+
+// schemaChanges returns the fields that were added, removed, or retyped, in
+// order of field ID.
+func schemaChanges(before, after FieldMap) []string {
+	changes := []string{}
+
+	for _, f := range sortedFields(before) {
+		a, ok := after[f.FieldID]
+		switch {
+		case !ok:
+			changes = append(changes, fmt.Sprintf("field %v (%s) removed", f.FieldID, f.Label))
+		case a.Type != f.Type:
+			changes = append(changes, fmt.Sprintf("field %v (%s) changed from %s to %s", f.FieldID, f.Label, f.Type, a.Type))
+		}
+	}
+
+	for _, f := range sortedFields(after) {
+		if _, ok := before[f.FieldID]; !ok {
+			changes = append(changes, fmt.Sprintf("field %v (%s) added", f.FieldID, f.Label))
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,239 @@
+package qbcli
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// AppSearchOptions are the options read through the command line.
+type AppSearchOptions struct {
+	Name        string `validate:"required" cliutil:"option=name usage='regular expression matched against app names (required)'"`
+	AllProfiles bool   `cliutil:"option=all-profiles usage='search the realms of every profile in the config file'"`
+}
+
+// AppSearchOutput lists the apps that matched the pattern.
+type AppSearchOutput struct {
+	Apps []*AppSearchMatch `json:"apps"`
+}
+
+// AppSearchMatch models an app that matched the pattern.
+type AppSearchMatch struct {
+	Profile       string `json:"profile,omitempty"`
+	RealmHostname string `json:"realmHostname"`
+	AppID         string `json:"appId"`
+	Name          string `json:"name"`
+}
+
+// TableHeader implements Tabular.TableHeader.
+func (o *AppSearchOutput) TableHeader() []string {
+	return []string{"Profile", "Realm", "App ID", "Name"}
+}
+
+// TableRows implements Tabular.TableRows.
+func (o *AppSearchOutput) TableRows() [][]string {
+	rows := make([][]string, len(o.Apps))
+	for idx, a := range o.Apps {
+		rows[idx] = []string{a.Profile, a.RealmHostname, a.AppID, a.Name}
+	}
+	return rows
+}
+
+// AppSearch searches the apps the user has access to for names that match a
+// regular expression. With AllProfiles, the realm of every profile in the
+// config file is searched, and profiles that fail to authenticate are logged
+// and skipped.
+func AppSearch(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *AppSearchOptions) (*AppSearchOutput, error) {
+	output := &AppSearchOutput{Apps: []*AppSearchMatch{}}
+
+	re, err := regexp.Compile(opts.Name)
+	if err != nil {
+		return output, fmt.Errorf("name option not valid: %w", err)
+	}
+
+	if !opts.AllProfiles {
+		matches, err := searchApps(qb, re)
+		for _, m := range matches {
+			m.Profile = cfg.Profile()
+		}
+		output.Apps = matches
+		return output, err
+	}
+
+	config, err := qbclient.ReadConfigFileAt(cfg.ConfigFile())
+	if err != nil {
+		return output, err
+	}
+
+	profiles := make([]string, 0, len(config))
+	for profile := range config {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+
+	for _, profile := range profiles {
+		pctx := cliutil.ContextWithLogTag(ctx, "profile", profile)
+
+		pqb, err := qbclient.NewFromProfile(profile)
+		if err != nil {
+			logger.Error(pctx, "profile skipped", err)
+			continue
+		}
+		pqb.AddPlugin(NewLoggerPlugin(pctx, logger))
+
+		matches, err := searchApps(pqb, re)
+		if err != nil {
+			logger.Error(pctx, "profile skipped", err)
+			continue
+		}
+		for _, m := range matches {
+			m.Profile = profile
+		}
+		output.Apps = append(output.Apps, matches...)
+	}
+
+	return output, nil
+}
+
+// searchApps returns the apps in the client's realm whose names match re.
+func searchApps(qb *qbclient.Client, re *regexp.Regexp) ([]*AppSearchMatch, error) {
+	lao, err := qb.ListApps(&qbclient.ListAppsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing apps: %w", err)
+	}
+
+	matches := []*AppSearchMatch{}
+	for _, db := range lao.Databases {
+		if re.MatchString(db.Name) {
+			matches = append(matches, &AppSearchMatch{
+				RealmHostname: qb.ReamlHostname,
+				AppID:         db.ID,
+				Name:          db.Name,
+			})
+		}
+	}
+	return matches, nil
+}
+
+// Match* constants contain the quality of a records search match, where lower
+// is better.
+const (
+	MatchExact = iota
+	MatchPrefix
+	MatchSubstring
+)
+
+// RecordsSearchOptions are the options read through the command line.
+type RecordsSearchOptions struct {
+	TableID    string `validate:"required" cliutil:"option=table-id"`
+	Field      string `validate:"required" cliutil:"option=field usage='label or ID of the text field that is searched (required)'"`
+	Query      string `validate:"required" cliutil:"option=query usage='term the field must contain (required)'"`
+	Select     []int  `cliutil:"option=select"`
+	SelectFile string `cliutil:"option=select-file usage='file listing the field IDs or labels to select, added to --select'"`
+	Limit      int    `validate:"min=0" cliutil:"option=limit default=25 usage='maximum number of records returned, 0 for unlimited'"`
+	Delay      int    `cliutil:"option=delay"`
+}
+
+// RecordsSearchOutput contains the matching records, ordered by relevance.
+type RecordsSearchOutput struct {
+	qbclient.Records
+}
+
+// RecordsSearch queries the records whose field contains the term and sorts
+// them by how well the field matches: exact matches first, then values that
+// start with the term, then values that contain it. The comparison is
+// case-insensitive, and ties are broken by the shorter value, then by record
+// ID. Sorting is done on the client, so all matches are read.
+func RecordsSearch(qb *qbclient.Client, opts *RecordsSearchOptions) (*RecordsSearchOutput, error) {
+	output := &RecordsSearchOutput{}
+	output.Data = []map[int]*qbclient.RecordsData{}
+
+	fid, err := PluckFieldID(qb, opts.TableID, opts.Field)
+	if err != nil {
+		return output, err
+	}
+
+	extra := opts.Select
+	if opts.SelectFile != "" {
+		if extra, err = withSelectFile(qb, opts.TableID, extra, opts.SelectFile); err != nil {
+			return output, err
+		}
+	}
+
+	sel := []int{3, fid}
+	for _, f := range extra {
+		if f != 3 && f != fid {
+			sel = append(sel, f)
+		}
+	}
+
+	term := strings.ReplaceAll(opts.Query, "'", "\\'")
+	input := &qbclient.QueryRecordsInput{
+		Select: sel,
+		From:   opts.TableID,
+		Where:  fmt.Sprintf("{%v.CT.'%s'}", fid, term),
+	}
+
+	err = QueryRecordsPaged(qb, input, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
+		if output.Fields == nil {
+			output.Fields = qro.Fields
+		}
+		output.Data = append(output.Data, qro.Data...)
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	query := strings.ToLower(opts.Query)
+	sort.SliceStable(output.Data, func(i, j int) bool {
+		vi := strings.ToLower(recordString(output.Data[i], fid))
+		vj := strings.ToLower(recordString(output.Data[j], fid))
+		if mi, mj := matchQuality(vi, query), matchQuality(vj, query); mi != mj {
+			return mi < mj
+		}
+		if len(vi) != len(vj) {
+			return len(vi) < len(vj)
+		}
+		return recordID(output.Data[i]) < recordID(output.Data[j])
+	})
+
+	total := len(output.Data)
+	if opts.Limit > 0 && total > opts.Limit {
+		output.Data = output.Data[:opts.Limit]
+	}
+
+	output.Metadata = &qbclient.RecordsMetadata{
+		TotalRecords: total,
+		NumRecords:   len(output.Data),
+		NumFields:    len(output.Fields),
+	}
+
+	return output, nil
+}
+
+// matchQuality returns how well the value matches the query. Both must be
+// lowercase.
+func matchQuality(value, query string) int {
+	switch {
+	case value == query:
+		return MatchExact
+	case strings.HasPrefix(value, query):
+		return MatchPrefix
+	default:
+		return MatchSubstring
+	}
+}
+
+// recordID returns the record ID of a record, or 0 if it wasn't selected.
+func recordID(record map[int]*qbclient.RecordsData) float64 {
+	if data, ok := record[3]; ok && data.Value != nil {
+		return data.Value.Float64
+	}
+	return 0
+}
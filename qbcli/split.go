@@ -0,0 +1,132 @@
+package qbcli
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// splitMaxNameLength is the maximum length of the sanitized value in the name
+// of a file written by splitWriter.
+const splitMaxNameLength = 100
+
+// splitEmptyName is the name of the file that records with an empty value are
+// written to.
+const splitEmptyName = "_empty"
+
+var reSplitUnsafe = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// splitWriter writes CSV rows to one file per distinct value of a field. Files
+// are opened for each page of rows and closed after it, so the number of open
+// files doesn't grow with the number of values.
+type splitWriter struct {
+	dir    string
+	header []string
+	crlf   bool
+
+	// files maps values to the names of their files, and taken maps the
+	// lowercase file names to the values they were assigned to, since
+	// several file systems are case-insensitive.
+	files map[string]string
+	taken map[string]string
+}
+
+func newSplitWriter(dir string, header []string, crlf bool) (*splitWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating output directory: %w", err)
+	}
+
+	return &splitWriter{
+		dir:    dir,
+		header: header,
+		crlf:   crlf,
+		files:  map[string]string{},
+		taken:  map[string]string{},
+	}, nil
+}
+
+// Write writes the rows, which are keyed by the value of the split field.
+// A file is truncated and its header is written the first time its value is
+// seen, and appended to after that.
+func (w *splitWriter) Write(rows map[string][][]string) error {
+	values := make([]string, 0, len(rows))
+	for v := range rows {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+
+	for _, v := range values {
+		if err := w.write(v, rows[v]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *splitWriter) write(value string, rows [][]string) error {
+	name, seen := w.files[value]
+	flag := os.O_WRONLY | os.O_APPEND
+	if !seen {
+		name = w.fileName(value)
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(filepath.Join(w.dir, name), flag, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer f.Close()
+
+	writer := csv.NewWriter(f)
+	writer.UseCRLF = w.crlf
+	if !seen {
+		writer.Write(w.header)
+	}
+	writer.WriteAll(rows)
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error writing file: %w", err)
+	}
+
+	return f.Close()
+}
+
+// fileName returns the name of the file for a value and reserves it. Unsafe
+// characters are replaced with underscores, and a hash of the value is
+// appended when the value was changed or the name is taken by another value,
+// so two values never share a file and the same value always gets the same
+// name for the same data.
+func (w *splitWriter) fileName(value string) string {
+	name := reSplitUnsafe.ReplaceAllString(value, "_")
+	name = strings.Trim(name, ".")
+	if len(name) > splitMaxNameLength {
+		name = name[:splitMaxNameLength]
+	}
+
+	switch {
+	case value == "":
+		name = splitEmptyName
+	case name != value:
+		name += "-" + shortHash(value)
+	}
+
+	if other, ok := w.taken[strings.ToLower(name)]; ok && other != value {
+		name += "-" + shortHash(value)
+	}
+
+	name += ".csv"
+	w.files[value] = name
+	w.taken[strings.ToLower(strings.TrimSuffix(name, ".csv"))] = value
+	return name
+}
+
+// shortHash returns the first 8 hex characters of the SHA-1 hash of s.
+func shortHash(s string) string {
+	h := sha1.Sum([]byte(s))
+	return hex.EncodeToString(h[:])[:8]
+}
@@ -0,0 +1,174 @@
+package qbcli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+var (
+	reSQLUnsafe = regexp.MustCompile(`[^a-z0-9_]+`)
+	reSQLTable  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+)
+
+// sqlReserved contains the words reserved by both PostgreSQL and MySQL that
+// are likely to be produced from field labels. Column names matching them get
+// a trailing underscore, since the names aren't quoted.
+var sqlReserved = map[string]bool{
+	"all": true, "and": true, "as": true, "asc": true, "by": true, "case": true,
+	"check": true, "column": true, "create": true, "default": true, "desc": true,
+	"distinct": true, "from": true, "group": true, "having": true, "in": true,
+	"into": true, "limit": true, "not": true, "null": true, "on": true,
+	"or": true, "order": true, "references": true, "select": true, "table": true,
+	"to": true, "union": true, "unique": true, "user": true, "when": true,
+	"where": true,
+}
+
+// writeSQL writes the records or tabular data in a as one INSERT statement
+// per row. Numbers are unquoted, checkboxes are TRUE or FALSE, dates and times
+// are quoted ISO 8601 strings in UTC, durations are numbers of milliseconds,
+// and empty values are NULL. Users and file attachments are flattened the same
+// way as in CSV output.
+func writeSQL(w io.Writer, a interface{}, cfg GlobalConfig) error {
+	var columns []string
+	var rows [][]string
+
+	if t, ok := a.(Tabular); ok {
+		columns = sqlColumns(t.TableHeader(), nil)
+		for _, r := range t.TableRows() {
+			row := make([]string, len(r))
+			for idx, v := range r {
+				row[idx] = sqlString(v)
+			}
+			rows = append(rows, row)
+		}
+	} else if r, ok := embeddedRecords(a); ok {
+		fields := orderFields(r.Fields, cfg.OutputFieldsOrder())
+
+		labels := make([]string, len(fields))
+		fids := make([]int, len(fields))
+		for idx, f := range fields {
+			labels[idx], fids[idx] = f.Label, f.FieldID
+		}
+		columns = sqlColumns(labels, fids)
+
+		for _, record := range r.Data {
+			row := make([]string, len(fields))
+			for idx, f := range fields {
+				row[idx] = "NULL"
+				if data, ok := record[f.FieldID]; ok && data.Value != nil {
+					row[idx] = sqlLiteral(data.Value, cfg.ListSeparator())
+				}
+			}
+			rows = append(rows, row)
+		}
+	} else {
+		return fmt.Errorf("%s: format not supported for output", FormatSQL)
+	}
+
+	prefix := "INSERT INTO " + cfg.SQLTable() + " (" + strings.Join(columns, ", ") + ") VALUES ("
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(w, prefix+strings.Join(row, ", ")+");"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sqlColumns returns the column names for the labels. Labels are lowercased
+// and runs of characters other than letters, digits, and underscores are
+// replaced with an underscore. Names that are empty or start with a digit are
+// prefixed, and names that are already taken get the field ID, or the column
+// number for tabular data, appended.
+func sqlColumns(labels []string, fids []int) []string {
+	columns := make([]string, len(labels))
+	taken := make(map[string]bool, len(labels))
+
+	for idx, label := range labels {
+		id := idx + 1
+		if fids != nil {
+			id = fids[idx]
+		}
+
+		name := strings.Trim(reSQLUnsafe.ReplaceAllString(strings.ToLower(label), "_"), "_")
+		switch {
+		case name == "":
+			name = "field_" + strconv.Itoa(id)
+		case name[0] >= '0' && name[0] <= '9':
+			name = "_" + name
+		case sqlReserved[name]:
+			name += "_"
+		}
+		if taken[name] {
+			name += "_" + strconv.Itoa(id)
+		}
+
+		columns[idx] = name
+		taken[name] = true
+	}
+
+	return columns
+}
+
+// sqlLiteral returns the SQL literal of a value.
+func sqlLiteral(v *qbclient.Value, listSeparator string) string {
+	if users, ok := userValueString(v, listSeparator); ok {
+		return sqlString(users)
+	}
+	if name, ok := fileName(v); ok {
+		return sqlString(name)
+	}
+
+	switch v.QuickBaseType {
+	case qbclient.FieldRecordID, qbclient.FieldNumeric, qbclient.FieldNumericCurrency, qbclient.FieldNumericPercent, qbclient.FieldNumericRating:
+		return strconv.FormatFloat(v.Float64, 'f', -1, 64)
+
+	case qbclient.FieldCheckbox:
+		if v.Bool {
+			return "TRUE"
+		}
+		return "FALSE"
+
+	case qbclient.FieldDate:
+		if v.Time.IsZero() {
+			return "NULL"
+		}
+		return sqlString(v.Time.Format(qbclient.FormatDate))
+
+	case qbclient.FieldDateTime:
+		if v.Time.IsZero() {
+			return "NULL"
+		}
+		return sqlString(v.Time.UTC().Format("2006-01-02 15:04:05"))
+
+	case qbclient.FieldTimeOfDay:
+		if v.Time.IsZero() {
+			return "NULL"
+		}
+		return sqlString(v.Time.UTC().Format("15:04:05"))
+
+	case qbclient.FieldDuration:
+		return strconv.FormatInt(v.Duration.Milliseconds(), 10)
+
+	case qbclient.FieldMultiSelectText:
+		return sqlString(strings.Join(v.StrSlice, listSeparator))
+
+	default:
+		return sqlString(v.String())
+	}
+}
+
+// sqlString returns s as a quoted string literal, or NULL if it is empty.
+// Single quotes are doubled, which is the standard escape. Backslashes are
+// left as-is, so MySQL must be run with the NO_BACKSLASH_ESCAPES mode for them
+// to be loaded literally.
+func sqlString(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
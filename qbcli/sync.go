@@ -0,0 +1,267 @@
+package qbcli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// SyncOptions are the options read through the command line.
+type SyncOptions struct {
+	Source        string `validate:"required" cliutil:"option=source usage='unique identifier (dbid) of the source table (required)'"`
+	Dest          string `validate:"required" cliutil:"option=dest usage='unique identifier (dbid) of the destination table (required)'"`
+	KeyField      int    `validate:"required" cliutil:"option=key-field usage='unique field in the destination table that records are matched on (required)'"`
+	DeleteOrphans bool   `cliutil:"option=delete-orphans usage='delete destination records that are not in the source table'"`
+	Since         string `cliutil:"option=since usage='only sync source records modified on or after this date, e.g., 2021-06-01, or after this time'"`
+	Delay         int    `cliutil:"option=delay"`
+	Yes           bool   `cliutil:"option=yes usage='delete orphans without prompting for confirmation'"`
+
+	ErrorModeOptions
+	SchemaCheckOptions
+}
+
+// SyncOutput is the result of syncing two tables.
+type SyncOutput struct {
+	Created    int                 `json:"created"`
+	Updated    int                 `json:"updated"`
+	Unchanged  int                 `json:"unchanged"`
+	Deleted    int                 `json:"deleted"`
+	LineErrors map[string][]string `json:"lineErrors,omitempty"`
+
+	BatchErrors []*BatchError `json:"batchErrors,omitempty"`
+}
+
+// Failures implements FailureCounter.Failures.
+func (o *SyncOutput) Failures() int { return len(o.LineErrors) + len(o.BatchErrors) }
+
+// syncField maps a source field to a destination field with the same label.
+type syncField struct {
+	source int
+	dest   int
+	ftype  string
+}
+
+// Sync replicates records from a source table to a destination table in one
+// direction. Fields are matched by label, and records are matched on the
+// destination's key field. Source records that are new or changed are upserted
+// into the destination, and destination records without a matching source
+// record are optionally deleted.
+func Sync(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *SyncOptions) (*SyncOutput, error) {
+	output, err := syncTables(ctx, logger, qb, cfg, opts)
+	if err != nil {
+		return output, err
+	}
+	return output, opts.checkSchema(ctx, logger, qb, opts.Source, opts.Dest)
+}
+
+func syncTables(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *SyncOptions) (*SyncOutput, error) {
+	output := &SyncOutput{LineErrors: map[string][]string{}, BatchErrors: []*BatchError{}}
+
+	if err := opts.validate(); err != nil {
+		return output, err
+	}
+
+	// Parse the date before any request is sent.
+	since := ""
+	if opts.Since != "" {
+		var err error
+		if since, err = ParseModifiedSince("since", opts.Since); err != nil {
+			return output, err
+		}
+	}
+
+	sfields, err := GetTableSchema(qb, opts.Source)
+	if err != nil {
+		return output, fmt.Errorf("error getting source table metadata: %w", err)
+	}
+	dfields, err := GetTableSchema(qb, opts.Dest)
+	if err != nil {
+		return output, fmt.Errorf("error getting destination table metadata: %w", err)
+	}
+
+	// Map the fields by label, skipping the built-in fields and file
+	// attachments, which cannot be written through the API.
+	labels := newLabelIndex(dfields)
+	key := -1
+	fields := []*syncField{}
+	for _, f := range sortedFields(sfields) {
+		dfid, err := labels.lookup(f.Label)
+		if err != nil {
+			return output, fmt.Errorf("destination table: %w", err)
+		}
+		if dfid == 0 || f.FieldID <= 5 || f.Type == qbclient.FieldFileAttachment {
+			continue
+		}
+		if dfid == opts.KeyField {
+			key = len(fields)
+		}
+		fields = append(fields, &syncField{source: f.FieldID, dest: dfid, ftype: dfields[dfid].Type})
+	}
+	if key == -1 {
+		return output, fmt.Errorf("key field %v not in destination table or has no matching source field", opts.KeyField)
+	}
+
+	// Read the destination records, keyed by the key field.
+	dselect := []int{3}
+	for _, f := range fields {
+		dselect = append(dselect, f.dest)
+	}
+	dest := map[string][]string{}
+	drids := map[string]int{}
+	dinput := &qbclient.QueryRecordsInput{Select: dselect, From: opts.Dest}
+	err = QueryRecordsPaged(qb, dinput, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
+		for _, record := range qro.Data {
+			vals := make([]string, len(fields))
+			for idx, f := range fields {
+				vals[idx] = recordString(record, f.dest)
+			}
+			dest[vals[key]] = vals
+			drids[vals[key]] = int(record[3].Value.Float64)
+		}
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	// Read the source records and upsert the new and changed ones.
+	sselect := []int{}
+	for _, f := range fields {
+		sselect = append(sselect, f.source)
+	}
+	sinput := &qbclient.QueryRecordsInput{Select: sselect, From: opts.Source}
+	sinput.Where = since
+
+	seen := map[string]bool{}
+	records := []map[int]*qbclient.InsertRecordsInputData{}
+	keys := []string{}
+	batch := 0
+
+	err = QueryRecordsPaged(qb, sinput, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
+		for _, record := range qro.Data {
+			vals := make([]string, len(fields))
+			for idx, f := range fields {
+				vals[idx] = recordString(record, f.source)
+			}
+			seen[vals[key]] = true
+
+			if dvals, ok := dest[vals[key]]; ok && strings.Join(dvals, "\x1f") == strings.Join(vals, "\x1f") {
+				output.Unchanged++
+				continue
+			}
+
+			data := make(map[int]*qbclient.InsertRecordsInputData, len(fields))
+			for idx, f := range fields {
+				val, err := qbclient.NewValueFromString(vals[idx], f.ftype)
+				if err != nil {
+					return fmt.Errorf("value invalid for field %v: %w", f.dest, err)
+				}
+				data[f.dest] = &qbclient.InsertRecordsInputData{Value: val}
+			}
+			records = append(records, data)
+			keys = append(keys, vals[key])
+		}
+
+		// Upsert the changes in this page.
+		batch++
+		if err := opts.batchError(&output.BatchErrors, batch, syncUpsert(qb, opts, records, keys, output)); err != nil {
+			return err
+		}
+		if err := opts.lineErrors(output.LineErrors); err != nil {
+			return err
+		}
+		records = records[:0]
+		keys = keys[:0]
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	if !opts.DeleteOrphans {
+		return output, nil
+	}
+
+	// An incremental sync only reads the modified source records, so all
+	// source keys are read to find the orphans.
+	if opts.Since != "" {
+		kinput := &qbclient.QueryRecordsInput{Select: []int{fields[key].source}, From: opts.Source}
+		err = QueryRecordsPaged(qb, kinput, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
+			for _, record := range qro.Data {
+				seen[recordString(record, fields[key].source)] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return output, err
+		}
+	}
+
+	orphans := []int{}
+	for k, rid := range drids {
+		if !seen[k] {
+			orphans = append(orphans, rid)
+		}
+	}
+	if len(orphans) == 0 {
+		return output, nil
+	}
+
+	label := fmt.Sprintf("Delete %v orphaned records from table %s?", len(orphans), opts.Dest)
+	ok, err := ConfirmCount(cfg, label, len(orphans), opts.Yes)
+	if err != nil {
+		return output, err
+	}
+	if !ok {
+		logger.Notice(ctx, "no orphaned records deleted")
+		return output, nil
+	}
+
+	var errs []*BatchError
+	output.Deleted, errs, err = DeleteRecordIDs(qb, opts.Dest, orphans, opts.Delay, opts.ErrorModeOptions)
+	output.BatchErrors = append(output.BatchErrors, errs...)
+	return output, err
+}
+
+// syncUpsert upserts records into the destination table, merging on the key
+// field, and adds the results to output.
+func syncUpsert(qb *qbclient.Client, opts *SyncOptions, records []map[int]*qbclient.InsertRecordsInputData, keys []string, output *SyncOutput) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	iro, err := qb.InsertRecords(&qbclient.InsertRecordsInput{
+		To:           opts.Dest,
+		Data:         records,
+		MergeFieldID: opts.KeyField,
+	})
+	if err != nil {
+		return fmt.Errorf("error upserting records: %w", err)
+	}
+
+	output.Created += len(iro.Metadata.CreatedRecordIDs)
+	output.Updated += len(iro.Metadata.UpdatedRecordIDs)
+	output.Unchanged += len(iro.Metadata.UnchangedRecordIDs)
+
+	// Key the line errors by the value of the key field.
+	for k, v := range iro.Metadata.LineErrors {
+		if pos, err := strconv.Atoi(k); err == nil && pos >= 1 && pos <= len(keys) {
+			k = keys[pos-1]
+		}
+		output.LineErrors[k] = v
+	}
+
+	return nil
+}
+
+// recordString returns the string value of a field in a record.
+func recordString(record map[int]*qbclient.RecordsData, fid int) string {
+	if data, ok := record[fid]; ok && data.Value != nil {
+		return data.Value.String()
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package qbcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qberrors"
+)
+
+// templateFuncs are the helpers available to templates in addition to the
+// text/template builtins. They cover the common string and formatting needs of
+// messages and snippets, similar to a subset of sprig.
+var templateFuncs = template.FuncMap{
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"title":     strings.Title,
+	"trim":      strings.TrimSpace,
+	"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"hasSuffix": func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	"split":     func(sep, s string) []string { return strings.Split(s, sep) },
+	"join": func(sep string, v interface{}) string {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return fmt.Sprint(v)
+		}
+		s := make([]string, rv.Len())
+		for idx := range s {
+			s[idx] = fmt.Sprint(rv.Index(idx).Interface())
+		}
+		return strings.Join(s, sep)
+	},
+	"default": func(def, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return def
+		}
+		return v
+	},
+	"quote": func(v interface{}) string { return fmt.Sprintf("%q", fmt.Sprint(v)) },
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"now": func() string { return time.Now().Format(time.RFC3339) },
+}
+
+// readTemplate returns the template passed through --template, or the
+// contents of the file passed through --template-file.
+func readTemplate(cfg GlobalConfig) (string, error) {
+	if path := cfg.TemplateFile(); path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", qberrors.Client(err).Safef(qberrors.InvalidInput, "error reading template file")
+		}
+		return string(b), nil
+	}
+	return cfg.Template(), nil
+}
+
+// renderTemplate executes the template against the JSON representation of v,
+// so the template uses the same property names as JSON output and the
+// JMESPath filter. The filter is applied first, so the template operates on
+// the filtered data. Numbers are kept as written to avoid float formatting.
+func renderTemplate(w io.Writer, v interface{}, cfg GlobalConfig) error {
+	text, err := readTemplate(cfg)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return qberrors.Client(err).Safef(qberrors.InvalidSyntax, "template not valid")
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var data interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return err
+	}
+
+	if data, err = filterOutput(data, cfg.JMESPathFilters()); err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, data)
+}
@@ -0,0 +1,125 @@
+package qbcli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// TouchOptions are the options read through the command line.
+type TouchOptions struct {
+	TableID string `validate:"required" cliutil:"option=table-id"`
+	Where   string `cliutil:"option=where usage='query that filters the records to touch, all records if empty'"`
+	Field   int    `cliutil:"option=field usage='field that is set to the current time, required if Quickbase reports the records as unchanged'"`
+	Delay   int    `cliutil:"option=delay"`
+	Yes     bool   `cliutil:"option=yes usage='touch the records without prompting for confirmation'"`
+}
+
+// TouchOutput is the result of touching records.
+type TouchOutput struct {
+	Matched    int                 `json:"matched"`
+	Touched    int                 `json:"touched"`
+	Unchanged  int                 `json:"unchanged"`
+	LineErrors map[string][]string `json:"lineErrors,omitempty"`
+}
+
+// Touch upserts the matching records so that their Date Modified field is
+// updated, e.g., to trigger automations. Quickbase doesn't update records
+// whose values don't change, so a field can be passed that is set to the
+// current time.
+func Touch(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *TouchOptions) (*TouchOutput, error) {
+	output := &TouchOutput{LineErrors: map[string][]string{}}
+
+	value := qbclient.NewTextValue(time.Now().UTC().Format(qbclient.FormatDateTime))
+	if opts.Field > 0 {
+		fields, err := GetTableSchema(qb, opts.TableID)
+		if err != nil {
+			return output, fmt.Errorf("error getting table metadata: %w", err)
+		}
+		f, ok := fields[opts.Field]
+		if !ok {
+			return output, fmt.Errorf("field %v not in table %s", opts.Field, opts.TableID)
+		}
+		if value, err = qbclient.NewValueFromString(value.Str, f.Type); err != nil {
+			return output, fmt.Errorf("field %v: %w", opts.Field, err)
+		}
+	}
+
+	// Find the records to touch.
+	rids := []int{}
+	input := &qbclient.QueryRecordsInput{Select: []int{3}, From: opts.TableID, Where: opts.Where}
+	err := QueryRecordsPaged(qb, input, DefaultBulkBatchSize, opts.Delay, func(qro *qbclient.QueryRecordsOutput) error {
+		for _, record := range qro.Data {
+			rids = append(rids, int(record[3].Value.Float64))
+		}
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	output.Matched = len(rids)
+	if output.Matched == 0 {
+		return output, nil
+	}
+
+	label := fmt.Sprintf("Touch %v records in table %s?", output.Matched, opts.TableID)
+	ok, err := ConfirmCount(cfg, label, output.Matched, opts.Yes)
+	if err != nil {
+		return output, err
+	}
+	if !ok {
+		logger.Notice(ctx, "no records touched")
+		return output, nil
+	}
+
+	// Upsert the records in batches, merging on the record ID.
+	size := batchSize(DefaultBulkBatchSize)
+	for start := 0; start < len(rids); start += size {
+		end := start + size
+		if end > len(rids) {
+			end = len(rids)
+		}
+
+		data := make([]map[int]*qbclient.InsertRecordsInputData, end-start)
+		for idx, rid := range rids[start:end] {
+			data[idx] = map[int]*qbclient.InsertRecordsInputData{
+				3: {Value: qbclient.NewRecordIDValue(float64(rid))},
+			}
+			if opts.Field > 0 {
+				data[idx][opts.Field] = &qbclient.InsertRecordsInputData{Value: value}
+			}
+		}
+
+		iro, err := qb.InsertRecords(&qbclient.InsertRecordsInput{
+			To:           opts.TableID,
+			Data:         data,
+			MergeFieldID: 3,
+		})
+		if err != nil {
+			return output, fmt.Errorf("error touching records: %w", err)
+		}
+
+		output.Touched += len(iro.Metadata.UpdatedRecordIDs)
+		output.Unchanged += len(iro.Metadata.UnchangedRecordIDs)
+		for k, v := range iro.Metadata.LineErrors {
+			if pos, err := strconv.Atoi(k); err == nil && pos >= 1 && pos <= end-start {
+				k = strconv.Itoa(rids[start+pos-1])
+			}
+			output.LineErrors[k] = v
+		}
+
+		// Delay before the next API call.
+		if end < len(rids) {
+			pauseBatch(opts.Delay)
+		}
+	}
+
+	logger.Notice(cliutil.ContextWithLogTag(ctx, "touched", strconv.Itoa(output.Touched)), "records touched")
+
+	return output, nil
+}
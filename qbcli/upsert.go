@@ -0,0 +1,145 @@
+package qbcli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+	"github.com/cpliakas/cliutil"
+)
+
+// UpsertOptions are the options read through the command line.
+type UpsertOptions struct {
+	To       string                                     `validate:"required" cliutil:"option=to"`
+	KeyField int                                        `validate:"required" cliutil:"option=key-field usage='unique field that records are matched on, records are created if their value matches none (required)'"`
+	Data     []map[int]*qbclient.InsertRecordsInputData `cliutil:"option=data func=record usage='record to insert or update, in the same format as records insert'"`
+
+	InsertCSVOptions
+	AttachOptions
+
+	// Files are the files passed through --file as fieldID=path pairs.
+	Files []string
+}
+
+// UpsertOutput is the result of upserting records.
+type UpsertOutput struct {
+	Created    int                 `json:"created"`
+	Updated    int                 `json:"updated"`
+	Unchanged  int                 `json:"unchanged"`
+	LineErrors map[string][]string `json:"lineErrors,omitempty"`
+
+	BatchErrors []*BatchError `json:"batchErrors,omitempty"`
+}
+
+// Failures implements FailureCounter.Failures.
+func (o *UpsertOutput) Failures() int { return len(o.LineErrors) + len(o.BatchErrors) }
+
+// TableHeader implements Tabular.TableHeader.
+func (o *UpsertOutput) TableHeader() []string {
+	return []string{"Created", "Updated", "Unchanged", "Errors"}
+}
+
+// TableRows implements Tabular.TableRows.
+func (o *UpsertOutput) TableRows() [][]string {
+	return [][]string{{
+		strconv.Itoa(o.Created),
+		strconv.Itoa(o.Updated),
+		strconv.Itoa(o.Unchanged),
+		strconv.Itoa(o.Failures()),
+	}}
+}
+
+// add adds the counts in the metadata returned by the API to the output.
+func (o *UpsertOutput) add(metadata *qbclient.InsertRecordsOutputMetadata) {
+	if metadata == nil {
+		return
+	}
+	o.Created += len(metadata.CreatedRecordIDs)
+	o.Updated += len(metadata.UpdatedRecordIDs)
+	o.Unchanged += len(metadata.UnchangedRecordIDs)
+	for k, v := range metadata.LineErrors {
+		o.LineErrors[k] = v
+	}
+}
+
+// Upsert inserts records into a table, or updates them if their value of the
+// key field matches an existing record's. The record is read from opts.Data,
+// or the records are read from opts.CSVFile if it is passed, in which case
+// they are inserted in batches as by InsertCSV. The key field must be Record
+// ID# or a unique field, which is checked before any records are sent.
+func Upsert(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, cfg GlobalConfig, opts *UpsertOptions) (*UpsertOutput, error) {
+	output := &UpsertOutput{LineErrors: map[string][]string{}, BatchErrors: []*BatchError{}}
+
+	schema, err := GetTableSchema(qb, opts.To)
+	if err != nil {
+		return output, fmt.Errorf("error getting table metadata: %w", err)
+	}
+	if err := validateKeyField(schema, opts.KeyField); err != nil {
+		return output, err
+	}
+
+	if opts.CSVFile != "" {
+		if len(opts.Files) > 0 {
+			return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "option %q can't be combined with %q", OptionFile, "csv-file")
+		}
+		imported, err := InsertCSV(ctx, logger, qb, cfg, opts.To, opts.KeyField, &opts.InsertCSVOptions)
+		output.add(imported.InsertRecordsOutputMetadata)
+		output.BatchErrors = imported.BatchErrors
+		return output, err
+	}
+
+	if len(opts.Data) > 0 && len(opts.Files) > 0 {
+		if err := AttachFiles(schema, opts.Data[0], opts.Files, opts.MaxFileSize); err != nil {
+			return output, err
+		}
+	}
+
+	if len(opts.Data) == 0 || len(opts.Data[0]) == 0 {
+		return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "option %q or %q required", "data", "csv-file")
+	}
+	if _, ok := opts.Data[0][opts.KeyField]; !ok {
+		return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "key field %v not in option %q", opts.KeyField, "data")
+	}
+
+	label := fmt.Sprintf("Upsert %v records into table %s?", len(opts.Data), opts.To)
+	ok, err := ConfirmCount(cfg, label, len(opts.Data), true)
+	if err != nil {
+		return output, err
+	}
+	if !ok {
+		logger.Notice(ctx, "no records upserted")
+		return output, nil
+	}
+
+	iro, err := qb.InsertRecords(&qbclient.InsertRecordsInput{
+		To:           opts.To,
+		Data:         opts.Data,
+		MergeFieldID: opts.KeyField,
+	})
+	if err != nil {
+		return output, fmt.Errorf("error upserting records: %w", err)
+	}
+	output.add(iro.Metadata)
+
+	ctx = cliutil.ContextWithLogTag(ctx, "created", strconv.Itoa(output.Created))
+	ctx = cliutil.ContextWithLogTag(ctx, "updated", strconv.Itoa(output.Updated))
+	logger.Info(ctx, "records upserted")
+
+	return output, nil
+}
+
+// validateKeyField returns an error unless the field is in the table and is
+// either Record ID# or a unique field, since the API can only match records
+// on those.
+func validateKeyField(schema FieldMap, fid int) error {
+	f, ok := schema[fid]
+	switch {
+	case !ok:
+		return qberrors.Client(nil).Safef(qberrors.InvalidInput, "key field %v not in table", fid)
+	case fid != 3 && !f.Unique:
+		return qberrors.Client(nil).Safef(qberrors.InvalidInput, "key field %v (%s) is not unique, so records can't be matched on it", fid, f.Label)
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package qbcli
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// FieldReference* constants contain the types of references to a field.
+const (
+	FieldReferenceFormula      = "formula"
+	FieldReferenceLookup       = "lookup"
+	FieldReferenceRelationship = "relationship"
+	FieldReferenceReport       = "report"
+	FieldReferenceSummary      = "summary"
+)
+
+// FieldUsageOptions are the options read through the command line.
+type FieldUsageOptions struct {
+	TableConcurrencyOptions
+
+	AppID   string `cliutil:"option=app-id usage='unique identifier of the app, required to find lookups in child tables'"`
+	TableID string `validate:"required" cliutil:"option=table-id"`
+	FieldID int    `validate:"required" cliutil:"option=field-id"`
+}
+
+// FieldUsageOutput lists the references to a field.
+type FieldUsageOutput struct {
+	TableID    string            `json:"tableId"`
+	FieldID    int               `json:"fieldId"`
+	Label      string            `json:"label"`
+	References []*FieldReference `json:"references"`
+	Errors     []*TableError     `json:"errors,omitempty"`
+}
+
+// FieldReference models a reference to a field.
+type FieldReference struct {
+	Type     string `json:"type"`
+	TableID  string `json:"tableId"`
+	FieldID  int    `json:"fieldId,omitempty"`
+	ReportID string `json:"reportId,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// FieldUsage finds references to a field in formulas, reports, and
+// relationships. Lookup fields in child tables are only found when the app ID
+// is passed, since relationships are listed by child table.
+func FieldUsage(qb *qbclient.Client, opts *FieldUsageOptions) (*FieldUsageOutput, error) {
+	output := &FieldUsageOutput{
+		TableID:    opts.TableID,
+		FieldID:    opts.FieldID,
+		References: []*FieldReference{},
+	}
+
+	fields, err := GetTableSchema(qb, opts.TableID)
+	if err != nil {
+		return output, fmt.Errorf("error getting table metadata: %w", err)
+	}
+
+	field, ok := fields[opts.FieldID]
+	if !ok {
+		return output, fmt.Errorf("field %v not in table %s", opts.FieldID, opts.TableID)
+	}
+	output.Label = field.Label
+	token := "[" + field.Label + "]"
+
+	// Formula and lookup fields in the same table.
+	for _, f := range sortedFields(fields) {
+		if f.FieldID == opts.FieldID || f.Properties == nil {
+			continue
+		}
+		if strings.Contains(f.Properties.Formula, token) {
+			output.References = append(output.References, &FieldReference{
+				Type:    FieldReferenceFormula,
+				TableID: opts.TableID,
+				FieldID: f.FieldID,
+				Name:    f.Label,
+			})
+		}
+		if f.Properties.LookupReferenceFieldID == opts.FieldID {
+			output.References = append(output.References, &FieldReference{
+				Type:    FieldReferenceLookup,
+				TableID: opts.TableID,
+				FieldID: f.FieldID,
+				Name:    f.Label,
+				Detail:  "reference field",
+			})
+		}
+	}
+
+	// Reports in the same table.
+	lro, err := qb.ListReports(&qbclient.ListReportsInput{TableID: opts.TableID})
+	if err != nil {
+		return output, fmt.Errorf("error listing reports: %w", err)
+	}
+	for _, report := range lro.Reports {
+		for _, detail := range reportUsage(&report.Report, opts.FieldID, token) {
+			output.References = append(output.References, &FieldReference{
+				Type:     FieldReferenceReport,
+				TableID:  opts.TableID,
+				ReportID: report.ReportID,
+				Name:     report.Name,
+				Detail:   detail,
+			})
+		}
+	}
+
+	// Relationships where the table is the child, i.e., the field is the
+	// foreign key or is summarized in the parent table.
+	rels, err := qb.ListRelationshipsByTableID(opts.TableID)
+	if err != nil {
+		return output, fmt.Errorf("error listing relationships: %w", err)
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ForeignKeyField != nil && rel.ForeignKeyField.FieldID == opts.FieldID {
+			output.References = append(output.References, &FieldReference{
+				Type:    FieldReferenceRelationship,
+				TableID: rel.ParentTableID,
+				Name:    "relationship " + strconv.Itoa(rel.RelationshipID),
+				Detail:  "foreign key",
+			})
+		}
+		if len(rel.SummaryFields) == 0 {
+			continue
+		}
+
+		parent, err := GetTableSchema(qb, rel.ParentTableID)
+		if err != nil {
+			return output, fmt.Errorf("error getting table metadata: %w", err)
+		}
+		for _, sf := range rel.SummaryFields {
+			if f, ok := parent[sf.FieldID]; ok && f.Properties != nil && f.Properties.SummaryTargetFieldID == opts.FieldID {
+				output.References = append(output.References, &FieldReference{
+					Type:    FieldReferenceSummary,
+					TableID: rel.ParentTableID,
+					FieldID: f.FieldID,
+					Name:    f.Label,
+				})
+			}
+		}
+	}
+
+	if opts.AppID == "" {
+		return output, nil
+	}
+
+	// Lookup fields in child tables that pull the field from the parent. The
+	// tables are scanned in parallel and aggregated in the order of the tables.
+	tables, err := qb.ListTablesByAppID(opts.AppID)
+	if err != nil {
+		return output, fmt.Errorf("error listing tables: %w", err)
+	}
+	refs := make([][]*FieldReference, len(tables.Tables))
+	output.Errors, err = forEachTable(tables.Tables, opts.TableConcurrencyOptions, func(idx int, table *qbclient.ListTablesOutputTable) error {
+		if table.TableID == opts.TableID {
+			return nil
+		}
+
+		rels, err := qb.ListRelationshipsByTableID(table.TableID)
+		if err != nil {
+			return fmt.Errorf("error listing relationships: %w", err)
+		}
+		for _, rel := range rels.Relationships {
+			if rel.ParentTableID != opts.TableID || len(rel.LookupFields) == 0 {
+				continue
+			}
+
+			child, err := GetTableSchema(qb, table.TableID)
+			if err != nil {
+				return fmt.Errorf("error getting table metadata: %w", err)
+			}
+			for _, lf := range rel.LookupFields {
+				if f, ok := child[lf.FieldID]; ok && f.Properties != nil && f.Properties.LookupTargetFieldID == opts.FieldID {
+					refs[idx] = append(refs[idx], &FieldReference{
+						Type:    FieldReferenceLookup,
+						TableID: table.TableID,
+						FieldID: f.FieldID,
+						Name:    f.Label,
+					})
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return output, err
+	}
+
+	for _, r := range refs {
+		output.References = append(output.References, r...)
+	}
+
+	return output, nil
+}
+
+// reportUsage returns the parts of a report that reference the field.
+func reportUsage(report *qbclient.Report, fid int, token string) []string {
+	usage := []string{}
+	if report.Query == nil {
+		return usage
+	}
+	q := report.Query
+
+	for _, id := range q.Fields {
+		if id == fid {
+			usage = append(usage, "columns")
+			break
+		}
+	}
+	if strings.Contains(q.Filter, "{"+strconv.Itoa(fid)+".") {
+		usage = append(usage, "filter")
+	}
+	for _, sb := range q.SortBy {
+		if sb.FieldID == fid {
+			usage = append(usage, "sort")
+			break
+		}
+	}
+	for _, gb := range q.GroupBy {
+		if gb.FieldID == fid {
+			usage = append(usage, "group")
+			break
+		}
+	}
+	for _, ff := range q.FormulaFields {
+		if strings.Contains(ff.Formula, token) {
+			usage = append(usage, "formula column "+ff.Label)
+		}
+	}
+
+	return usage
+}
+
+// sortedFields returns the fields sorted by field ID.
+func sortedFields(fields FieldMap) []*qbclient.ListFieldsOutputField {
+	fids := make([]int, 0, len(fields))
+	for fid := range fields {
+		fids = append(fids, fid)
+	}
+	sort.Ints(fids)
+
+	sorted := make([]*qbclient.ListFieldsOutputField, len(fids))
+	for idx, fid := range fids {
+		sorted[idx] = fields[fid]
+	}
+	return sorted
+}
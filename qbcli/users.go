@@ -0,0 +1,153 @@
+package qbcli
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/cpliakas/cliutil"
+)
+
+// _users caches the users looked up by DecodeUsers, keyed by ID. _usersApps
+// tracks the apps whose users have been loaded, where "" is the realm.
+var (
+	_users     = map[string]*qbclient.User{}
+	_usersApps = map[string]bool{}
+	_usersMu   sync.Mutex
+)
+
+// DecodeUsers fills in the email and name of the users in the records' user
+// and user list fields. The API usually returns them, but some users are
+// returned as an ID only, in which case all users of the app, or of the realm
+// if appID is empty, are looked up in pages and cached.
+//
+// Users that can't be found, e.g., because they are deactivated, are left as
+// a raw ID. Lookup errors are logged rather than returned, since the records
+// are still valid without the decoded users.
+func DecodeUsers(ctx context.Context, logger *cliutil.LeveledLogger, qb *qbclient.Client, appID string, records qbclient.Records) {
+	users := recordUsers(records)
+
+	missing := false
+	for _, u := range users {
+		if u.Email == "" || u.Name == "" {
+			missing = true
+			break
+		}
+	}
+	if !missing {
+		return
+	}
+
+	if err := loadUsers(qb, appID); err != nil {
+		logger.Notice(cliutil.ContextWithLogTag(ctx, "error", err.Error()), "users not decoded")
+		return
+	}
+
+	_usersMu.Lock()
+	defer _usersMu.Unlock()
+	for _, u := range users {
+		if cached, ok := _users[u.ID]; ok {
+			if u.Email == "" {
+				u.Email = cached.Email
+			}
+			if u.Name == "" {
+				u.Name = cached.Name
+			}
+		}
+	}
+}
+
+// recordUsers returns the users in the records.
+func recordUsers(records qbclient.Records) []*qbclient.User {
+	users := []*qbclient.User{}
+	for _, record := range records.Data {
+		for _, data := range record {
+			if data.Value == nil {
+				continue
+			}
+			switch data.Value.QuickBaseType {
+			case qbclient.FieldUser:
+				if data.Value.User != nil && data.Value.User.ID != "" {
+					users = append(users, data.Value.User)
+				}
+			case qbclient.FieldUserList:
+				for _, u := range data.Value.UserSlice {
+					if u != nil && u.ID != "" {
+						users = append(users, u)
+					}
+				}
+			}
+		}
+	}
+	return users
+}
+
+// loadUsers pages through the users of the app and caches them.
+func loadUsers(qb *qbclient.Client, appID string) error {
+	_usersMu.Lock()
+	defer _usersMu.Unlock()
+
+	if _usersApps[appID] {
+		return nil
+	}
+
+	input := &qbclient.GetUsersInput{}
+	if appID != "" {
+		input.AppIDs = []string{appID}
+	}
+
+	for {
+		output, err := qb.GetUsers(input)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range output.Users {
+			_users[u.HashID] = &qbclient.User{
+				ID:    u.HashID,
+				Email: u.EmailAddress,
+				Name:  strings.TrimSpace(u.FirstName + " " + u.LastName),
+			}
+		}
+
+		if output.Metadata == nil || output.Metadata.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = output.Metadata.NextPageToken
+	}
+
+	_usersApps[appID] = true
+	return nil
+}
+
+// userValueString returns the emails of the users in a user or user list
+// value, falling back to the IDs of the users without an email. List values
+// are joined with sep. ok is false if the value isn't a user type.
+func userValueString(v *qbclient.Value, sep string) (s string, ok bool) {
+	switch v.QuickBaseType {
+	case qbclient.FieldUser:
+		return userString(v.User), true
+
+	case qbclient.FieldUserList:
+		users := make([]string, len(v.UserSlice))
+		for idx, u := range v.UserSlice {
+			users[idx] = userString(u)
+		}
+		return strings.Join(users, sep), true
+
+	default:
+		return "", false
+	}
+}
+
+// userString returns the email of a user if it is known, and the ID otherwise.
+func userString(u *qbclient.User) string {
+	if u == nil {
+		return ""
+	}
+	if u.Email != "" {
+		return u.Email
+	}
+	return u.ID
+}
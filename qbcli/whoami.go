@@ -0,0 +1,101 @@
+package qbcli
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/QuickBase/quickbase-cli/qberrors"
+)
+
+// WhoAmIOutput is the result of verifying the configured credential. The
+// token is masked, so the output is safe to share.
+type WhoAmIOutput struct {
+	Profile       string `json:"profile"`
+	RealmHostname string `json:"realm_hostname"`
+	AuthMethod    string `json:"auth_method"`
+	Credential    string `json:"credential,omitempty"`
+	Check         string `json:"check,omitempty"`
+	Authenticated bool   `json:"authenticated"`
+}
+
+// TableHeader implements Tabular.TableHeader.
+func (o *WhoAmIOutput) TableHeader() []string {
+	return []string{"Profile", "Realm Hostname", "Auth Method", "Authenticated"}
+}
+
+// TableRows implements Tabular.TableRows.
+func (o *WhoAmIOutput) TableRows() [][]string {
+	return [][]string{{o.Profile, o.RealmHostname, o.AuthMethod, strconv.FormatBool(o.Authenticated)}}
+}
+
+// WhoAmI verifies the credential resolved from the configuration by making a
+// lightweight authenticated request. A user token lists the user's apps in the
+// realm through API_GrantedDBs. A temporary token is scoped to an app, so the
+// default app is read instead, which requires --app-id. The output describes
+// the credential whether or not the request succeeded, and the error tells a
+// wrong realm hostname apart from an invalid token and a network error.
+func WhoAmI(qb *qbclient.Client, cfg GlobalConfig) (*WhoAmIOutput, error) {
+	output := &WhoAmIOutput{
+		Profile:       cfg.Profile(),
+		RealmHostname: cfg.RealmHostname(),
+		AuthMethod:    AuthNone,
+	}
+
+	method, err := qbclient.SelectAuthMethod(cfg.AuthMethod(), cfg.UserToken(), cfg.TemporaryToken())
+	if err != nil {
+		return output, err
+	}
+
+	switch method {
+	case qbclient.AuthMethodUser:
+		output.AuthMethod = AuthUserToken
+		output.Credential = qbclient.MaskToken(cfg.UserToken())
+		output.Check = "API_GrantedDBs"
+		_, err = qb.ListApps(&qbclient.ListAppsInput{RealmAppsOnly: true, ExcludeParents: true})
+
+	case qbclient.AuthMethodTemporary:
+		output.AuthMethod = AuthTemporaryToken
+		output.Credential = qbclient.MaskToken(cfg.TemporaryToken())
+		if cfg.DefaultAppID() == "" {
+			return output, qberrors.Client(nil).Safef(qberrors.InvalidInput, "option %q required to verify a temporary token, which is scoped to an app", qbclient.OptionAppID)
+		}
+		output.Check = "GET /v1/apps/" + cfg.DefaultAppID()
+		_, err = qb.GetAppByID(cfg.DefaultAppID())
+
+	default:
+		return output, InvalidTokenError(nil, "no user or temporary token configured for realm %s", output.RealmHostname)
+	}
+
+	if err != nil {
+		return output, authError(output, err)
+	}
+
+	output.Authenticated = true
+	return output, nil
+}
+
+// authError classifies the error of a failed authentication check. A hostname
+// that doesn't resolve, or an API error that mentions the realm, means the
+// realm hostname is wrong, and other 401 and 403 responses mean the token was
+// rejected. Requests that got no response are network errors. Anything else,
+// e.g., a 404 for an app that doesn't exist, is returned as-is.
+func authError(output *WhoAmIOutput, err error) error {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+
+	switch status := qberrors.StatusCode(err); {
+	case causeAs(err, &dnsErr) && dnsErr.IsNotFound:
+		return WrongRealmError(err, "%s does not resolve, check --realm-hostname", output.RealmHostname)
+	case (status == http.StatusUnauthorized || status == http.StatusForbidden) && strings.Contains(strings.ToLower(err.Error()), "realm"):
+		return WrongRealmError(err, "%s rejected the request (%s), check --realm-hostname", output.RealmHostname, err)
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return InvalidTokenError(err, "%s %s rejected by %s (%s)", output.AuthMethod, output.Credential, output.RealmHostname, err)
+	case errors.Is(err, qbclient.ErrTimeout) || causeAs(err, &netErr):
+		return UnreachableError(err, "no response from %s (%s)", output.RealmHostname, qberrors.Upstream(err))
+	}
+	return err
+}
@@ -0,0 +1,268 @@
+package qbcli
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+// Style indexes in the cellXfs element of the xlsx stylesheet.
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleHeader
+	xlsxStyleDate
+	xlsxStyleDateTime
+	xlsxStyleTimeOfDay
+	xlsxStyleDuration
+)
+
+// xlsxEpoch is day zero of Excel's date serial numbers.
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxCell is a cell in a worksheet.
+type xlsxCell struct {
+	value string
+	style int
+
+	// typ is the cell type, i.e., "n" for numbers, "b" for booleans, and
+	// "inlineStr" for strings.
+	typ string
+}
+
+// writeXLSX writes the records or tabular data in a to a native Excel
+// workbook. The header row contains the field labels and is frozen, and
+// numbers, checkboxes, dates, and durations are written as typed cells.
+func writeXLSX(path string, a interface{}, cfg GlobalConfig) error {
+	var rows [][]*xlsxCell
+
+	if t, ok := a.(Tabular); ok {
+		rows = append(rows, xlsxHeader(t.TableHeader()))
+		for _, r := range t.TableRows() {
+			row := make([]*xlsxCell, len(r))
+			for idx, v := range r {
+				row[idx] = &xlsxCell{value: v, typ: "inlineStr"}
+			}
+			rows = append(rows, row)
+		}
+	} else if r, ok := embeddedRecords(a); ok {
+		fields := orderFields(r.Fields, cfg.OutputFieldsOrder())
+
+		labels := make([]string, len(fields))
+		for idx, f := range fields {
+			labels[idx] = f.Label
+		}
+		rows = append(rows, xlsxHeader(labels))
+
+		for _, record := range r.Data {
+			row := make([]*xlsxCell, len(fields))
+			for idx, f := range fields {
+				row[idx] = &xlsxCell{typ: "inlineStr"}
+				if data, ok := record[f.FieldID]; ok && data.Value != nil {
+					row[idx] = newXLSXCell(data.Value, cfg.ListSeparator(), cfg.DecodeUsers())
+				}
+			}
+			rows = append(rows, row)
+		}
+	} else {
+		return fmt.Errorf("%s: format not supported for output", FormatXLSX)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error creating directory: %w", err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles(cfg.ParsedLocale())},
+		{"xl/worksheets/sheet1.xml", xlsxSheet(rows)},
+	}
+	for _, p := range parts {
+		w, err := zw.Create(p.name)
+		if err != nil {
+			return fmt.Errorf("error writing xlsx: %w", err)
+		}
+		if _, err := w.Write([]byte(p.body)); err != nil {
+			return fmt.Errorf("error writing xlsx: %w", err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error writing xlsx: %w", err)
+	}
+	return f.Close()
+}
+
+// xlsxHeader returns the header row.
+func xlsxHeader(labels []string) []*xlsxCell {
+	row := make([]*xlsxCell, len(labels))
+	for idx, label := range labels {
+		row[idx] = &xlsxCell{value: label, style: xlsxStyleHeader, typ: "inlineStr"}
+	}
+	return row
+}
+
+// newXLSXCell returns a typed cell for a value. Users are written as emails
+// if decodeUsers is true.
+func newXLSXCell(v *qbclient.Value, listSeparator string, decodeUsers bool) *xlsxCell {
+	if users, ok := userValueString(v, listSeparator); ok && decodeUsers {
+		return &xlsxCell{value: users, typ: "inlineStr"}
+	}
+
+	switch v.QuickBaseType {
+	case qbclient.FieldRecordID, qbclient.FieldNumeric, qbclient.FieldNumericCurrency, qbclient.FieldNumericPercent, qbclient.FieldNumericRating:
+		return &xlsxCell{value: strconv.FormatFloat(v.Float64, 'f', -1, 64), typ: "n"}
+
+	case qbclient.FieldCheckbox:
+		b := "0"
+		if v.Bool {
+			b = "1"
+		}
+		return &xlsxCell{value: b, typ: "b"}
+
+	case qbclient.FieldDate:
+		return &xlsxCell{value: xlsxSerial(v.Time), style: xlsxStyleDate, typ: "n"}
+
+	case qbclient.FieldDateTime:
+		return &xlsxCell{value: xlsxSerial(v.Time), style: xlsxStyleDateTime, typ: "n"}
+
+	case qbclient.FieldTimeOfDay:
+		t := v.Time.UTC()
+		d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+		return &xlsxCell{value: xlsxDays(d), style: xlsxStyleTimeOfDay, typ: "n"}
+
+	case qbclient.FieldDuration:
+		return &xlsxCell{value: xlsxDays(v.Duration), style: xlsxStyleDuration, typ: "n"}
+
+	case qbclient.FieldMultiSelectText:
+		return &xlsxCell{value: strings.Join(v.StrSlice, listSeparator), typ: "inlineStr"}
+
+	case qbclient.FieldUserList:
+		ids := make([]string, len(v.UserSlice))
+		for idx, u := range v.UserSlice {
+			ids[idx] = u.ID
+		}
+		return &xlsxCell{value: strings.Join(ids, listSeparator), typ: "inlineStr"}
+
+	default:
+		return &xlsxCell{value: v.String(), typ: "inlineStr"}
+	}
+}
+
+// xlsxSerial returns the Excel date serial number of t.
+func xlsxSerial(t time.Time) string {
+	return xlsxDays(t.UTC().Sub(xlsxEpoch))
+}
+
+// xlsxDays returns the duration as a fractional number of days.
+func xlsxDays(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours()/24, 'f', -1, 64)
+}
+
+// xlsxColumn returns the column name of a zero-based index, e.g., 0 is A and
+// 26 is AA.
+func xlsxColumn(idx int) string {
+	name := ""
+	for idx++; idx > 0; idx = (idx - 1) / 26 {
+		name = string(rune('A'+(idx-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxSheet renders the worksheet XML.
+func xlsxSheet(rows [][]*xlsxCell) string {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<sheetData>`)
+
+	for ridx, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, ridx+1)
+		for cidx, cell := range row {
+			ref := xlsxColumn(cidx) + strconv.Itoa(ridx+1)
+			if cell.typ == "inlineStr" {
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, cell.style)
+				xml.EscapeText(&b, []byte(cell.value))
+				b.WriteString(`</t></is></c>`)
+			} else {
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="%s"><v>%s</v></c>`, ref, cell.style, cell.typ, cell.value)
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles uses the built-in number formats 14 (date), 22 (date and time),
+// 21 (time), and 46 (elapsed time). Dates use custom formats in the locale's
+// layout if one is passed. The order of cellXfs matches the xlsxStyle*
+// constants.
+func xlsxStyles(locale *Locale) string {
+	numFmts, date, dateTime := "", 14, 22
+	if locale != nil {
+		dateFormat := locale.xlsxDateFormat()
+		numFmts = `<numFmts count="2">` +
+			`<numFmt numFmtId="164" formatCode="` + dateFormat + `"/>` +
+			`<numFmt numFmtId="165" formatCode="` + dateFormat + ` hh:mm"/>` +
+			`</numFmts>`
+		date, dateTime = 164, 165
+	}
+
+	return xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		numFmts +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="6">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		fmt.Sprintf(`<xf numFmtId="%v" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>`, date) +
+		fmt.Sprintf(`<xf numFmtId="%v" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>`, dateTime) +
+		`<xf numFmtId="21" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="46" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`</cellXfs>` +
+		`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+		`</styleSheet>`
+}
@@ -25,6 +25,8 @@ func (i *ListAppsInput) method() string               { return http.MethodPost }
 func (i *ListAppsInput) url() string                  { return i.u }
 func (i *ListAppsInput) addHeaders(req *http.Request) { addHeadersXML(req, i.c, "API_GrantedDBs") }
 func (i *ListAppsInput) encode() ([]byte, error)      { return marshalXML(i, i.c) }
+func (i *ListAppsInput) idempotent() bool             { return true }
+func (i *ListAppsInput) readOnly() bool               { return true }
 
 // ListAppsOutput models the XML API response returned by API_GrantedDBs.
 // See https://help.quickbase.com/api-guide/granteddbs.html
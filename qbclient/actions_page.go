@@ -72,6 +72,8 @@ func (i *GetPageInput) method() string               { return http.MethodPost }
 func (i *GetPageInput) url() string                  { return i.u }
 func (i *GetPageInput) addHeaders(req *http.Request) { addHeadersXML(req, i.c, "API_GetDBPage") }
 func (i *GetPageInput) encode() ([]byte, error)      { return marshalXML(i, i.c) }
+func (i *GetPageInput) idempotent() bool             { return true }
+func (i *GetPageInput) readOnly() bool               { return true }
 
 // GetPageOutput models the XML API response returned by API_GetDBPage
 // See https://help.quickbase.com/api-guide/index.html#get_db_page.html
@@ -114,6 +116,7 @@ func (i *UpdatePageInput) addHeaders(req *http.Request) {
 	addHeadersXML(req, i.c, "API_AddReplaceDBPage")
 }
 func (i *UpdatePageInput) encode() ([]byte, error) { return marshalXML(i, i.c) }
+func (i *UpdatePageInput) idempotent() bool        { return true }
 
 // UpdatePageInputBody models the pagebody element.
 type UpdatePageInputBody struct {
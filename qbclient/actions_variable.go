@@ -23,6 +23,8 @@ func (i *GetVariableInput) method() string               { return http.MethodPos
 func (i *GetVariableInput) url() string                  { return i.u }
 func (i *GetVariableInput) addHeaders(req *http.Request) { addHeadersXML(req, i.c, "API_GetDBvar") }
 func (i *GetVariableInput) encode() ([]byte, error)      { return marshalXML(i, i.c) }
+func (i *GetVariableInput) idempotent() bool             { return true }
+func (i *GetVariableInput) readOnly() bool               { return true }
 
 // GetVariableOutput models the XML API response returned by API_GetDBvar.
 // See https://help.quickbase.com/api-guide/index.html#getdbvar.html
@@ -66,6 +68,7 @@ func (i *SetVariableInput) method() string               { return http.MethodPos
 func (i *SetVariableInput) url() string                  { return i.u }
 func (i *SetVariableInput) addHeaders(req *http.Request) { addHeadersXML(req, i.c, "API_SetDBvar") }
 func (i *SetVariableInput) encode() ([]byte, error)      { return marshalXML(i, i.c) }
+func (i *SetVariableInput) idempotent() bool             { return true }
 
 // SetVariableOutput models the XML API response returned by API_SetDBvar
 // See https://help.quickbase.com/api-guide/index.html#setdbvar.html
@@ -0,0 +1,48 @@
+package qbclient
+
+import (
+	"errors"
+	"fmt"
+)
+
+// AuthMethod* constants contain the valid values of the auth-method option.
+const (
+	AuthMethodOAuth     = "oauth"
+	AuthMethodTemporary = "temporary"
+	AuthMethodUser      = "user"
+)
+
+// SelectAuthMethod returns the auth method requests are authenticated with.
+// If method is empty, a user token takes precedence over a temporary token,
+// and an empty string is returned if neither is configured. If method is
+// passed, an error is returned if its credential isn't configured.
+func SelectAuthMethod(method, userToken, tempToken string) (string, error) {
+	switch method {
+	case "":
+		if userToken != "" {
+			return AuthMethodUser, nil
+		}
+		if tempToken != "" {
+			return AuthMethodTemporary, nil
+		}
+		return "", nil
+
+	case AuthMethodUser:
+		if userToken == "" {
+			return "", fmt.Errorf("option %q: %w", OptionUserToken, errors.New("value required for user auth method"))
+		}
+		return method, nil
+
+	case AuthMethodTemporary:
+		if tempToken == "" {
+			return "", fmt.Errorf("option %q: %w", OptionTemporaryToken, errors.New("value required for temporary auth method"))
+		}
+		return method, nil
+
+	case AuthMethodOAuth:
+		return "", fmt.Errorf("value %q for option %q: %w", method, OptionAuthMethod, errors.New("OAuth is not supported by this client"))
+
+	default:
+		return "", fmt.Errorf("value %q for option %q: %w", method, OptionAuthMethod, errors.New("expecting user or temporary"))
+	}
+}
@@ -2,9 +2,18 @@ package qbclient
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/QuickBase/quickbase-cli/qberrors"
 	"github.com/go-playground/validator/v10"
@@ -12,6 +21,24 @@ import (
 	"github.com/spf13/viper"
 )
 
+// BudgetExhausted is the error returned when a request would exceed the
+// client's MaxRequests.
+var BudgetExhausted = qberrors.ErrSafe{Message: "api call budget exhausted", StatusCode: http.StatusTooManyRequests}
+
+// RetryBudgetExhausted is the error returned when a failed request would be
+// retried after the client spent its RetryBudget waiting to retry.
+var RetryBudgetExhausted = qberrors.ErrSafe{Message: "retry budget exhausted", StatusCode: http.StatusServiceUnavailable}
+
+// ErrRequestFailed is the error returned when a request got no response, e.g.,
+// because the connection failed.
+var ErrRequestFailed = qberrors.ErrSafe{Message: "error executing request", StatusCode: http.StatusServiceUnavailable}
+
+// Default retry policy of clients returned by New.
+const (
+	DefaultMaxRetries   = 3
+	DefaultRetryMaxWait = 30 * time.Second
+)
+
 // Client makes requests to the Quick Base API.
 type Client struct {
 	HTTPClient    *http.Client
@@ -20,6 +47,54 @@ type Client struct {
 	URL           string
 	UserAgent     string
 	UserToken     string
+
+	// TemporaryToken is sent instead of UserToken when UserToken is empty.
+	// Temporary tokens are only accepted by the JSON API.
+	TemporaryToken string
+
+	// MaxRequests is the maximum number of HTTP requests the client makes,
+	// including retries, or 0 for unlimited.
+	MaxRequests int
+
+	// RetryBudget is the maximum cumulative time the client waits to retry
+	// failed requests, or 0 for unlimited. Once it is spent, retryable
+	// failures are returned as errors.
+	RetryBudget time.Duration
+
+	// MaxRetries is the maximum number of times a failed request is retried,
+	// or 0 to disable retries. Requests are retried on connection errors, 429,
+	// and 5xx responses, except that requests that aren't idempotent are only
+	// retried on 429, which the API returns before processing the request.
+	MaxRetries int
+
+	// RetryMaxWait is the maximum wait between retries, which grows
+	// exponentially with jitter. The wait requested by a Retry-After header
+	// is honored even if it is longer.
+	RetryMaxWait time.Duration
+
+	// RateLimit is the maximum number of requests per second, including
+	// retries, or 0 for unlimited. Requests block until they are allowed.
+	RateLimit float64
+
+	// CompressRequests gzip-compresses request bodies of at least
+	// CompressMinSize bytes. If the API rejects a compressed body with 415,
+	// the request is sent again uncompressed, and compression is disabled
+	// for the rest of the client's requests.
+	CompressRequests bool
+
+	// Timeout is the maximum duration of each attempt of a request,
+	// including reading the response body, or 0 for no timeout. Attempts
+	// that time out are retried like connection errors.
+	Timeout time.Duration
+
+	// DryRun skips requests that can change data. They are constructed and
+	// passed to the plugins' PreRequest and DryRun hooks, and ErrDryRun is
+	// returned instead of sending them. Read-only requests are still sent.
+	DryRun bool
+
+	requests         int64
+	retryWait        int64
+	compressRejected int32
 }
 
 // New returns a new Client.
@@ -28,14 +103,28 @@ func New(cfg ConfigIface) *Client {
 		ReamlHostname: cfg.RealmHostname(),
 		URL:           "https://api.quickbase.com/v1",
 		UserAgent:     userAgent(),
-		UserToken:     cfg.UserToken(),
+		MaxRetries:    DefaultMaxRetries,
+		RetryMaxWait:  DefaultRetryMaxWait,
+		Timeout:       DefaultTimeout,
+	}
+
+	// Only the credential of the selected auth method is sent.
+	if method, _ := SelectAuthMethod(cfg.AuthMethod(), cfg.UserToken(), cfg.TemporaryToken()); method == AuthMethodTemporary {
+		c.TemporaryToken = cfg.TemporaryToken()
+	} else {
+		c.UserToken = cfg.UserToken()
 	}
 
-	// Configure and set the retry handler.
+	// Configure and set the retry handler. The number of retries is enforced
+	// by checkRetry, so that it can be changed after the client is created.
 	rh := retryablehttp.NewClient()
-	rh.RetryMax = 2
+	rh.RetryMax = math.MaxInt32
 	rh.Logger = nil
 	rh.ErrorHandler = c.errorHandler
+	rh.RequestLogHook = c.requestHook
+	rh.CheckRetry = c.checkRetry
+	rh.Backoff = c.backoff
+	rh.HTTPClient.Transport = &rateLimitTransport{c: c, next: &timeoutTransport{c: c, next: rh.HTTPClient.Transport}}
 	c.HTTPClient = rh.StandardClient()
 
 	return c
@@ -45,7 +134,7 @@ func New(cfg ConfigIface) *Client {
 // passed profile.
 func NewFromProfile(profile string) (client *Client, err error) {
 	cfg := viper.New()
-	cfg.SetDefault(OptionProfile, profile)
+	cfg.Set(OptionProfile, profile)
 	if err = ReadInConfig(cfg); err == nil {
 		client = New(Config{cfg: cfg})
 	}
@@ -76,54 +165,131 @@ func (c *Client) Do(input Input, output Output) error {
 		return qberrors.Client(err).Safef(qberrors.InvalidInput, "error encoding input")
 	}
 
-	// Create the request, using the marshalled input as the body.
-	req, err := http.NewRequest(input.method(), input.url(), bytes.NewBuffer(b))
-	if err != nil {
-		serr := qberrors.ErrSafe{Message: "error creating request"}
-		return qberrors.Internal(err).Safe(serr)
+	// Don't send requests that change data in dry-run mode.
+	if c.DryRun && changesData(input) {
+		return c.dryRun(input, b)
 	}
 
-	// Add HTTP headers using Input.addHeaders.
-	input.addHeaders(req)
-
-	// Invoke each plugin's PreRequest hook.
-	c.invokePreRequest(req)
+	// Compress the body if enabled and worthwhile.
+	body, compressed := b, false
+	if c.CompressRequests && len(b) >= CompressMinSize && atomic.LoadInt32(&c.compressRejected) == 0 {
+		if gz, err := gzipBody(b); err == nil && len(gz) < len(b) {
+			body, compressed = gz, true
+		}
+	}
 
-	// Do the HTTP request.
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.send(input, body, compressed, len(b))
 	if err != nil {
-		serr := qberrors.ErrSafe{Message: "error executing request"}
-		return qberrors.Service(err).Safe(serr)
+		return err
 	}
 
-	// Invoke each plugin's PostResponse hook.
-	c.invokePostResponse(resp)
+	// Send the body uncompressed if the API doesn't accept gzip.
+	if compressed && resp.StatusCode == http.StatusUnsupportedMediaType {
+		resp.Body.Close()
+		atomic.StoreInt32(&c.compressRejected, 1)
+		if resp, err = c.send(input, b, false, len(b)); err != nil {
+			return err
+		}
+	}
 
 	// Parse the response body. We do our best to handle this gracefully if
 	// an error is thrown outside of the API's control plane, e.g., from
 	// Cloudflare, which might not produce parsable output.
-	if err := output.decode(resp.Body); err != nil {
+	var derr error
+	if rd, ok := output.(responseDecoder); ok {
+		derr = rd.decodeResponse(resp)
+	} else {
+		derr = output.decode(resp.Body)
+	}
+	if err := derr; err != nil {
 		switch true {
+		case c.timeoutError(err, 1) != nil:
+			return withRequestID(c.timeoutError(err, 1), resp)
 		case resp.StatusCode >= 200 && resp.StatusCode < 300:
 			serr := qberrors.ErrSafe{Message: "error decoding response"}
-			return qberrors.Internal(err).Safe(serr)
+			return withRequestID(qberrors.Internal(err).Safe(serr), resp)
 		case resp.StatusCode >= 400 && resp.StatusCode < 500:
 			serr := qberrors.ErrSafe{Message: http.StatusText(resp.StatusCode), StatusCode: resp.StatusCode}
-			return qberrors.Client(serr).Safe(serr)
+			return withRequestID(qberrors.Client(serr).Safe(serr), resp)
 		default:
 			serr := qberrors.ErrSafe{Message: http.StatusText(resp.StatusCode), StatusCode: resp.StatusCode}
-			return qberrors.Service(serr).Safe(serr)
+			return withRequestID(qberrors.Service(serr).Safe(serr), resp)
 		}
 	}
 
 	// Handle any errors, the logic of which will depend on whether we are
-	// consuming the XML or RESTful API.
-	return output.handleError(output, resp)
+	// consuming the XML or RESTful API. The request ID is added so it can be
+	// reported to Quickbase Support.
+	return withRequestID(output.handleError(output, resp), resp)
+}
+
+// send sends the request with the body, which is gzip-compressed from size
+// bytes if compressed is set, and invokes the plugins' hooks.
+func (c *Client) send(input Input, body []byte, compressed bool, size int) (*http.Response, error) {
+
+	// Create the request, using the marshalled input as the body.
+	req, err := http.NewRequest(input.method(), input.url(), bytes.NewBuffer(body))
+	if err != nil {
+		serr := qberrors.ErrSafe{Message: "error creating request"}
+		return nil, qberrors.Internal(err).Safe(serr)
+	}
+
+	// Add HTTP headers using Input.addHeaders.
+	input.addHeaders(req)
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	// Track the attempts of the request for checkRetry.
+	state := &retryState{idempotent: input.method() != http.MethodPost}
+	if ii, ok := input.(idempotentInput); ok {
+		state.idempotent = ii.idempotent()
+	}
+	req = req.WithContext(context.WithValue(req.Context(), retryStateKey{}, state))
+
+	// Invoke each plugin's PreRequest hook, and notify the CompressPlugins.
+	c.invokePreRequest(req)
+	if compressed {
+		for _, plugin := range c.Plugins {
+			if cp, ok := plugin.(CompressPlugin); ok {
+				cp.Compress(req, size, len(body))
+			}
+		}
+	}
+
+	// Don't start a request that exceeds the budget.
+	if c.budgetExhausted() {
+		return nil, c.budgetError()
+	}
+
+	// Do the HTTP request.
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		if c.budgetExhausted() {
+			return nil, c.budgetError()
+		}
+		if c.RetryBudgetExhausted() {
+			return nil, c.retryBudgetError()
+		}
+		// The timeout error returned by errorHandler is wrapped by the
+		// http.Client in a *url.Error, which only adds the URL.
+		var uerr *url.Error
+		if errors.Is(err, ErrTimeout) && errors.As(err, &uerr) {
+			return nil, uerr.Err
+		}
+		return nil, qberrors.Service(err).Safe(ErrRequestFailed)
+	}
+
+	// Invoke each plugin's PostResponse hook.
+	c.invokePostResponse(resp)
+
+	return resp, nil
 }
 
 // errorHandler implements retryablehttp.ErrorHandler by invoking post-response
 // plugins after the error occurs. It then closes the response body and returns
-// the same error message as retryablehttp.Do.
+// the same error message as retryablehttp.Do, with the status code and request
+// ID of the last response if there was one.
 func (c *Client) errorHandler(resp *http.Response, err error, numTries int) (*http.Response, error) {
 	c.invokePostResponse(resp)
 
@@ -131,13 +297,145 @@ func (c *Client) errorHandler(resp *http.Response, err error, numTries int) (*ht
 		resp.Body.Close()
 	}
 
+	if terr := c.timeoutError(err, numTries); terr != nil {
+		return nil, terr
+	}
+
 	s := fmt.Sprintf("giving up after %d attempt", numTries)
 	if numTries > 1 {
 		s += "s"
 	}
 
 	serr := qberrors.ErrSafe{Message: s}
-	return nil, qberrors.Service(err).Safe(serr)
+	if resp != nil {
+		serr.StatusCode = resp.StatusCode
+	}
+	return nil, withRequestID(qberrors.Service(err).Safe(serr), resp)
+}
+
+// Requests returns the number of HTTP requests made, including retries.
+func (c *Client) Requests() int { return int(atomic.LoadInt64(&c.requests)) }
+
+// retryStateKey is the context key of a request's retryState.
+type retryStateKey struct{}
+
+// retryState tracks the attempts of a request.
+type retryState struct {
+	attempt    int
+	idempotent bool
+}
+
+// requestHook implements retryablehttp.RequestLogHook by counting each
+// attempt, including retries, and notifying the RetryPlugins of retries.
+func (c *Client) requestHook(_ retryablehttp.Logger, req *http.Request, attempt int) {
+	atomic.AddInt64(&c.requests, 1)
+
+	if state, ok := req.Context().Value(retryStateKey{}).(*retryState); ok {
+		state.attempt = attempt
+	}
+
+	if attempt > 0 {
+		for _, plugin := range c.Plugins {
+			if rp, ok := plugin.(RetryPlugin); ok {
+				rp.Retry(req, attempt)
+			}
+		}
+	}
+}
+
+// checkRetry implements retryablehttp.CheckRetry by using the default policy,
+// except that requests are retried at most MaxRetries times, requests that
+// aren't idempotent are only retried on 429, and requests aren't retried once
+// either budget is exhausted.
+func (c *Client) checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	retry, cerr := retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+	if state, ok := ctx.Value(retryStateKey{}).(*retryState); ok && retry {
+		if state.attempt >= c.MaxRetries {
+			return false, cerr
+		}
+		if !state.idempotent && (resp == nil || resp.StatusCode != http.StatusTooManyRequests) {
+			return false, cerr
+		}
+	}
+	if retry && c.budgetExhausted() {
+		return false, c.budgetError()
+	}
+	if retry && c.RetryBudgetExhausted() {
+		return false, c.retryBudgetError()
+	}
+	return retry, cerr
+}
+
+// RetryWait returns the cumulative time the client waited to retry requests.
+func (c *Client) RetryWait() time.Duration { return time.Duration(atomic.LoadInt64(&c.retryWait)) }
+
+// RetryBudgetExhausted returns whether the client spent its RetryBudget.
+func (c *Client) RetryBudgetExhausted() bool {
+	return c.RetryBudget > 0 && c.RetryWait() >= c.RetryBudget
+}
+
+// backoff implements retryablehttp.Backoff by waiting for the duration in the
+// Retry-After header of 429 and 503 responses, or else for an exponentially
+// growing duration capped at RetryMaxWait, of which up to half is random
+// jitter. The wait is capped at what remains of the retry budget.
+func (c *Client) backoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if c.RetryMaxWait > 0 {
+		max = c.RetryMaxWait
+	}
+
+	wait, ok := retryAfter(resp)
+	if !ok {
+		wait = time.Duration(math.Min(math.Pow(2, float64(attemptNum))*float64(min), float64(max)))
+		if half := int64(wait / 2); half > 0 {
+			_jitterMu.Lock()
+			wait = time.Duration(half + _jitter.Int63n(half+1))
+			_jitterMu.Unlock()
+		}
+	}
+
+	if c.RetryBudget > 0 {
+		if remaining := c.RetryBudget - c.RetryWait(); wait > remaining {
+			wait = remaining
+		}
+		if wait < 0 {
+			wait = 0
+		}
+	}
+	atomic.AddInt64(&c.retryWait, int64(wait))
+	return wait
+}
+
+// _jitter is the source of the random jitter added to retry waits. It's
+// seeded so processes retrying at the same time don't wait in lockstep.
+var (
+	_jitter   = rand.New(rand.NewSource(time.Now().UnixNano()))
+	_jitterMu sync.Mutex
+)
+
+// retryAfter returns the wait requested in the Retry-After header of a 429 or
+// 503 response, in seconds.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	sec, err := strconv.ParseInt(resp.Header.Get("Retry-After"), 10, 64)
+	if err != nil || sec < 0 {
+		return 0, false
+	}
+	return time.Duration(sec) * time.Second, true
+}
+
+func (c *Client) retryBudgetError() error {
+	return qberrors.Service(nil).Safef(RetryBudgetExhausted, "%v spent waiting to retry, the maximum is %v", c.RetryWait().Round(time.Millisecond), c.RetryBudget)
+}
+
+// budgetExhausted returns whether MaxRequests have been made.
+func (c *Client) budgetExhausted() bool {
+	return c.MaxRequests > 0 && c.Requests() >= c.MaxRequests
+}
+
+func (c *Client) budgetError() error {
+	return qberrors.Client(nil).Safef(BudgetExhausted, "%v requests made, the maximum is %v", c.Requests(), c.MaxRequests)
 }
 
 func (c *Client) invokePreRequest(req *http.Request) {
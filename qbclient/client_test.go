@@ -1,7 +1,13 @@
 package qbclient_test
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/QuickBase/quickbase-cli/qbclient"
 	"github.com/spf13/viper"
@@ -20,3 +26,199 @@ func TestInvalidInput(t *testing.T) {
 		t.Fatal("got nil, expected error")
 	}
 }
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		request  func(*qbclient.Client) error
+		retries  int
+		expected int
+	}{
+		{"query retried on 500", http.StatusInternalServerError, queryRecords, 2, 3},
+		{"query retried on 429", http.StatusTooManyRequests, queryRecords, 2, 3},
+		{"retries disabled", http.StatusTooManyRequests, queryRecords, 0, 1},
+		{"insert retried on 429", http.StatusTooManyRequests, insertRecords(0), 2, 3},
+		{"insert not retried on 500", http.StatusInternalServerError, insertRecords(0), 2, 1},
+		{"upsert retried on 500", http.StatusInternalServerError, insertRecords(6), 2, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int64
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt64(&requests, 1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"message":"error","description":"error"}`))
+			}))
+			defer ts.Close()
+
+			client := qbclient.New(qbclient.NewConfig(viper.New()))
+			client.URL = ts.URL
+			client.MaxRetries = tt.retries
+			client.RetryMaxWait = time.Millisecond
+
+			if err := tt.request(client); err == nil {
+				t.Fatal("got nil, expected error")
+			}
+
+			if actual := atomic.LoadInt64(&requests); actual != int64(tt.expected) {
+				t.Errorf("got %v requests, expected %v", actual, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+		w.Write([]byte(`{"data":[],"fields":[],"metadata":{}}`))
+	}))
+	defer ts.Close()
+
+	client := qbclient.New(qbclient.NewConfig(viper.New()))
+	client.URL = ts.URL
+	client.RetryMaxWait = time.Millisecond
+	client.RateLimit = 20
+
+	// The bucket holds 20 tokens, so the 21st and 22nd requests, one of
+	// which is the retry, wait for 50ms each.
+	start := time.Now()
+	for i := 0; i < 21; i++ {
+		if err := queryRecords(client); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("got %v, expected about 100ms", elapsed)
+	}
+	if actual := atomic.LoadInt64(&requests); actual != 22 {
+		t.Errorf("got %v requests, expected 22", actual)
+	}
+}
+
+func TestCompressRequest(t *testing.T) {
+	var gzipped, plain int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			atomic.AddInt64(&gzipped, 1)
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			w.Write([]byte(`{"message":"error","description":"error"}`))
+			return
+		}
+		atomic.AddInt64(&plain, 1)
+		w.Write([]byte(`{"data":[],"fields":[],"metadata":{}}`))
+	}))
+	defer ts.Close()
+
+	client := qbclient.New(qbclient.NewConfig(viper.New()))
+	client.URL = ts.URL
+	client.CompressRequests = true
+
+	// The first request falls back to an uncompressed body, and the second
+	// isn't compressed since the API rejected it.
+	input := func() *qbclient.QueryRecordsInput {
+		return &qbclient.QueryRecordsInput{Select: []int{3}, From: "bqgruir7z", Where: "{6.CT.'" + strings.Repeat("a", 2000) + "'}"}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.QueryRecords(input()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if actual := atomic.LoadInt64(&gzipped); actual != 1 {
+		t.Errorf("got %v compressed requests, expected 1", actual)
+	}
+	if actual := atomic.LoadInt64(&plain); actual != 2 {
+		t.Errorf("got %v uncompressed requests, expected 2", actual)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var requests int64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"data":[],"fields":[],"metadata":{}}`))
+	}))
+	defer ts.Close()
+
+	client := qbclient.New(qbclient.NewConfig(viper.New()))
+	client.URL = ts.URL
+	client.MaxRetries = 1
+	client.RetryMaxWait = time.Millisecond
+	client.Timeout = 20 * time.Millisecond
+
+	// The attempt that timed out is retried, then the timeout is reported.
+	if err := queryRecords(client); !errors.Is(err, qbclient.ErrTimeout) {
+		t.Fatalf("got %v, expected %v", err, qbclient.ErrTimeout)
+	}
+	if actual := atomic.LoadInt64(&requests); actual != 2 {
+		t.Errorf("got %v requests, expected 2", actual)
+	}
+
+	// A timeout of 0 waits for the response.
+	client.Timeout = 0
+	if err := queryRecords(client); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		retries int
+	}{
+		{"client error", http.StatusBadRequest, 0},
+		{"retries exhausted", http.StatusServiceUnavailable, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(qbclient.HeaderRequestID, "6f1ac1c8d5e2a1b3")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"message":"error","description":"error"}`))
+			}))
+			defer ts.Close()
+
+			client := qbclient.New(qbclient.NewConfig(viper.New()))
+			client.URL = ts.URL
+			client.MaxRetries = tt.retries
+			client.RetryMaxWait = time.Millisecond
+
+			err := queryRecords(client)
+			if err == nil {
+				t.Fatal("got nil, expected error")
+			}
+			if actual := qbclient.RequestID(err); actual != "6f1ac1c8d5e2a1b3" {
+				t.Errorf("got %q, expected %q", actual, "6f1ac1c8d5e2a1b3")
+			}
+		})
+	}
+
+	if actual := qbclient.RequestID(errors.New("error")); actual != "" {
+		t.Errorf("got %q, expected an empty string", actual)
+	}
+}
+
+func queryRecords(c *qbclient.Client) error {
+	_, err := c.QueryRecords(&qbclient.QueryRecordsInput{Select: []int{3}, From: "bqgruir7z"})
+	return err
+}
+
+func insertRecords(mergeFieldID int) func(*qbclient.Client) error {
+	return func(c *qbclient.Client) error {
+		_, err := c.InsertRecords(&qbclient.InsertRecordsInput{
+			To:           "bqgruir7z",
+			MergeFieldID: mergeFieldID,
+			Data:         []map[int]*qbclient.InsertRecordsInputData{{6: {}}},
+		})
+		return err
+	}
+}
@@ -0,0 +1,31 @@
+package qbclient
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+)
+
+// CompressMinSize is the minimum size in bytes of a request body that is
+// compressed when the client's CompressRequests is set. Smaller bodies gain
+// little from compression.
+const CompressMinSize = 1024
+
+// CompressPlugin is implemented by plugins that are notified when a request
+// body is compressed, with the size of the body before and after.
+type CompressPlugin interface {
+	Compress(req *http.Request, before, after int)
+}
+
+// gzipBody returns the gzip-compressed body.
+func gzipBody(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
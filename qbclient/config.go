@@ -1,8 +1,14 @@
 package qbclient
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/mitchellh/go-homedir"
@@ -16,21 +22,47 @@ const EnvPrefix = "QUICKBASE"
 // ConfigFilename is the name of the configuration file.
 const ConfigFilename = "config.yml"
 
+// ProjectFilename is the name of the project file discovered by walking up
+// from the working directory.
+const ProjectFilename = ".quickbase.yaml"
+
 // Option* constants contain CLI options.
 const (
 	OptionAppID          = "app-id"
+	OptionAuthMethod     = "auth-method"
+	OptionBatchSize      = "batch-size"
 	OptionConfigDir      = "config-dir"
+	OptionConfigFile     = "config"
+	OptionConfirmCount   = "confirm-count-threshold"
 	OptionFieldID        = "field-id"
+	OptionFormat         = "format"
+	OptionJMESPathFilter = "filter"
+	OptionOutputFields   = "output-fields-order"
 	OptionProfile        = "profile"
 	OptionRealmHostname  = "realm-hostname"
 	OptionRelationshipID = "relationship-id"
+	OptionStrictFIDs     = "strict-fids"
 	OptionTableID        = "table-id"
+	OptionTemporaryToken = "temp-token"
+	OptionTokenHelper    = "token-helper"
 	OptionUserToken      = "user-token"
+	OptionUserTokenFile  = "user-token-file"
 )
 
+// envAliases maps options to the shorter environment variables that are read
+// as fallbacks for the QUICKBASE_ variables, e.g., in CI.
+var envAliases = map[string]string{
+	OptionRealmHostname:  "QB_REALM_HOSTNAME",
+	OptionTemporaryToken: "QB_TEMP_TOKEN",
+	OptionUserToken:      "QB_USER_TOKEN",
+}
+
 // ConfigIface is implemented by structs used to configure the cleint.
 type ConfigIface interface {
 
+	// AuthMethod returns the explicitly selected auth method.
+	AuthMethod() string
+
 	// ConfigDir returns the configuration directory.
 	ConfigDir() string
 
@@ -49,7 +81,10 @@ type ConfigIface interface {
 	// RealmHostname returns the configured realm hostname.
 	RealmHostname() string
 
-	// UserToken returns the configured log level.
+	// TemporaryToken returns the configured temporary token.
+	TemporaryToken() string
+
+	// UserToken returns the configured user token.
 	UserToken() string
 }
 
@@ -63,9 +98,16 @@ func NewConfig(cfg *viper.Viper) Config {
 	return Config{cfg: cfg}
 }
 
+// AuthMethod returns the explicitly selected auth method.
+func (c Config) AuthMethod() string { return c.cfg.GetString(OptionAuthMethod) }
+
 // ConfigDir returns the configuration directory.
 func (c Config) ConfigDir() string { return c.cfg.GetString(OptionConfigDir) }
 
+// ConfigFile returns the path of the configuration file, which is the file
+// passed through --config, or else the file in the configuration directory.
+func (c Config) ConfigFile() string { return ConfigFilePath(c.cfg) }
+
 // DefaultAppID returns the default app ID.
 func (c Config) DefaultAppID() string { return c.cfg.GetString(OptionAppID) }
 
@@ -78,30 +120,51 @@ func (c Config) DefaultTableID() string { return c.cfg.GetString(OptionTableID)
 // Profile returns the configured profile.
 func (c Config) Profile() string { return c.cfg.GetString(OptionProfile) }
 
-// RealmHostname returns the configured realm hostname.
+// RealmHostname returns the configured realm hostname. The --realm-hostname
+// flag takes precedence over the QUICKBASE_REALM_HOSTNAME and
+// QB_REALM_HOSTNAME environment variables, in that order, which take
+// precedence over the project file and the profile.
 func (c Config) RealmHostname() string { return c.cfg.GetString(OptionRealmHostname) }
 
-// UserToken returns the configured log level.
+// TemporaryToken returns the configured temporary token. The --temp-token
+// flag takes precedence over the QUICKBASE_TEMP_TOKEN and QB_TEMP_TOKEN
+// environment variables, in that order, which take precedence over the
+// profile.
+func (c Config) TemporaryToken() string { return c.cfg.GetString(OptionTemporaryToken) }
+
+// UserToken returns the configured user token. The --user-token flag takes
+// precedence over the QUICKBASE_USER_TOKEN and QB_USER_TOKEN environment
+// variables, in that order, which take precedence over the profile and the
+// token helper. A token read from --user-token-file, or from stdin if the
+// token is "-", replaces the value once the configuration is read in.
 func (c Config) UserToken() string { return c.cfg.GetString(OptionUserToken) }
 
 // ReadInConfig reads in configuration from the config file.
 func ReadInConfig(cfg *viper.Viper) error {
-	homeDir, err := homedir.Dir()
-	if err != nil {
-		return err
-	}
-
-	// Set the default profile and configuration file directory.
-	cfg.SetDefault(OptionProfile, "default")
-	cfg.SetDefault(OptionConfigDir, Filepath(homeDir, ".config", "quickbase"))
-
 	// Read in configuration from environment variables.
 	cfg.SetEnvPrefix(EnvPrefix)
 	cfg.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	cfg.AutomaticEnv()
 
-	// Read the configuration file in the configuration directory if it exists.
-	configFile, err := ReadConfigFile(cfg.GetString(OptionConfigDir))
+	// Set the default profile and configuration file directory. The home
+	// directory is only required when the configuration directory isn't set,
+	// so containers without one can be configured entirely through the
+	// environment.
+	cfg.SetDefault(OptionProfile, "default")
+	if cfg.GetString(OptionConfigDir) == "" && cfg.GetString(OptionConfigFile) == "" {
+		homeDir, err := homedir.Dir()
+		if err != nil {
+			return err
+		}
+		cfg.SetDefault(OptionConfigDir, Filepath(homeDir, ".config", "quickbase"))
+	}
+
+	// Read the configuration file passed through --config, which must exist,
+	// or else the file in the configuration directory if it exists.
+	if path := cfg.GetString(OptionConfigFile); path != "" && !FileExists(path) {
+		return fmt.Errorf("value %q for option %q: %w", path, OptionConfigFile, errors.New("file not found"))
+	}
+	configFile, err := ReadConfigFileAt(ConfigFilePath(cfg))
 	if err != nil {
 		return err
 	}
@@ -111,51 +174,318 @@ func ReadInConfig(cfg *viper.Viper) error {
 	if config, ok := configFile[p]; ok {
 		cfg.SetDefault(OptionRealmHostname, config.RealmHostname)
 		cfg.SetDefault(OptionUserToken, config.UserToken)
+		cfg.SetDefault(OptionTemporaryToken, config.TemporaryToken)
 		cfg.SetDefault(OptionAppID, config.AppID)
 		cfg.SetDefault(OptionTableID, config.TableID)
 		cfg.SetDefault(OptionFieldID, config.FieldID)
+		cfg.SetDefault(OptionTokenHelper, config.TokenHelper)
+		if config.BatchSize != 0 {
+			cfg.SetDefault(OptionBatchSize, config.BatchSize)
+		}
+		if config.ConfirmCountThreshold != 0 {
+			cfg.SetDefault(OptionConfirmCount, config.ConfirmCountThreshold)
+		}
+		if config.StrictFIDs {
+			cfg.SetDefault(OptionStrictFIDs, true)
+		}
+		if config.Format != "" {
+			cfg.SetDefault(OptionFormat, config.Format)
+		}
+		if config.Filter != "" {
+			cfg.SetDefault(OptionJMESPathFilter, config.Filter)
+		}
+		if config.OutputFieldsOrder != "" {
+			cfg.SetDefault(OptionOutputFields, config.OutputFieldsOrder)
+		}
+	}
+
+	// Defaults in the nearest project file take precedence over the profile.
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if path := FindProjectFile(wd); path != "" {
+		project, err := ReadProjectFile(path)
+		if err != nil {
+			return err
+		}
+		if project.RealmHostname != "" {
+			cfg.SetDefault(OptionRealmHostname, project.RealmHostname)
+		}
+		if project.AppID != "" {
+			cfg.SetDefault(OptionAppID, project.AppID)
+		}
+		if project.TableID != "" {
+			cfg.SetDefault(OptionTableID, project.TableID)
+		}
+	}
+
+	// The short environment variables take precedence over the profile and
+	// project file, but not over flags and the QUICKBASE_ variables, which
+	// viper reads first.
+	for option, env := range envAliases {
+		if v := os.Getenv(env); v != "" {
+			cfg.SetDefault(option, v)
+		}
+	}
+
+	// Expand a realm alias, which can be passed wherever a hostname can.
+	aliases, err := ReadRealmAliasesAt(ConfigFilePath(cfg))
+	if err != nil {
+		return err
+	}
+	if hostname, ok := aliases[cfg.GetString(OptionRealmHostname)]; ok {
+		cfg.Set(OptionRealmHostname, hostname)
+	}
+
+	// Read the user token from a file or stdin, so it never appears in the
+	// shell history or process listings.
+	if path := cfg.GetString(OptionUserTokenFile); path != "" {
+		if cfg.GetString(OptionUserToken) == "-" {
+			return fmt.Errorf("option %q: %w", OptionUserTokenFile, fmt.Errorf("cannot be used with option %q set to %q", OptionUserToken, "-"))
+		}
+		token, err := ReadTokenFile(path)
+		if err != nil {
+			return err
+		}
+		cfg.Set(OptionUserToken, token)
+	} else if cfg.GetString(OptionUserToken) == "-" {
+		token, err := readToken(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("error reading user token from stdin: %w", err)
+		}
+		cfg.Set(OptionUserToken, token)
+	}
+
+	// Get the token from the helper if no static token is configured, or if
+	// the user auth method is selected and no user token is. The token is
+	// cached in the configuration for the life of the process.
+	method := cfg.GetString(OptionAuthMethod)
+	if cfg.GetString(OptionUserToken) == "" && (method == AuthMethodUser || (method == "" && cfg.GetString(OptionTemporaryToken) == "")) {
+		if helper := cfg.GetString(OptionTokenHelper); helper != "" {
+			token, err := RunTokenHelper(helper)
+			if err != nil {
+				return err
+			}
+			cfg.Set(OptionUserToken, token)
+		}
 	}
 
 	return nil
 }
 
-// ReadConfigFile reads and parses the configuration file.
-func ReadConfigFile(dir string) (cf ConfigFile, err error) {
+// RunTokenHelper executes the command through the shell and returns the token
+// written to its stdout, similar to git and docker credential helpers.
+func RunTokenHelper(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stderr = os.Stderr
+
+	b, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("error running token helper: %w", err)
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", errors.New("token helper returned an empty token")
+	}
+
+	return token, nil
+}
+
+// ReadTokenFile returns the token stored in the file, with surrounding
+// whitespace such as the trailing newline removed.
+func ReadTokenFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading token file: %w", err)
+	}
+	defer f.Close()
+
+	token, err := readToken(f)
+	if err != nil {
+		return "", fmt.Errorf("error reading token file %s: %w", path, err)
+	}
+	return token, nil
+}
+
+// readToken reads a token from r, with surrounding whitespace removed.
+func readToken(r io.Reader) (string, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", errors.New("empty token")
+	}
+	return token, nil
+}
+
+// FindProjectFile returns the path to the project file in dir or its nearest
+// parent directory, similar to how git finds the .git directory. An empty
+// string is returned if no project file is found.
+func FindProjectFile(dir string) string {
+	dir = filepath.Clean(dir)
+	for {
+		path := filepath.Join(dir, ProjectFilename)
+		if FileExists(path) {
+			return path
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// ReadProjectFile reads and parses a project file.
+func ReadProjectFile(path string) (pf *ProjectFile, err error) {
+	pf = &ProjectFile{}
+
+	var b []byte
+	if b, err = ioutil.ReadFile(path); err != nil {
+		return
+	}
+
+	err = yaml.Unmarshal(b, pf)
+	return
+}
+
+// ConfigFilePath returns the path of the configuration file, which is the
+// file passed through --config if set, or else ConfigFilename in the
+// configuration directory.
+func ConfigFilePath(cfg *viper.Viper) string {
+	if path := cfg.GetString(OptionConfigFile); path != "" {
+		return path
+	}
+	return Filepath(cfg.GetString(OptionConfigDir), ConfigFilename)
+}
+
+// ReadConfigFile reads and parses the configuration file in the directory.
+func ReadConfigFile(dir string) (ConfigFile, error) {
+	return ReadConfigFileAt(Filepath(dir, ConfigFilename))
+}
+
+// ReadConfigFileAt reads and parses the configuration file at the path. An
+// empty configuration is returned if the file doesn't exist.
+func ReadConfigFileAt(name string) (cf ConfigFile, err error) {
 	cf = make(map[string]*ConfigFileProfile, 0)
 
-	filepath := Filepath(dir, ConfigFilename)
-	if !FileExists(filepath) {
+	if !FileExists(name) {
+		return
+	}
+
+	var b []byte
+	if b, err = ioutil.ReadFile(name); err != nil {
+		return
+	}
+
+	// The realm aliases share the top level with the profiles, so they are
+	// skipped here and read by ReadRealmAliases.
+	var nodes map[string]yaml.Node
+	if err = yaml.Unmarshal(b, &nodes); err != nil {
+		return
+	}
+	delete(nodes, ConfigFileRealmsKey)
+
+	for name, node := range nodes {
+		p := &ConfigFileProfile{}
+		if err = node.Decode(p); err != nil {
+			return
+		}
+		cf[name] = p
+	}
+	return
+}
+
+// ReadRealmAliases reads the realm aliases in the configuration file in the
+// directory, which map short names to realm hostnames.
+func ReadRealmAliases(dir string) (map[string]string, error) {
+	return ReadRealmAliasesAt(Filepath(dir, ConfigFilename))
+}
+
+// ReadRealmAliasesAt reads the realm aliases in the configuration file at the
+// path.
+func ReadRealmAliasesAt(name string) (aliases map[string]string, err error) {
+	aliases = map[string]string{}
+
+	if !FileExists(name) {
 		return
 	}
 
 	var b []byte
-	if b, err = ioutil.ReadFile(filepath); err != nil {
+	if b, err = ioutil.ReadFile(name); err != nil {
 		return
 	}
 
-	err = yaml.Unmarshal(b, &cf)
+	var file struct {
+		Realms map[string]string `yaml:"realms"`
+	}
+	if err = yaml.Unmarshal(b, &file); err != nil {
+		return
+	}
+	for alias, hostname := range file.Realms {
+		aliases[alias] = hostname
+	}
 	return
 }
 
-// WriteConfigFile writes a configuration file.
-func WriteConfigFile(dir string, cf ConfigFile) (err error) {
+// WriteConfigFile writes the configuration file in the directory. The realm
+// aliases in the existing file are kept.
+func WriteConfigFile(dir string, cf ConfigFile) error {
+	return WriteConfigFileAt(Filepath(dir, ConfigFilename), cf)
+}
 
-	if !DirExists(dir) {
+// WriteConfigFileAt writes the configuration file at the path, creating its
+// directory if it doesn't exist. The realm aliases in the existing file are
+// kept.
+func WriteConfigFileAt(name string, cf ConfigFile) (err error) {
+	if dir := filepath.Dir(name); !DirExists(dir) {
 		if err = os.MkdirAll(dir, 0755); err != nil {
 			return
 		}
 	}
 
+	aliases, err := ReadRealmAliasesAt(name)
+	if err != nil {
+		return
+	}
+
+	file := make(map[string]interface{}, len(cf)+1)
+	for name, p := range cf {
+		file[name] = p
+	}
+	if len(aliases) > 0 {
+		file[ConfigFileRealmsKey] = aliases
+	}
+
 	var b []byte
-	if b, err = yaml.Marshal(cf); err != nil {
+	if b, err = yaml.Marshal(file); err != nil {
 		return
 	}
 
-	filepath := Filepath(dir, ConfigFilename)
-	err = ioutil.WriteFile(filepath, b, 0600)
+	// The file stores tokens, so its permissions are reset in case it was
+	// created with looser ones, which WriteFile doesn't change.
+	if err = ioutil.WriteFile(name, b, 0600); err != nil {
+		return
+	}
+	err = os.Chmod(name, 0600)
 	return
 }
 
+// ConfigFileRealmsKey is the top-level key of the configuration file that
+// contains the realm aliases. It can't be used as a profile name.
+const ConfigFileRealmsKey = "realms"
+
 // ConfigFile models the configuration file.
 type ConfigFile map[string]*ConfigFileProfile
 
@@ -163,8 +493,26 @@ type ConfigFile map[string]*ConfigFileProfile
 type ConfigFileProfile struct {
 	RealmHostname  string `yaml:"realm_hostname,omitempty" json:"realm_hostname,omitempty"`
 	UserToken      string `yaml:"user_token,omitempty" json:"user_token,omitempty"`
-	TemporaryToken string `yaml:"temp_token,omitempty" json:"temp_token,omitempty"`
+	TemporaryToken string `yaml:"temp_token,omitempty" json:"-"`
+	TokenHelper    string `yaml:"token_helper,omitempty" json:"token_helper,omitempty"`
 	AppID          string `yaml:"app_id,omitempty" json:"app_id,omitempty"`
 	TableID        string `yaml:"table_id,omitempty" json:"table_id,omitempty"`
 	FieldID        int    `yaml:"field_id,omitempty" json:"field_id,omitempty"`
+
+	BatchSize             int  `yaml:"batch_size,omitempty" json:"batch_size,omitempty"`
+	ConfirmCountThreshold int  `yaml:"confirm_count_threshold,omitempty" json:"confirm_count_threshold,omitempty"`
+	StrictFIDs            bool `yaml:"strict_fids,omitempty" json:"strict_fids,omitempty"`
+
+	// Output defaults for the profile, overridden by the corresponding flags.
+	Format            string `yaml:"format,omitempty" json:"format,omitempty"`
+	Filter            string `yaml:"filter,omitempty" json:"filter,omitempty"`
+	OutputFieldsOrder string `yaml:"output_fields_order,omitempty" json:"output_fields_order,omitempty"`
+}
+
+// ProjectFile models the project file. Tokens are intentionally not read from
+// the project file, since it is usually committed to a repository.
+type ProjectFile struct {
+	RealmHostname string `yaml:"realm_hostname,omitempty" json:"realm_hostname,omitempty"`
+	AppID         string `yaml:"app_id,omitempty" json:"app_id,omitempty"`
+	TableID       string `yaml:"table_id,omitempty" json:"table_id,omitempty"`
 }
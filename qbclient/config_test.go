@@ -0,0 +1,177 @@
+package qbclient_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+func TestProfileOutputDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quickbase-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cf := qbclient.ConfigFile{
+		"default": &qbclient.ConfigFileProfile{},
+		"reports": &qbclient.ConfigFileProfile{
+			Format:            "csv",
+			Filter:            "data[].\"6\".value",
+			OutputFieldsOrder: "schema",
+			BatchSize:         5000,
+		},
+	}
+	if err := qbclient.WriteConfigFile(dir, cf); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		profile string
+		args    []string
+		option  string
+		want    string
+	}{
+		{"reports", nil, qbclient.OptionFormat, "csv"},
+		{"reports", nil, qbclient.OptionJMESPathFilter, "data[].\"6\".value"},
+		{"reports", nil, qbclient.OptionOutputFields, "schema"},
+		{"reports", nil, qbclient.OptionBatchSize, "5000"},
+		{"reports", []string{"--format", "table"}, qbclient.OptionFormat, "table"},
+		{"reports", []string{"--filter", "metadata"}, qbclient.OptionJMESPathFilter, "metadata"},
+		{"reports", []string{"--output-fields-order", "response"}, qbclient.OptionOutputFields, "response"},
+		{"reports", []string{"--batch-size", "200"}, qbclient.OptionBatchSize, "200"},
+		{"default", nil, qbclient.OptionFormat, ""},
+		{"default", nil, qbclient.OptionOutputFields, "response"},
+		{"default", nil, qbclient.OptionBatchSize, "1000"},
+	}
+
+	for _, tt := range tests {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		flags.String(qbclient.OptionFormat, "", "")
+		flags.String(qbclient.OptionJMESPathFilter, "", "")
+		flags.String(qbclient.OptionOutputFields, "response", "")
+		flags.Int(qbclient.OptionBatchSize, 1000, "")
+		if err := flags.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := viper.New()
+		if err := cfg.BindPFlags(flags); err != nil {
+			t.Fatal(err)
+		}
+		cfg.Set(qbclient.OptionConfigDir, dir)
+		cfg.Set(qbclient.OptionProfile, tt.profile)
+
+		if err := qbclient.ReadInConfig(cfg); err != nil {
+			t.Fatal(err)
+		}
+
+		have := cfg.GetString(tt.option)
+		if have != tt.want {
+			t.Errorf("profile %s, args %v: have %q, want %q for %s", tt.profile, tt.args, have, tt.want, tt.option)
+		}
+	}
+}
+
+func TestUserTokenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quickbase-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(path, []byte("b5ab3c_token \n\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path, "b5ab3c_token", false},
+		{filepath.Join(dir, "missing"), "", true},
+		{dir, "", true},
+	}
+
+	for _, tt := range tests {
+		cfg := viper.New()
+		cfg.Set(qbclient.OptionConfigDir, dir)
+		cfg.Set(qbclient.OptionUserToken, "from_flag")
+		cfg.Set(qbclient.OptionUserTokenFile, tt.path)
+
+		err := qbclient.ReadInConfig(cfg)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: got nil, expected error", tt.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if have := cfg.GetString(qbclient.OptionUserToken); have != tt.want {
+			t.Errorf("%s: have %q, want %q", tt.path, have, tt.want)
+		}
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quickbase-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The file in the configuration directory must be ignored.
+	if err := qbclient.WriteConfigFile(dir, qbclient.ConfigFile{
+		"default": &qbclient.ConfigFileProfile{RealmHostname: "dir.quickbase.com"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "ci.yml")
+	if err := qbclient.WriteConfigFileAt(path, qbclient.ConfigFile{
+		"default": &qbclient.ConfigFileProfile{RealmHostname: "file.quickbase.com"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"", "dir.quickbase.com", false},
+		{path, "file.quickbase.com", false},
+		{filepath.Join(dir, "missing.yml"), "", true},
+		{dir, "", true},
+	}
+
+	for _, tt := range tests {
+		cfg := viper.New()
+		cfg.Set(qbclient.OptionConfigDir, dir)
+		cfg.Set(qbclient.OptionConfigFile, tt.path)
+
+		err := qbclient.ReadInConfig(cfg)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: got nil, expected error", tt.path)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if have := cfg.GetString(qbclient.OptionRealmHostname); have != tt.want {
+			t.Errorf("%q: have %q, want %q", tt.path, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package qbclient
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/QuickBase/quickbase-cli/qberrors"
+)
+
+// ErrDryRun is returned for requests that change data when the client is in
+// dry-run mode.
+var ErrDryRun = errors.New("dry run, request not sent")
+
+// DryRunPlugin is implemented by plugins that are notified of requests that
+// weren't sent because the client is in dry-run mode.
+type DryRunPlugin interface {
+	DryRun(req *http.Request)
+}
+
+// changesData returns whether sending the input can change data. Requests
+// sent with GET never do, and neither do those sent with POST whose input
+// implements readOnlyInput.
+func changesData(input Input) bool {
+	if input.method() == http.MethodGet {
+		return false
+	}
+	if ri, ok := input.(readOnlyInput); ok {
+		return !ri.readOnly()
+	}
+	return true
+}
+
+// dryRun constructs the request without sending it, invokes the plugins'
+// PreRequest and DryRun hooks so it can be inspected, and returns ErrDryRun.
+func (c *Client) dryRun(input Input, body []byte) error {
+	req, err := http.NewRequest(input.method(), input.url(), bytes.NewBuffer(body))
+	if err != nil {
+		serr := qberrors.ErrSafe{Message: "error creating request"}
+		return qberrors.Internal(err).Safe(serr)
+	}
+	input.addHeaders(req)
+
+	c.invokePreRequest(req)
+	for _, plugin := range c.Plugins {
+		if dp, ok := plugin.(DryRunPlugin); ok {
+			dp.DryRun(req)
+		}
+	}
+
+	return ErrDryRun
+}
@@ -33,6 +33,10 @@ type Field struct {
 
 	// No UI
 	AddToForms bool `json:"addToForms,omitempty" cliutil:"option=add-to-forms"`
+
+	// Permissions are only returned when requested through
+	// ListFieldsInput.IncludeFieldPermissions.
+	Permissions []*FieldPermission `json:"permissions,omitempty"`
 }
 
 // FieldProperties models field properties.
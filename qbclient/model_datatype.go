@@ -2,6 +2,7 @@ package qbclient
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
@@ -183,10 +184,13 @@ type User struct {
 	Name  string `json:"name"`
 }
 
-// File models a file attachment.
+// File models a file attachment. FileName and Data are only set to upload a
+// file, in which case Data contains the base64-encoded contents of the file.
 type File struct {
-	URL     string         `json:"url,omitempty"`
-	Version []*FileVersion `json:"versions,omitempty"`
+	URL      string         `json:"url,omitempty"`
+	Version  []*FileVersion `json:"versions,omitempty"`
+	FileName string         `json:"fileName,omitempty"`
+	Data     string         `json:"data,omitempty"`
 }
 
 // FileVersion models the "version" property.
@@ -435,6 +439,12 @@ func NewFileAttachmentValue(val *File) *Value {
 	return &Value{File: val, QuickBaseType: FieldFileAttachment}
 }
 
+// NewFileUploadValue returns a new Value of the FieldFileAttachment type that
+// uploads a file with the name and contents.
+func NewFileUploadValue(name string, contents []byte) *Value {
+	return NewFileAttachmentValue(&File{FileName: name, Data: base64.StdEncoding.EncodeToString(contents)})
+}
+
 // NewReportLinkValue returns a new Value of the FieldReportLink type.
 func NewReportLinkValue(val string) *Value {
 	return &Value{Str: val, QuickBaseType: FieldReportLink}
@@ -525,10 +535,10 @@ func NewValueFromString(val, ftype string) (v *Value, err error) {
 		v, err = NewNumericCurrencyValueFromString(val)
 
 	case FieldNumericPercent:
-		v, err = NewNumericCurrencyValueFromString(val)
+		v, err = NewNumericPercentValueFromString(val)
 
 	case FieldNumericRating:
-		v, err = NewNumericPercentValueFromString(val)
+		v, err = NewNumericRatingValueFromString(val)
 
 	case FieldDate:
 		v, err = NewDateValueFromString(val)
@@ -720,11 +730,12 @@ func unmarshalField(fid int, ftype string, data *json.RawMessage) (val *Value, e
 		}
 
 	case FieldNumeric, FieldNumericCurrency, FieldNumericPercent, FieldNumericRating:
+		// Preserve the subtype so the value can be rendered accordingly.
 		var v float64
 		if data == nil {
-			val = NewNumericValue(v)
+			val = &Value{Float64: v, QuickBaseType: ftype}
 		} else if err = json.Unmarshal(*data, &v); err == nil {
-			val = NewNumericValue(v)
+			val = &Value{Float64: v, QuickBaseType: ftype}
 		}
 
 	case FieldDate:
@@ -21,6 +21,20 @@ type Input interface {
 	encode() ([]byte, error)
 }
 
+// idempotentInput is implemented by inputs sent with POST that are safe to
+// retry after the request may have been processed, e.g., queries and updates
+// that set values. Inputs sent with other methods are always idempotent.
+type idempotentInput interface {
+	idempotent() bool
+}
+
+// readOnlyInput is implemented by inputs sent with methods other than GET that
+// don't change data, e.g., queries sent with POST. They are sent in dry-run
+// mode.
+type readOnlyInput interface {
+	readOnly() bool
+}
+
 // Output models the payload of API responses.
 type Output interface {
 
@@ -36,3 +50,10 @@ type Output interface {
 	// handleError handles errors returned by the API.
 	handleError(Output, *http.Response) error
 }
+
+// responseDecoder is implemented by outputs that need the status of the
+// response to decode its body, e.g., to stream a successful response instead
+// of parsing it. It is used in place of Output.decode.
+type responseDecoder interface {
+	decodeResponse(*http.Response) error
+}
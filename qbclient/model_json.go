@@ -40,6 +40,8 @@ func addHeadersJSON(req *http.Request, c *Client) {
 
 	if c.UserToken != "" {
 		req.Header.Add("Authorization", fmt.Sprintf("QB-USER-TOKEN %s", c.UserToken))
+	} else if c.TemporaryToken != "" {
+		req.Header.Add("Authorization", fmt.Sprintf("QB-TEMP-TOKEN %s", c.TemporaryToken))
 	}
 }
 
@@ -8,3 +8,9 @@ type Plugin interface {
 	PreRequest(req *http.Request)
 	PostResponse(resp *http.Response)
 }
+
+// RetryPlugin is implemented by plugins that are notified before a failed
+// request is retried. The attempt is 1 for the first retry.
+type RetryPlugin interface {
+	Retry(req *http.Request, attempt int)
+}
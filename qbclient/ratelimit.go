@@ -0,0 +1,65 @@
+package qbclient
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket that holds up to one second of tokens. Tokens
+// are reserved in order, so callers that have to wait are served first come,
+// first served.
+type rateLimiter struct {
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token at the rate of tokens per second and returns how long
+// the caller must wait until the token is available.
+func (l *rateLimiter) reserve(rate float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	burst := math.Max(1, math.Floor(rate))
+	now := time.Now()
+	if l.last.IsZero() {
+		l.tokens = burst
+	} else {
+		l.tokens = math.Min(burst, l.tokens+now.Sub(l.last).Seconds()*rate)
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / rate * float64(time.Second))
+}
+
+// rateLimitTransport implements http.RoundTripper by waiting for a token from
+// the client's rate limiter before each request. It wraps the transport used
+// by the retry handler, so that retries also consume a token.
+type rateLimitTransport struct {
+	c       *Client
+	limiter rateLimiter
+	next    http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.RoundTrip. The wait is cut short if
+// the request's context is done.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.c.RateLimit > 0 {
+		if wait := t.limiter.reserve(t.c.RateLimit); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			case <-timer.C:
+			}
+		}
+	}
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,59 @@
+package qbclient
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/QuickBase/quickbase-cli/qberrors"
+)
+
+// HeaderRequestID is the response header that contains the ID Quickbase
+// assigns to each request. Quickbase Support uses it to find the request in
+// their logs, so it should be included when reporting an error.
+const HeaderRequestID = "QB-API-Ray"
+
+// requestIDError wraps an error caused by a response with the ID of the
+// request. The error chain is unchanged, so it's transparent to qberrors.
+type requestIDError struct {
+	err       error
+	requestID string
+}
+
+func (e requestIDError) Error() string { return e.err.Error() }
+func (e requestIDError) Unwrap() error { return e.err }
+
+// RequestID returns the ID of the request whose response caused err, or an
+// empty string if there was no response or it had no HeaderRequestID. The
+// upstream errors are searched too, since errors returned once the retries
+// are exhausted are wrapped by the http.Client.
+func RequestID(err error) string {
+	for err != nil {
+		var rerr requestIDError
+		if errors.As(err, &rerr) {
+			return rerr.requestID
+		}
+		if !qberrors.IsSafe(err) {
+			return ""
+		}
+		err = qberrors.Upstream(err)
+	}
+	return ""
+}
+
+// ResponseRequestID returns the ID of the request from the response's
+// headers, or an empty string if resp is nil or has no HeaderRequestID.
+func ResponseRequestID(resp *http.Response) string {
+	if resp == nil {
+		return ""
+	}
+	return resp.Header.Get(HeaderRequestID)
+}
+
+// withRequestID wraps err with the ID of the request from the response's
+// headers so it can be read by RequestID.
+func withRequestID(err error, resp *http.Response) error {
+	if id := ResponseRequestID(resp); err != nil && id != "" {
+		return requestIDError{err: err, requestID: id}
+	}
+	return err
+}
@@ -98,6 +98,7 @@ func (i *UpdateAppInput) url() string                  { return i.u }
 func (i *UpdateAppInput) method() string               { return http.MethodPost }
 func (i *UpdateAppInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
 func (i *UpdateAppInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *UpdateAppInput) idempotent() bool             { return true }
 
 // UpdateAppOutput models the output returned by POST /v1/apps/{appId}.
 // See https://developer.quickbase.com/operation/updateApp
@@ -220,9 +221,9 @@ type CopyAppInput struct {
 	c *Client
 	u string
 
-	AppID       string                  `json:"-" validate:"required" cliutil:"option=app-id"`
-	Name        string                  `json:"name" validate:"required" cliutil:"option=name"`
-	Description string                  `json:"description,omitempty" cliutil:"option=description"`
+	AppID       string                  `json:"-" validate:"required" cliutil:"option=app-id usage='unique identifier of the app to copy, e.g., bqgruir3g'"`
+	Name        string                  `json:"name" validate:"required" cliutil:"option=name usage='name of the new app'"`
+	Description string                  `json:"description,omitempty" cliutil:"option=description usage='description of the new app'"`
 	Properties  *CopyAppInputProperties `json:"properties,omitempty"`
 }
 
@@ -233,10 +234,10 @@ func (i *CopyAppInput) encode() ([]byte, error)      { return marshalJSON(i) }
 
 // CopyAppInputProperties models the properties property.
 type CopyAppInputProperties struct {
-	AssignUserToken   bool `json:"assignUserToken,omitempty" cliutil:"option=assign-token"`
-	ExcludeFiles      bool `json:"excludeFiles,omitempty" cliutil:"option=exclude-files"`
-	KeepData          bool `json:"keepData,omitempty" cliutil:"option=keep-data"`
-	KeepUsersAndRoles bool `json:"usersAndRoles,omitempty" cliutil:"option=keep-users-roles"`
+	AssignUserToken   bool `json:"assignUserToken,omitempty" cliutil:"option=assign-token usage='assign the user token to the new app'"`
+	ExcludeFiles      bool `json:"excludeFiles,omitempty" cliutil:"option=exclude-files usage='exclude the file attachments from the copied data'"`
+	KeepData          bool `json:"keepData,omitempty" cliutil:"option=keep-data usage='copy the records of each table'"`
+	KeepUsersAndRoles bool `json:"usersAndRoles,omitempty" cliutil:"option=keep-users-roles usage='copy the users and the roles they are assigned'"`
 }
 
 // CopyAppOutput models the output returned by POST /v1/apps/{appId}/copy.
@@ -0,0 +1,63 @@
+package qbclient
+
+import (
+	"io"
+	"net/http"
+)
+
+// GetAuditLogsInput models the input sent to POST /v1/audit.
+// See https://developer.quickbase.com/operation/audit
+type GetAuditLogsInput struct {
+	c *Client
+	u string
+
+	Date      string   `json:"date,omitempty"`
+	NextToken string   `json:"nextToken,omitempty"`
+	NumRows   int      `json:"numRows,omitempty"`
+	QueryID   string   `json:"queryId,omitempty"`
+	Topics    []string `json:"topics,omitempty"`
+}
+
+func (i *GetAuditLogsInput) url() string                  { return i.u }
+func (i *GetAuditLogsInput) method() string               { return http.MethodPost }
+func (i *GetAuditLogsInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
+func (i *GetAuditLogsInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *GetAuditLogsInput) idempotent() bool             { return true }
+func (i *GetAuditLogsInput) readOnly() bool               { return true }
+
+// GetAuditLogsOutput models the output returned by POST /v1/audit.
+// See https://developer.quickbase.com/operation/audit
+type GetAuditLogsOutput struct {
+	ErrorProperties
+
+	QueryID   string                     `json:"queryId,omitempty"`
+	Events    []*GetAuditLogsOutputEvent `json:"events"`
+	NextToken string                     `json:"nextToken,omitempty"`
+}
+
+func (o *GetAuditLogsOutput) decode(body io.ReadCloser) error { return unmarshalJSON(body, &o) }
+
+// GetAuditLogsOutputEvent models the objects in the events property.
+type GetAuditLogsOutputEvent struct {
+	ID          string      `json:"id"`
+	FirstName   string      `json:"firstname"`
+	LastName    string      `json:"lastname"`
+	Email       string      `json:"email"`
+	TopicID     string      `json:"topicId"`
+	Time        string      `json:"time"`
+	IPAddress   string      `json:"ipaddress"`
+	UserAgent   string      `json:"useragent"`
+	Application string      `json:"application,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Payload     interface{} `json:"payload,omitempty"`
+}
+
+// GetAuditLogs sends a request to POST /v1/audit.
+// See https://developer.quickbase.com/operation/audit
+func (c *Client) GetAuditLogs(input *GetAuditLogsInput) (output *GetAuditLogsOutput, err error) {
+	input.c = c
+	input.u = c.URL + "/audit"
+	output = &GetAuditLogsOutput{}
+	err = c.Do(input, output)
+	return
+}
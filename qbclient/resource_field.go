@@ -50,12 +50,19 @@ func (o *ListFieldsOutput) UnmarshalJSON(b []byte) (err error) {
 type ListFieldsOutputField struct {
 	Field
 	FieldID    int                              `json:"id,omitempty"`
+	Mode       string                           `json:"mode,omitempty"`
 	Properties *ListFieldsOutputFieldProperties `json:"properties,omitempty"`
 }
 
 // ListFieldsOutputFieldProperties models the field object properties.
 type ListFieldsOutputFieldProperties struct {
 	FieldProperties
+
+	Choices                 []string `json:"choices,omitempty"`
+	LookupReferenceFieldID  int      `json:"lookupReferenceFieldId,omitempty"`
+	LookupTargetFieldID     int      `json:"lookupTargetFieldId,omitempty"`
+	SummaryReferenceFieldID int      `json:"summaryReferenceFieldId,omitempty"`
+	SummaryTargetFieldID    int      `json:"summaryTargetFieldId,omitempty"`
 }
 
 // ListFields sends a request to GET /v1/fields?tableId={tableId}.
@@ -63,6 +70,9 @@ type ListFieldsOutputFieldProperties struct {
 func (c *Client) ListFields(input *ListFieldsInput) (output *ListFieldsOutput, err error) {
 	input.c = c
 	input.u = c.URL + "/fields?tableId=" + url.QueryEscape(input.TableID)
+	if input.IncludeFieldPermissions {
+		input.u += "&includeFieldPerms=true"
+	}
 	output = &ListFieldsOutput{}
 	err = c.Do(input, output)
 	return
@@ -228,6 +238,7 @@ func (i *UpdateFieldInput) url() string                  { return i.u }
 func (i *UpdateFieldInput) method() string               { return http.MethodPost }
 func (i *UpdateFieldInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
 func (i *UpdateFieldInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *UpdateFieldInput) idempotent() bool             { return true }
 
 // UpdateFieldInputProperties models the "properties" property.
 type UpdateFieldInputProperties struct {
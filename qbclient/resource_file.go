@@ -1,12 +1,56 @@
 package qbclient
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 )
 
+// DownloadFileInput models the input sent to GET /v1/files/{tableId}/{recordId}/{fieldId}/{versionNumber}.
+// See https://developer.quickbase.com/operation/downloadFile
+type DownloadFileInput struct {
+	c *Client
+	u string
+
+	TableID  string `json:"-" validate:"required" cliutil:"option=table-id"`
+	RecordID int    `json:"-" validate:"required" cliutil:"option=record-id"`
+	FieldID  int    `json:"-" validate:"required" cliutil:"option=field-id"`
+	Version  int    `json:"-" validate:"required" cliutil:"option=version"`
+}
+
+func (i *DownloadFileInput) url() string                  { return i.u }
+func (i *DownloadFileInput) method() string               { return http.MethodGet }
+func (i *DownloadFileInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
+func (i *DownloadFileInput) encode() ([]byte, error)      { return marshalJSON(i) }
+
+// DownloadFileOutput models the output returned by GET /v1/files/{tableId}/{recordId}/{fieldId}/{versionNumber}.
+// See https://developer.quickbase.com/operation/downloadFile
+type DownloadFileOutput struct {
+	ErrorProperties
+
+	// Size is the number of bytes of the decoded file written to w.
+	Size int64 `json:"size"`
+
+	w io.Writer
+}
+
+func (o *DownloadFileOutput) decode(body io.ReadCloser) error { return unmarshalJSON(body, &o) }
+
+// decodeResponse implements responseDecoder.decodeResponse. The API returns the
+// file base64-encoded, so a successful response is decoded as it is streamed
+// to w, and error responses are parsed as JSON.
+func (o *DownloadFileOutput) decodeResponse(resp *http.Response) (err error) {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return o.decode(resp.Body)
+	}
+
+	defer resp.Body.Close()
+	o.Size, err = io.Copy(o.w, base64.NewDecoder(base64.StdEncoding, resp.Body))
+	return
+}
+
 // DeleteFileInput models the input sent to DELETE /v1/files/{tableId}/{recordId}/{fieldId}/{versionNumber}.
 // See https://developer.quickbase.com/operation/deleteFile
 type DeleteFileInput struct {
@@ -53,3 +97,14 @@ func (c *Client) DeleteFile(input *DeleteFileInput) (output *DeleteFileOutput, e
 	err = c.Do(input, output)
 	return
 }
+
+// DownloadFile sends a request to GET /v1/files/{tableId}/{recordId}/{fieldId}/{versionNumber}
+// and writes the decoded file to w without holding it in memory.
+// See https://developer.quickbase.com/operation/downloadFile
+func (c *Client) DownloadFile(input *DownloadFileInput, w io.Writer) (output *DownloadFileOutput, err error) {
+	input.c = c
+	input.u = fmt.Sprintf("%s/files/%s/%v/%v/%v", c.URL, url.PathEscape(input.TableID), input.RecordID, input.FieldID, input.Version)
+	output = &DownloadFileOutput{w: w}
+	err = c.Do(input, output)
+	return
+}
@@ -20,6 +20,8 @@ func (i *RunFormulaInput) url() string                  { return i.u }
 func (i *RunFormulaInput) method() string               { return http.MethodPost }
 func (i *RunFormulaInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
 func (i *RunFormulaInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *RunFormulaInput) idempotent() bool             { return true }
+func (i *RunFormulaInput) readOnly() bool               { return true }
 
 // RunFormulaOutput models the output returned by POST /v1/formula/run.
 // See https://developer.quickbase.com/operation/runFormula
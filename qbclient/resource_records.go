@@ -21,6 +21,7 @@ func (i *InsertRecordsInput) url() string                  { return i.u }
 func (i *InsertRecordsInput) method() string               { return http.MethodPost }
 func (i *InsertRecordsInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
 func (i *InsertRecordsInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *InsertRecordsInput) idempotent() bool             { return i.MergeFieldID != 0 }
 
 // InsertRecordsInputData models the data property.
 type InsertRecordsInputData struct {
@@ -97,7 +98,7 @@ type QueryRecordsInput struct {
 	c *Client
 	u string
 
-	Select  []int                       `json:"select" validate:"required,min=1" cliutil:"option=select"`
+	Select  []int                       `json:"select" validate:"required,min=1" cliutil:"option=select usage='comma-separated IDs, ranges such as 10:15, or labels of the fields to return'"`
 	From    string                      `json:"from" validate:"required" cliutil:"option=from"`
 	Where   string                      `json:"where" cliutil:"option=where func=query"`
 	GroupBy []*QueryRecordsInputGroupBy `json:"groupBy,omitempty" cliutil:"option=group-by func=group"`
@@ -109,6 +110,8 @@ func (i *QueryRecordsInput) url() string                  { return i.u }
 func (i *QueryRecordsInput) method() string               { return http.MethodPost }
 func (i *QueryRecordsInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
 func (i *QueryRecordsInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *QueryRecordsInput) idempotent() bool             { return true }
+func (i *QueryRecordsInput) readOnly() bool               { return true }
 
 // QueryRecordsInputGroupBy models the groupBy objects.
 type QueryRecordsInputGroupBy struct {
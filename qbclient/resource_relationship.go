@@ -115,6 +115,7 @@ func (i *UpdateRelationshipInput) url() string                  { return i.u }
 func (i *UpdateRelationshipInput) method() string               { return http.MethodPost }
 func (i *UpdateRelationshipInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
 func (i *UpdateRelationshipInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *UpdateRelationshipInput) idempotent() bool             { return true }
 
 // UpdateRelationshipOutput models the output returned by POST /v1/tables/{tableId}/relationship/{relationshipId}.
 // See https://developer.quickbase.com/operation/updateRelationship
@@ -109,6 +109,8 @@ func (i *RunReportInput) url() string                  { return i.u }
 func (i *RunReportInput) method() string               { return http.MethodPost }
 func (i *RunReportInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
 func (i *RunReportInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *RunReportInput) idempotent() bool             { return true }
+func (i *RunReportInput) readOnly() bool               { return true }
 
 // RunReportOutput models the output returned by POST /v1/reports/{reportId}/run?tableId={tableId}.
 // See https://developer.quickbase.com/operation/runReport
@@ -201,6 +201,7 @@ func (i *UpdateTableInput) url() string                  { return i.u }
 func (i *UpdateTableInput) method() string               { return http.MethodPost }
 func (i *UpdateTableInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
 func (i *UpdateTableInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *UpdateTableInput) idempotent() bool             { return true }
 
 // UpdateTableOutput models the output returned by POST /v1/tables/{tableId}?appId={appId}.
 // See https://developer.quickbase.com/operation/updateTable
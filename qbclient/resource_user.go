@@ -0,0 +1,64 @@
+package qbclient
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// GetUsersInput models the input sent to POST /v1/users.
+// See https://developer.quickbase.com/operation/getUsers
+type GetUsersInput struct {
+	c *Client
+	u string
+
+	AccountID     string   `json:"-" cliutil:"option=account-id"`
+	Emails        []string `json:"emails,omitempty" cliutil:"option=emails"`
+	AppIDs        []string `json:"appIds,omitempty" cliutil:"option=app-ids"`
+	NextPageToken string   `json:"nextPageToken,omitempty" cliutil:"option=next-page-token"`
+}
+
+func (i *GetUsersInput) url() string                  { return i.u }
+func (i *GetUsersInput) method() string               { return http.MethodPost }
+func (i *GetUsersInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
+func (i *GetUsersInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *GetUsersInput) idempotent() bool             { return true }
+func (i *GetUsersInput) readOnly() bool               { return true }
+
+// GetUsersOutput models the output returned by POST /v1/users.
+// See https://developer.quickbase.com/operation/getUsers
+type GetUsersOutput struct {
+	ErrorProperties
+
+	Users    []*GetUsersOutputUser   `json:"users"`
+	Metadata *GetUsersOutputMetadata `json:"metadata,omitempty"`
+}
+
+func (o *GetUsersOutput) decode(body io.ReadCloser) error { return unmarshalJSON(body, &o) }
+
+// GetUsersOutputUser models the objects in the users property.
+type GetUsersOutputUser struct {
+	EmailAddress string `json:"emailAddress"`
+	FirstName    string `json:"firstName"`
+	LastName     string `json:"lastName"`
+	HashID       string `json:"hashId"`
+	UserName     string `json:"userName"`
+}
+
+// GetUsersOutputMetadata models the metadata property.
+type GetUsersOutputMetadata struct {
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// GetUsers sends a request to POST /v1/users.
+// See https://developer.quickbase.com/operation/getUsers
+func (c *Client) GetUsers(input *GetUsersInput) (output *GetUsersOutput, err error) {
+	input.c = c
+	input.u = c.URL + "/users"
+	if input.AccountID != "" {
+		input.u += "?accountId=" + url.QueryEscape(input.AccountID)
+	}
+	output = &GetUsersOutput{}
+	err = c.Do(input, output)
+	return
+}
@@ -65,6 +65,7 @@ func (i *DeactivateUserTokenInput) url() string                  { return i.u }
 func (i *DeactivateUserTokenInput) method() string               { return http.MethodPost }
 func (i *DeactivateUserTokenInput) addHeaders(req *http.Request) { addHeadersJSON(req, i.c) }
 func (i *DeactivateUserTokenInput) encode() ([]byte, error)      { return marshalJSON(i) }
+func (i *DeactivateUserTokenInput) idempotent() bool             { return true }
 
 // DeactivateUserTokenOutput models the output returned by POST /v1/usertoken/deactivate.
 // See https://developer.quickbase.com/operation/deactivateUserToken
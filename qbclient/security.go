@@ -2,13 +2,33 @@ package qbclient
 
 import "regexp"
 
-var reUserTokenMask *regexp.Regexp
+// RedactionMarker replaces the credentials removed by RedactCredentials.
+const RedactionMarker = "[redacted]"
 
-// MaskUserToken masks user tokens in a byte slice.
+var (
+	reUserTokenMask       *regexp.Regexp
+	reTempTokenMask       *regexp.Regexp
+	reAuthHeaderRedact    *regexp.Regexp
+	reXMLCredentialRedact *regexp.Regexp
+)
+
+// MaskUserToken masks user tokens, and temporary tokens in Authorization
+// headers, in a byte slice.
 func MaskUserToken(b []byte) []byte {
+	b = reTempTokenMask.ReplaceAll(b, []byte(`${1}********************`))
 	return reUserTokenMask.ReplaceAll(b, []byte(`${1}_${2}********************${3}`))
 }
 
+// RedactCredentials replaces the credentials in Authorization headers, and the
+// user tokens and tickets in XML API requests, with RedactionMarker in a dump
+// of a request or response. Other user tokens, e.g., in the body of a cloned
+// token's response, are masked as by MaskUserToken.
+func RedactCredentials(b []byte) []byte {
+	b = MaskUserToken(b)
+	b = reAuthHeaderRedact.ReplaceAll(b, []byte("${1}"+RedactionMarker))
+	return reXMLCredentialRedact.ReplaceAll(b, []byte("${1}"+RedactionMarker+"${2}"))
+}
+
 // MaskUserTokenString masks user tokens in a string.
 func MaskUserTokenString(s string) string {
 	return reUserTokenMask.ReplaceAllString(s, "${1}_${2}********************${3}")
@@ -16,4 +36,16 @@ func MaskUserTokenString(s string) string {
 
 func init() {
 	reUserTokenMask = regexp.MustCompile(`([0-9a-z]+_[0-9a-z]+)_([0-9a-z]{4})[0-9a-z]+([0-9a-z]{4})`)
+	reTempTokenMask = regexp.MustCompile(`(QB-TEMP-TOKEN )\S+`)
+	reAuthHeaderRedact = regexp.MustCompile(`(?mi)^(Authorization:[ \t]*(?:QB-USER-TOKEN[ \t]+|QB-TEMP-TOKEN[ \t]+)?)[^\r\n]+`)
+	reXMLCredentialRedact = regexp.MustCompile(`(<(?:usertoken|ticket)>)[^<]*(</(?:usertoken|ticket)>)`)
+}
+
+// MaskToken masks a token of any kind, keeping only the first four characters
+// so users can tell which credential is in use.
+func MaskToken(s string) string {
+	if len(s) <= 8 {
+		return "***"
+	}
+	return s[:4] + "***"
 }
@@ -0,0 +1,27 @@
+package qbclient_test
+
+import (
+	"testing"
+
+	"github.com/QuickBase/quickbase-cli/qbclient"
+)
+
+func TestRedactCredentials(t *testing.T) {
+	tests := []struct {
+		dump string
+		want string
+	}{
+		{"Authorization: QB-USER-TOKEN b5ab3c_abcd_0_efghijklmnopqrstuvwxyz12345\r\n", "Authorization: QB-USER-TOKEN [redacted]\r\n"},
+		{"Authorization: QB-TEMP-TOKEN b7dmtq4b_abcd_efgh\r\nContent-Type: application/json\r\n", "Authorization: QB-TEMP-TOKEN [redacted]\r\nContent-Type: application/json\r\n"},
+		{"authorization: Bearer abc\n", "authorization: [redacted]\n"},
+		{"<qdbapi><usertoken>abc</usertoken><ticket>def</ticket></qdbapi>", "<qdbapi><usertoken>[redacted]</usertoken><ticket>[redacted]</ticket></qdbapi>"},
+		{`{"token":"b5ab3c_abcd_0_efghijklmnopqrstuvwxyz12345"}`, `{"token":"b5ab3c_abcd_0_efgh********************2345"}`},
+	}
+
+	for _, tt := range tests {
+		have := string(qbclient.RedactCredentials([]byte(tt.dump)))
+		if have != tt.want {
+			t.Errorf("have %q, want %q", have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package qbclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/QuickBase/quickbase-cli/qberrors"
+)
+
+// DefaultTimeout is the timeout of each attempt of a request made by clients
+// returned by New.
+const DefaultTimeout = 60 * time.Second
+
+// ErrTimeout is the error returned when no attempt of a request completed
+// within the client's Timeout.
+var ErrTimeout = qberrors.ErrSafe{Message: "request timed out", StatusCode: http.StatusGatewayTimeout}
+
+// timeoutTransport implements http.RoundTripper by cancelling each attempt of
+// a request that doesn't complete within the client's Timeout, including the
+// time spent reading the response body. It wraps the transport used by the
+// retry handler, so an attempt that times out is retried like a connection
+// error, and waits for the rate limiter don't count against the timeout.
+type timeoutTransport struct {
+	c    *Client
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.RoundTrip.
+func (t *timeoutTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.c.Timeout <= 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.c.Timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the context of an attempt when its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// timeoutError returns an ErrTimeout if err was caused by the client's
+// Timeout, or nil otherwise.
+func (c *Client) timeoutError(err error, numTries int) error {
+	if c.Timeout <= 0 || !errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+
+	s := fmt.Sprintf("no response within %s", c.Timeout)
+	if numTries > 1 {
+		s += fmt.Sprintf(" in %d attempts", numTries)
+	}
+	return qberrors.Service(err).Safef(ErrTimeout, "%s", s)
+}